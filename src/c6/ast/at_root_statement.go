@@ -0,0 +1,76 @@
+package ast
+
+import "strings"
+
+/*
+AtRootStatement presents the @at-root statement, the statements inside the
+block are moved out of the enclosing rulesets, or out of the at-rules
+excluded by the query:
+
+	.a { @at-root .b { ... } }
+	.a { @media print { @at-root (without: media) { ... } } }
+
+"@at-root .b { ... }" is parsed as "@at-root { .b { ... } }".
+*/
+type AtRootStatement struct {
+	// nil means "(without: rule)"
+	Query *AtRootQuery
+
+	Block *Block
+	Token *Token
+}
+
+func (stm AtRootStatement) CanBeStatement() {}
+
+func (stm AtRootStatement) String() string {
+	if stm.Query != nil {
+		return "@at-root " + stm.Query.String()
+	}
+	return "@at-root"
+}
+
+func NewAtRootStatementWithToken(token *Token) *AtRootStatement {
+	return &AtRootStatement{Token: token}
+}
+
+/*
+AtRootQuery is the query of @at-root, the names are "rule" for the rulesets,
+"all" for everything, or the names of the at-rules without "@", e.g. "media"
+and "supports".
+*/
+type AtRootQuery struct {
+	// true for "(with: ...)", false for "(without: ...)"
+	With bool
+
+	Names []string
+}
+
+func NewAtRootQuery(with bool) *AtRootQuery {
+	return &AtRootQuery{With: with, Names: []string{}}
+}
+
+/*
+Excludes returns true if the ruleset ("rule") or the at-rule of the name is
+excluded by the query. The nil query excludes only the rulesets.
+*/
+func (query *AtRootQuery) Excludes(name string) bool {
+	if query == nil {
+		return name == "rule"
+	}
+	var listed = false
+	for _, queryName := range query.Names {
+		if queryName == "all" || queryName == strings.ToLower(name) {
+			listed = true
+			break
+		}
+	}
+	return listed != query.With
+}
+
+func (query AtRootQuery) String() string {
+	var keyword = "without"
+	if query.With {
+		keyword = "with"
+	}
+	return "(" + keyword + ": " + strings.Join(query.Names, " ") + ")"
+}
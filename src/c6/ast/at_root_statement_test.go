@@ -0,0 +1,26 @@
+package ast
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestAtRootQueryExcludes(t *testing.T) {
+	var query *AtRootQuery
+	assert.True(t, query.Excludes("rule"))
+	assert.False(t, query.Excludes("media"))
+
+	query = &AtRootQuery{With: false, Names: []string{"media"}}
+	assert.True(t, query.Excludes("media"))
+	assert.False(t, query.Excludes("rule"))
+
+	query = &AtRootQuery{With: true, Names: []string{"supports"}}
+	assert.True(t, query.Excludes("rule"))
+	assert.True(t, query.Excludes("media"))
+	assert.False(t, query.Excludes("supports"))
+
+	query = &AtRootQuery{With: false, Names: []string{"all"}}
+	assert.True(t, query.Excludes("rule"))
+	assert.True(t, query.Excludes("supports"))
+
+	query = &AtRootQuery{With: true, Names: []string{"all"}}
+	assert.False(t, query.Excludes("rule"))
+}
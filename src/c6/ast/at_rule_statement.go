@@ -0,0 +1,35 @@
+package ast
+
+/*
+AtRuleStatement presents the at-rules unknown to the compiler, they're passed
+through with the prelude and the block:
+
+	@viewport { width: device-width; }
+	@-moz-document url-prefix() { .foo { color: red; } }
+*/
+type AtRuleStatement struct {
+	// the at-rule name, e.g. "@viewport"
+	Name string
+
+	// the text between the name and the block, nil if it's empty.
+	Prelude Expression
+
+	// nil for the at-rules ending with a semicolon.
+	Block *Block
+
+	Token *Token
+}
+
+func (stm AtRuleStatement) CanBeStatement() {}
+
+func (stm AtRuleStatement) String() string {
+	var out = stm.Name
+	if stm.Prelude != nil {
+		out += " " + stm.Prelude.String()
+	}
+	return out
+}
+
+func NewAtRuleStatementWithToken(token *Token) *AtRuleStatement {
+	return &AtRuleStatement{Name: token.Str, Token: token}
+}
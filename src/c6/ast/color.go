@@ -28,7 +28,11 @@ func (self HexColor) Boolean() bool {
 	return true
 }
 
+// The hex code keeps the leading '#' from the token.
 func (self HexColor) String() string {
+	if len(self.Hex) > 0 && self.Hex[0] == '#' {
+		return string(self.Hex)
+	}
 	return "#" + string(self.Hex)
 }
 
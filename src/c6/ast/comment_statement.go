@@ -0,0 +1,19 @@
+package ast
+
+// CommentStatement presents a block comment: /* ... */
+//
+// Line comments are dropped by the parser since they never go to the output.
+type CommentStatement struct {
+	Text  string
+	Token *Token
+}
+
+func (self CommentStatement) CanBeStatement() {}
+
+func (self CommentStatement) String() string {
+	return "/*" + self.Text + "*/"
+}
+
+func NewCommentStatementWithToken(token *Token) *CommentStatement {
+	return &CommentStatement{token.Str, token}
+}
@@ -0,0 +1,19 @@
+package ast
+
+/*
+ContentStatement presents the @content statement inside a mixin, it's
+replaced by the content block of the @include statement.
+*/
+type ContentStatement struct {
+	Token *Token
+}
+
+func (stm ContentStatement) CanBeStatement() {}
+
+func (stm ContentStatement) String() string {
+	return "@content"
+}
+
+func NewContentStatementWithToken(token *Token) *ContentStatement {
+	return &ContentStatement{token}
+}
@@ -0,0 +1,20 @@
+package ast
+
+/*
+DebugStatement presents the @debug statement, the value is reported to the
+diagnostics sink with the position of the statement.
+*/
+type DebugStatement struct {
+	Value Expression
+	Token *Token
+}
+
+func (stm DebugStatement) CanBeStatement() {}
+
+func (stm DebugStatement) String() string {
+	return "@debug " + stm.Value.String()
+}
+
+func NewDebugStatement(value Expression, token *Token) *DebugStatement {
+	return &DebugStatement{value, token}
+}
@@ -16,6 +16,14 @@ type DeclarationBlock struct {
 
 	// Nested rulesets
 	SubRuleSets []*RuleSet
+
+	// the number of the statements before each nested ruleset, it keeps the
+	// source order of the nested rulesets among the statements
+	SubRuleSetOffsets []int
+}
+
+func NewDeclarationBlock() *DeclarationBlock {
+	return &DeclarationBlock{SymTable: symtable.NewSymTable()}
 }
 
 /**
@@ -28,6 +36,28 @@ func (self *DeclarationBlock) Append(decl Statement) {
 func (self *DeclarationBlock) AppendSubRuleSet(ruleset *RuleSet) {
 	newRuleSets := append(self.SubRuleSets, ruleset)
 	self.SubRuleSets = newRuleSets
+	self.SubRuleSetOffsets = append(self.SubRuleSetOffsets, len(self.Statements))
+}
+
+/*
+OrderedStatements returns the statements and the nested rulesets in the
+source order, the nested rulesets without the offset are at the end.
+*/
+func (self *DeclarationBlock) OrderedStatements() []Statement {
+	var stmts = []Statement{}
+	var next = 0
+	for idx, ruleset := range self.SubRuleSets {
+		var offset = len(self.Statements)
+		if idx < len(self.SubRuleSetOffsets) && self.SubRuleSetOffsets[idx] < offset {
+			offset = self.SubRuleSetOffsets[idx]
+		}
+		if offset > next {
+			stmts = append(stmts, self.Statements[next:offset]...)
+			next = offset
+		}
+		stmts = append(stmts, ruleset)
+	}
+	return append(stmts, self.Statements[next:]...)
 }
 
 func (self DeclarationBlock) String() (out string) {
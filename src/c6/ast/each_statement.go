@@ -0,0 +1,33 @@
+package ast
+
+/*
+EachStatement presents the @each statement, the items of the list or the
+pairs of the map are assigned to the variables. The nested lists are
+destructured when there are more than one variable:
+
+	@each $name, $glyph in (home: "\f101", search: "\f102") { }
+	@each $name, $glyph, $size in home "\f101" 12px, search "\f102" 14px { }
+*/
+type EachStatement struct {
+	Variables []*Variable
+	List      Expression
+	Block     *Block
+	Token     *Token
+}
+
+func (stm EachStatement) CanBeStatement() {}
+
+func (stm EachStatement) String() string {
+	var out = "@each "
+	for i, variable := range stm.Variables {
+		if i > 0 {
+			out += ", "
+		}
+		out += variable.String()
+	}
+	return out + " in " + stm.List.String() + " {  }\n"
+}
+
+func NewEachStatement(token *Token) *EachStatement {
+	return &EachStatement{Variables: []*Variable{}, Token: token}
+}
@@ -0,0 +1,20 @@
+package ast
+
+/*
+ErrorStatement presents the @error statement, the compilation is aborted with
+the value as the error message.
+*/
+type ErrorStatement struct {
+	Value Expression
+	Token *Token
+}
+
+func (stm ErrorStatement) CanBeStatement() {}
+
+func (stm ErrorStatement) String() string {
+	return "@error " + stm.Value.String()
+}
+
+func NewErrorStatement(value Expression, token *Token) *ErrorStatement {
+	return &ErrorStatement{value, token}
+}
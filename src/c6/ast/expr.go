@@ -66,6 +66,11 @@ func (self *BinaryExpression) IsCssSlash() bool {
 		_, aok := self.Left.(*Number)
 		_, bok := self.Right.(*Number)
 
+		// "100/10/2" is the slash-separated numbers too
+		if left, ok := self.Left.(*BinaryExpression); ok {
+			aok = left.IsCssSlash()
+		}
+
 		// it's not grouped, we should inflate it as string
 		if aok && bok && self.Grouped == false {
 			return true
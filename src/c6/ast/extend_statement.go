@@ -0,0 +1,33 @@
+package ast
+
+/*
+ExtendStatement presents the @extend statement inside a ruleset, the
+selectors of the ruleset are added to the rulesets matching the target
+selector:
+
+	.error { @extend .message; }
+	.hidden { @extend %visually-hidden !optional; }
+*/
+type ExtendStatement struct {
+	// the target compound selector
+	Selectors SelectorList
+
+	// no error is raised if the target selector is not found
+	Optional bool
+
+	Token *Token
+}
+
+func (stm ExtendStatement) CanBeStatement() {}
+
+func (stm ExtendStatement) String() string {
+	var out = "@extend " + stm.Selectors.String()
+	if stm.Optional {
+		out += " !optional"
+	}
+	return out
+}
+
+func NewExtendStatementWithToken(token *Token) *ExtendStatement {
+	return &ExtendStatement{Selectors: SelectorList{}, Token: token}
+}
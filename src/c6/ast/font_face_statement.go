@@ -0,0 +1,22 @@
+package ast
+
+/*
+FontFaceStatement presents the @font-face statement with the font
+descriptors:
+
+	@font-face { font-family: "Open Sans"; src: url(open-sans.woff); }
+*/
+type FontFaceStatement struct {
+	Block *DeclarationBlock
+	Token *Token
+}
+
+func (stm FontFaceStatement) CanBeStatement() {}
+
+func (stm FontFaceStatement) String() string {
+	return "@font-face"
+}
+
+func NewFontFaceStatementWithToken(token *Token) *FontFaceStatement {
+	return &FontFaceStatement{Token: token}
+}
@@ -6,6 +6,7 @@ type ForStatement struct {
 	Through  Expression
 	To       Expression
 	Block    *Block
+	Token    *Token
 }
 
 func (stm ForStatement) CanBeStatement() {}
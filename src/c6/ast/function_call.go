@@ -2,7 +2,7 @@ package ast
 
 type FunctionCall struct {
 	Function  string
-	Arguments []Expression
+	Arguments []*Argument
 	Token     *Token
 }
 
@@ -20,11 +20,12 @@ func (self FunctionCall) String() (out string) {
 }
 
 func NewFunctionCall(token *Token) *FunctionCall {
-	return &FunctionCall{token.Str, []Expression{}, token}
+	return &FunctionCall{token.Str, []*Argument{}, token}
 }
 
+// Append a positional argument
 func (self *FunctionCall) AppendArgument(arg Expression) {
-	var args = append(self.Arguments, arg)
+	var args = append(self.Arguments, &Argument{Value: arg})
 	self.Arguments = args
 }
 
@@ -0,0 +1,26 @@
+package ast
+
+/*
+FunctionStatement presents a function definition, the function is called by
+the expressions and returns the value of @return:
+
+	@function spacing($n, $base: 4px) {
+		@return $n * $base;
+	}
+*/
+type FunctionStatement struct {
+	Name       string
+	Parameters []*Parameter
+	Block      *Block
+	Token      *Token
+}
+
+func (stm FunctionStatement) CanBeStatement() {}
+
+func (stm FunctionStatement) String() string {
+	return "@function " + stm.Name + "(" + ParameterList(stm.Parameters).String() + ") { }"
+}
+
+func NewFunctionStatement(name string, token *Token) *FunctionStatement {
+	return &FunctionStatement{Name: name, Parameters: []*Parameter{}, Token: token}
+}
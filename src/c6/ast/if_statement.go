@@ -9,11 +9,11 @@ type IfStatement struct {
 
 func (stm IfStatement) CanBeStatement() {}
 
-func (stm IfStatement) AppendElseIf(ifStm *IfStatement) {
+func (stm *IfStatement) AppendElseIf(ifStm *IfStatement) {
 	stm.ElseIfs = append(stm.ElseIfs, ifStm)
 }
 
-func (stm IfStatement) SetElseBlock(block *Block) {
+func (stm *IfStatement) SetElseBlock(block *Block) {
 	stm.ElseBlock = block
 }
 
@@ -1,12 +1,45 @@
 package ast
 
+/*
+ImportStatement presents an @import rule. The statements of the imported file
+are parsed into Statements, it's nil for the plain CSS import. An @import rule
+with more than one url is parsed as an ImportStatement without the Url, the
+Statements are the ImportStatements of the urls.
+*/
 type ImportStatement struct {
-	Url       interface{} // if it's wrapped with url(...) or "string"
-	MediaList []string
+	Url            interface{} // if it's wrapped with url(...) or "string"
+	MediaQueryList []*MediaQuery
+	Token          *Token
+
+	// the resolved path of the imported file
+	Path       string
+	Statements []Statement
+
+	// ECSS @import-once, the file is skipped if it's already imported
+	Once bool
 }
 
 func NewImportStatement() *ImportStatement {
-	return &ImportStatement{nil, []string{}}
+	return &ImportStatement{Url: nil, MediaQueryList: []*MediaQuery{}}
+}
+
+/*
+Expand returns the statements replacing the import statement, the plain CSS
+import is kept.
+*/
+func (self *ImportStatement) Expand() []Statement {
+	if self.Statements == nil {
+		return []Statement{self}
+	}
+	var stmts = []Statement{}
+	for _, stm := range self.Statements {
+		if importStm, ok := stm.(*ImportStatement); ok {
+			stmts = append(stmts, importStm.Expand()...)
+		} else {
+			stmts = append(stmts, stm)
+		}
+	}
+	return stmts
 }
 
 func (self ImportStatement) CanBeStatement() {}
@@ -0,0 +1,63 @@
+package ast
+
+import "strings"
+
+/*
+IncludeStatement presents a mixin call, the content block is passed to the
+@content statements of the mixin:
+
+	@include button(red, $size: 14px) {
+		margin: 0;
+	}
+*/
+type IncludeStatement struct {
+	Name      string
+	Arguments []*Argument
+
+	// the block after the arguments, it's nil if there is no block
+	ContentBlock *DeclarationBlock
+	Token        *Token
+}
+
+func (stm IncludeStatement) CanBeStatement() {}
+
+func (stm IncludeStatement) String() string {
+	return "@include " + stm.Name + "(" + ArgumentList(stm.Arguments).String() + ")"
+}
+
+func NewIncludeStatement(name string, token *Token) *IncludeStatement {
+	return &IncludeStatement{Name: name, Arguments: []*Argument{}, Token: token}
+}
+
+/*
+Argument is an argument of the mixin or the function call. The name is the
+variable name of the keyword argument, e.g. "$size", it's empty for the
+positional argument. The rest argument `$list...` passes the items of the
+list as the arguments.
+*/
+type Argument struct {
+	Name  string
+	Value Expression
+	Rest  bool
+}
+
+func (self Argument) String() (out string) {
+	if self.Name != "" {
+		out = self.Name + ": "
+	}
+	out += self.Value.String()
+	if self.Rest {
+		out += "..."
+	}
+	return out
+}
+
+type ArgumentList []*Argument
+
+func (self ArgumentList) String() string {
+	var args = []string{}
+	for _, arg := range self {
+		args = append(args, arg.String())
+	}
+	return strings.Join(args, ", ")
+}
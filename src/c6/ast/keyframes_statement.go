@@ -0,0 +1,29 @@
+package ast
+
+/*
+KeyframesStatement presents the @keyframes statement and the vendor-prefixed
+forms, the keyframe blocks are the rulesets with the "from", "to" and the
+percentage selectors:
+
+	@-webkit-keyframes spin { from { ... } 50% { ... } to { ... } }
+*/
+type KeyframesStatement struct {
+	// the at-rule name, e.g. "@keyframes" or "@-webkit-keyframes"
+	Keyword string
+
+	// the name of the animation, it may contain interpolations.
+	Name Expression
+
+	Block *Block
+	Token *Token
+}
+
+func (stm KeyframesStatement) CanBeStatement() {}
+
+func (stm KeyframesStatement) String() string {
+	return stm.Keyword + " " + stm.Name.String()
+}
+
+func NewKeyframesStatementWithToken(token *Token) *KeyframesStatement {
+	return &KeyframesStatement{Keyword: token.Str, Token: token}
+}
@@ -1,19 +1,86 @@
 package ast
 
+import "strings"
+
+/*
+Map presents the SASS map, the pairs are kept in the order of the insertion.
+The keys are compared by their values, e.g. "a" and a are the same key.
+*/
 type Map struct {
+	Keys   []Expression
+	Values []Expression
+}
+
+func (self *Map) index(key Expression) int {
+	for i, k := range self.Keys {
+		if k.String() == key.String() {
+			return i
+		}
+	}
+	return -1
+}
+
+/*
+Set replaces the value of the key, or appends the pair if the key is not
+found.
+*/
+func (self *Map) Set(key Expression, value Expression) {
+	if idx := self.index(key); idx != -1 {
+		self.Values[idx] = value
+		return
+	}
+	self.Keys = append(self.Keys, key)
+	self.Values = append(self.Values, value)
 }
 
-func (self *Map) Set() {
+func (self *Map) Get(key Expression) (Expression, bool) {
+	if idx := self.index(key); idx != -1 {
+		return self.Values[idx], true
+	}
+	return nil, false
+}
+
+/*
+Remove removes the pair of the key, false is returned if the key is not
+found.
+*/
+func (self *Map) Remove(key Expression) bool {
+	var idx = self.index(key)
+	if idx == -1 {
+		return false
+	}
+	self.Keys = append(self.Keys[:idx:idx], self.Keys[idx+1:]...)
+	self.Values = append(self.Values[:idx:idx], self.Values[idx+1:]...)
+	return true
+}
 
+/*
+Copy returns a new map with the same pairs, the keys and the values are not
+copied.
+*/
+func (self *Map) Copy() *Map {
+	var out = NewMap()
+	out.Keys = append(out.Keys, self.Keys...)
+	out.Values = append(out.Values, self.Values...)
+	return out
 }
 
-func (self *Map) Get() {
+func (self *Map) Len() int {
+	return len(self.Keys)
 }
 
 func (self Map) GetValueType() ValueType {
 	return MapValue
 }
 
+func (self Map) String() string {
+	var pairs = []string{}
+	for i, key := range self.Keys {
+		pairs = append(pairs, key.String()+": "+self.Values[i].String())
+	}
+	return "(" + strings.Join(pairs, ", ") + ")"
+}
+
 func NewMap() *Map {
-	return &Map{}
+	return &Map{Keys: []Expression{}, Values: []Expression{}}
 }
@@ -0,0 +1,34 @@
+package ast
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestMapKeepsInsertionOrder(t *testing.T) {
+	var m = NewMap()
+	m.Set(NewString(0, "small", nil), NewNumber(12, NewUnit(T_UNIT_PX, nil), nil))
+	m.Set(NewString(0, "large", nil), NewNumber(16, NewUnit(T_UNIT_PX, nil), nil))
+	m.Set(NewString('"', "small", nil), NewNumber(10, NewUnit(T_UNIT_PX, nil), nil))
+
+	assert.Equal(t, 2, m.Len())
+	assert.Equal(t, "(small: 10px, large: 16px)", m.String())
+
+	value, ok := m.Get(NewString(0, "large", nil))
+	assert.True(t, ok)
+	assert.Equal(t, "16px", value.String())
+
+	_, ok = m.Get(NewString(0, "medium", nil))
+	assert.False(t, ok)
+}
+
+func TestMapRemoveAndCopy(t *testing.T) {
+	var m = NewMap()
+	m.Set(NewString(0, "a", nil), NewNumber(1, nil, nil))
+	m.Set(NewString(0, "b", nil), NewNumber(2, nil, nil))
+	m.Set(NewString(0, "c", nil), NewNumber(3, nil, nil))
+
+	var copied = m.Copy()
+	assert.True(t, m.Remove(NewString('"', "b", nil)))
+	assert.False(t, m.Remove(NewString(0, "d", nil)))
+	assert.Equal(t, "(a: 1, c: 3)", m.String())
+	assert.Equal(t, "(a: 1, b: 2, c: 3)", copied.String())
+}
@@ -2,6 +2,12 @@ package ast
 
 type MediaQueryStatement struct {
 	MediaQueryList []*MediaQuery
+	Block          *Block
+	Token          *Token
+
+	// the queries with the interpolations, they are parsed after the
+	// evaluation: @media screen and #{$query}
+	QueryTemplate Expression
 }
 
 func (stm MediaQueryStatement) CanBeStatement() {}
@@ -11,6 +17,9 @@ func NewMediaQueryStatement() *MediaQueryStatement {
 }
 
 func (stm MediaQueryStatement) String() (out string) {
+	if stm.QueryTemplate != nil && len(stm.MediaQueryList) == 0 {
+		return stm.QueryTemplate.String()
+	}
 	for _, mediaQuery := range stm.MediaQueryList {
 		out += ", " + mediaQuery.String()
 	}
@@ -0,0 +1,59 @@
+package ast
+
+import "strings"
+
+/*
+MixinStatement presents a mixin definition:
+
+	@mixin button($color, $size: 12px, $shadows...) {
+		color: $color;
+	}
+*/
+type MixinStatement struct {
+	Name       string
+	Parameters []*Parameter
+	Block      *DeclarationBlock
+	Token      *Token
+}
+
+func (stm MixinStatement) CanBeStatement() {}
+
+func (stm MixinStatement) String() string {
+	return "@mixin " + stm.Name + "(" + ParameterList(stm.Parameters).String() + ") { }"
+}
+
+func NewMixinStatement(name string, token *Token) *MixinStatement {
+	return &MixinStatement{Name: name, Parameters: []*Parameter{}, Token: token}
+}
+
+/*
+Parameter is a parameter of the mixin or the function definition, the
+default value is nil if it's required. The rest parameter `$args...` takes
+the remaining arguments.
+*/
+type Parameter struct {
+	Variable *Variable
+	Default  Expression
+	Rest     bool
+}
+
+func (self Parameter) String() (out string) {
+	out = self.Variable.String()
+	if self.Default != nil {
+		out += ": " + self.Default.String()
+	}
+	if self.Rest {
+		out += "..."
+	}
+	return out
+}
+
+type ParameterList []*Parameter
+
+func (self ParameterList) String() string {
+	var params = []string{}
+	for _, param := range self {
+		params = append(params, param.String())
+	}
+	return strings.Join(params, ", ")
+}
@@ -0,0 +1,22 @@
+package ast
+
+/*
+NamespaceStatement presents the @namespace statement, the prelude is the
+optional prefix and the namespace url:
+
+	@namespace svg url(http://www.w3.org/2000/svg);
+*/
+type NamespaceStatement struct {
+	Prelude Expression
+	Token   *Token
+}
+
+func (stm NamespaceStatement) CanBeStatement() {}
+
+func (stm NamespaceStatement) String() string {
+	return "@namespace " + stm.Prelude.String() + ";"
+}
+
+func NewNamespaceStatementWithToken(token *Token) *NamespaceStatement {
+	return &NamespaceStatement{Token: token}
+}
@@ -0,0 +1,27 @@
+package ast
+
+/*
+PageStatement presents the @page statement, the page selector is optional:
+
+	@page :first { margin: 1in; }
+*/
+type PageStatement struct {
+	// the page selector, nil if it's omitted.
+	Selector Expression
+
+	Block *DeclarationBlock
+	Token *Token
+}
+
+func (stm PageStatement) CanBeStatement() {}
+
+func (stm PageStatement) String() string {
+	if stm.Selector != nil {
+		return "@page " + stm.Selector.String()
+	}
+	return "@page"
+}
+
+func NewPageStatementWithToken(token *Token) *PageStatement {
+	return &PageStatement{Token: token}
+}
@@ -13,6 +13,13 @@ type Property struct {
 		`padding: 3px 3px;`
 	*/
 	Values []Expression
+
+	// !important flag after the property value
+	Important bool
+
+	// the nested properties, their names are prefixed by the name of this
+	// property, e.g. "font: bold { family: serif; }"
+	Properties []*Property
 }
 
 /**
@@ -21,10 +28,14 @@ Property is one of the declaration
 func (self Property) CanBeDeclaration() {}
 func (self Property) CanBeStatement()   {}
 
-func (self Property) AppendValue(value Expression) {
+func (self *Property) AppendValue(value Expression) {
 	self.Values = append(self.Values, value)
 }
 
+func (self *Property) AppendProperty(property *Property) {
+	self.Properties = append(self.Properties, property)
+}
+
 func (self Property) String() (out string) {
 	out = self.Name.String() + ":"
 
@@ -34,6 +45,16 @@ func (self Property) String() (out string) {
 		items = append(items, expr.String())
 	}
 	out += strings.Join(items, " ")
+	if self.Important {
+		out += " !important"
+	}
+	if len(self.Properties) > 0 {
+		out += " {"
+		for _, property := range self.Properties {
+			out += " " + property.String() + ";"
+		}
+		out += " }"
+	}
 	return out
 }
 
@@ -42,6 +63,10 @@ type PropertyName struct {
 	// If there is an interpolation in the property name
 	Interpolation bool
 	Token         *Token
+
+	// The parsed name, it's an Ident, an Interpolation or a LiteralConcat
+	// that joins both.
+	Expression Expression
 }
 
 func (self PropertyName) String() string {
@@ -49,9 +74,22 @@ func (self PropertyName) String() string {
 }
 
 func NewPropertyName(tok *Token) *PropertyName {
-	return &PropertyName{tok.Str, tok.ContainsInterpolation, tok}
+	return &PropertyName{tok.Str, tok.ContainsInterpolation, tok, NewIdentWithToken(tok)}
+}
+
+/*
+Create a property name from the expression returned by the parser, the token
+is the first token of the property name.
+*/
+func NewPropertyNameWithExpression(expr Expression, tok *Token) *PropertyName {
+	var _, isIdent = expr.(*Ident)
+	return &PropertyName{expr.String(), !isIdent, tok, expr}
 }
 
 func NewProperty(nameTok *Token) *Property {
-	return &Property{NewPropertyName(nameTok), []Expression{}}
+	return &Property{NewPropertyName(nameTok), []Expression{}, false, nil}
+}
+
+func NewPropertyWithName(name *PropertyName) *Property {
+	return &Property{name, []Expression{}, false, nil}
 }
@@ -0,0 +1,16 @@
+package ast
+
+type ReturnStatement struct {
+	Value Expression
+	Token *Token
+}
+
+func (stm ReturnStatement) CanBeStatement() {}
+
+func (stm ReturnStatement) String() string {
+	return "@return " + stm.Value.String()
+}
+
+func NewReturnStatement(value Expression, token *Token) *ReturnStatement {
+	return &ReturnStatement{value, token}
+}
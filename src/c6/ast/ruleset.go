@@ -7,6 +7,127 @@ func (self SelectorList) Append(sel Selector) {
 	self = newSlice
 }
 
+/*
+HasPlaceholder returns true if the selector contains a placeholder selector.
+*/
+func (self SelectorList) HasPlaceholder() bool {
+	for _, sel := range self {
+		if _, ok := sel.(*PlaceholderSelector); ok {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+HasParentSelector returns true if the selector contains the parent selector
+"&".
+*/
+func (self SelectorList) HasParentSelector() bool {
+	for _, sel := range self {
+		if _, ok := sel.(*ParentSelector); ok {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ResolveParent joins the selector of the nested ruleset with the selector of
+the parent ruleset. The parent selectors "&" are replaced by the parent,
+otherwise the selector is the descendant of the parent:
+
+	.a { &:hover {} }     // .a:hover
+	.a { .b & {} }        // .b .a
+	.a { &-title {} }     // .a-title
+	.a { > .b {} }        // .a > .b
+	.a { .b {} }          // .a .b
+*/
+func (self SelectorList) ResolveParent(parent SelectorList) SelectorList {
+	var out = SelectorList{}
+	if !self.HasParentSelector() {
+		out = append(out, parent...)
+		if len(self) > 0 && !IsCombinator(self[0]) {
+			out = append(out, NewDescendantCombinator())
+		}
+		return append(out, self...)
+	}
+
+	for _, sel := range self {
+		parentSel, ok := sel.(*ParentSelector)
+		if !ok {
+			out = append(out, sel)
+			continue
+		}
+		out = append(out, parent...)
+		if parentSel.Suffix != "" && len(out) > 0 {
+			out[len(out)-1] = appendSelectorSuffix(out[len(out)-1], parentSel.Suffix)
+		}
+	}
+	return out
+}
+
+/*
+resolveParents replaces the first "&" by the first parent, the following "&"
+are replaced by each of the parents.
+*/
+func (self SelectorList) resolveParents(first SelectorList, parents SelectorGroup) SelectorGroup {
+	if !self.HasParentSelector() {
+		return SelectorGroup{self.ResolveParent(first)}
+	}
+	var outs = SelectorGroup{SelectorList{}}
+	var candidates = SelectorGroup{first}
+	for _, sel := range self {
+		parentSel, ok := sel.(*ParentSelector)
+		if !ok {
+			for i := range outs {
+				outs[i] = append(outs[i], sel)
+			}
+			continue
+		}
+		var next = SelectorGroup{}
+		for _, out := range outs {
+			for _, parent := range candidates {
+				var list = append(append(SelectorList{}, out...), parent...)
+				if parentSel.Suffix != "" && len(list) > 0 {
+					list[len(list)-1] = appendSelectorSuffix(list[len(list)-1], parentSel.Suffix)
+				}
+				next = append(next, list)
+			}
+		}
+		outs = next
+		candidates = parents
+	}
+	return outs
+}
+
+func canHaveSuffix(sel Selector) bool {
+	switch sel.(type) {
+	case *TypeSelector, *ClassSelector, *IdSelector, *PlaceholderSelector:
+		return true
+	}
+	return false
+}
+
+/*
+appendSelectorSuffix appends the suffix of "&-suffix" to the last simple
+selector of the parent.
+*/
+func appendSelectorSuffix(sel Selector, suffix string) Selector {
+	switch t := sel.(type) {
+	case *TypeSelector:
+		return &TypeSelector{t.Type + suffix, t.Token}
+	case *ClassSelector:
+		return &ClassSelector{t.ClassName + suffix, t.Token}
+	case *IdSelector:
+		return &IdSelector{t.Id + suffix, t.Token}
+	case *PlaceholderSelector:
+		return &PlaceholderSelector{t.Name + suffix, t.Token}
+	}
+	// the suffix is appended to the text of the selector
+	return &TypeSelector{sel.String() + suffix, nil}
+}
+
 func (self SelectorList) String() (out string) {
 	for _, sel := range self {
 		out += sel.String()
@@ -15,16 +136,33 @@ func (self SelectorList) String() (out string) {
 }
 
 type RuleSet struct {
-	Selectors SelectorList
+	Selectors SelectorGroup
 	Block     *DeclarationBlock
+
+	// the selectors added by @extend, they're resolved with the parent
+	// selectors since the ancestors of the target and the extender are
+	// woven.
+	ExtendedSelectors SelectorGroup
+
+	// the selectors containing interpolations, e.g. ".icon-#{$name}", the
+	// interpreter evaluates the interpolations and parses the text into the
+	// selectors.
+	SelectorTemplate Expression
+
+	// the first token of the selectors
+	Token *Token
 }
 
 func NewRuleSet() *RuleSet {
 	return &RuleSet{}
 }
 
-func (self *RuleSet) AppendSelector(sel Selector) {
-	self.Selectors = append(self.Selectors, sel)
+/*
+AppendSelectorList appends the complex selector to the selector group of the
+ruleset.
+*/
+func (self *RuleSet) AppendSelectorList(selectors SelectorList) {
+	self.Selectors = append(self.Selectors, selectors)
 }
 
 func (self *RuleSet) AppendSubRuleSet(ruleset *RuleSet) {
@@ -41,3 +179,16 @@ func (self *RuleSet) CanBeStatement() {}
 func (self RuleSet) String() string {
 	return "String() not implemented yet."
 }
+
+/*
+ResolvedSelectors returns the selectors of the ruleset resolved with the
+parent selectors, followed by the selectors added by @extend. The parents are
+nil for the top level ruleset.
+*/
+func (self *RuleSet) ResolvedSelectors(parents SelectorGroup) SelectorGroup {
+	var selectors = append(SelectorGroup{}, self.Selectors...)
+	if parents != nil {
+		selectors = selectors.ResolveParent(parents)
+	}
+	return append(selectors, self.ExtendedSelectors...)
+}
@@ -1,5 +1,7 @@
 package ast
 
+import "strings"
+
 /**
 @see http://www.w3.org/TR/CSS21/grammar.html
 
@@ -12,7 +14,9 @@ ChildCombinator
 ClassSelector
 IdSelector
 AdjacentCombinator
+GeneralSiblingCombinator
 AttributeSelector
+ParentSelector
 
 */
 
@@ -81,10 +85,15 @@ func NewClassSelector(className string) *ClassSelector {
 	return &ClassSelector{className, nil}
 }
 
+/*
+Selectors present: E[name], E[name="value"], the quotes of the value are kept
+in the pattern.
+*/
 type AttributeSelector struct {
 	Name    string
 	Op      string
 	Pattern string
+	Token   *Token
 }
 
 func (self AttributeSelector) IsSelector() {}
@@ -95,6 +104,10 @@ func (self AttributeSelector) String() (out string) {
 	return "[" + self.Name + "]"
 }
 
+func NewAttributeSelectorWithToken(name string, op string, pattern string, token *Token) *AttributeSelector {
+	return &AttributeSelector{name, op, pattern, token}
+}
+
 type UniversalSelector struct {
 	Token *Token
 }
@@ -113,6 +126,28 @@ func NewUniversalSelector() *UniversalSelector {
 	return &UniversalSelector{}
 }
 
+/*
+PlaceholderSelector presents the SASS placeholder selector '%foo', which is
+only used by @extend.
+*/
+type PlaceholderSelector struct {
+	Name  string
+	Token *Token
+}
+
+func (self PlaceholderSelector) IsSelector() {}
+func (self PlaceholderSelector) String() string {
+	return self.Name
+}
+
+func NewPlaceholderSelectorWithToken(token *Token) *PlaceholderSelector {
+	return &PlaceholderSelector{token.Str, token}
+}
+
+func NewPlaceholderSelector(name string) *PlaceholderSelector {
+	return &PlaceholderSelector{name, nil}
+}
+
 /*
 Selectors presents: E:pseudo
 */
@@ -131,7 +166,8 @@ func (self PseudoSelector) String() (out string) {
 }
 
 func NewPseudoSelectorWithToken(token *Token) *PseudoSelector {
-	return &PseudoSelector{token.Str, "", token}
+	// the token contains the leading ':', "::before" is kept as ":before"
+	return &PseudoSelector{strings.TrimPrefix(token.Str, ":"), "", token}
 }
 
 /*
@@ -148,6 +184,20 @@ func NewAdjacentCombinatorWithToken(token *Token) *AdjacentCombinator {
 	return &AdjacentCombinator{token}
 }
 
+/*
+Selectors present: E '~' F
+*/
+type GeneralSiblingCombinator struct {
+	Token *Token
+}
+
+func (self GeneralSiblingCombinator) IsSelector()    {}
+func (self GeneralSiblingCombinator) String() string { return " ~ " }
+
+func NewGeneralSiblingCombinatorWithToken(token *Token) *GeneralSiblingCombinator {
+	return &GeneralSiblingCombinator{token}
+}
+
 type DescendantCombinator struct {
 	Token *Token
 }
@@ -179,20 +229,31 @@ func NewChildCombinator() *ChildCombinator {
 }
 
 /*
-This is a SCSS only selector
+This is a SCSS only selector, it's replaced by the selectors of the parent
+ruleset. The suffix is appended to the parent selector, e.g. "&-title".
 */
 type ParentSelector struct {
 	ParentRuleSet *RuleSet
+	Suffix        string
 	Token         *Token
 }
 
 func (self ParentSelector) IsSelector() {}
 func (self ParentSelector) String() string {
-	// TODO: get parent rule set and render the selector...
-	panic("unimplemented")
-	return ""
+	return "&" + self.Suffix
 }
 
 func NewParentSelectorWithToken(parentRuleSet *RuleSet, token *Token) *ParentSelector {
-	return &ParentSelector{parentRuleSet, token}
+	return &ParentSelector{parentRuleSet, strings.TrimPrefix(token.Str, "&"), token}
+}
+
+/*
+IsCombinator returns true if the selector joins two compound selectors.
+*/
+func IsCombinator(sel Selector) bool {
+	switch sel.(type) {
+	case *DescendantCombinator, *ChildCombinator, *AdjacentCombinator, *GeneralSiblingCombinator:
+		return true
+	}
+	return false
 }
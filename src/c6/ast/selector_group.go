@@ -1,5 +1,69 @@
 package ast
 
-type SelectorGroup struct {
-	Selectors []Selector
+import "fmt"
+import "strings"
+
+/*
+SelectorGroup is the comma-separated selectors of a ruleset. Each item is a
+complex selector, the compound selectors joined by the combinators:
+
+	h1, .nav > li.active a    // [h1] [.nav, >, li, .active, " ", a]
+*/
+type SelectorGroup []SelectorList
+
+func (self SelectorGroup) HasParentSelector() bool {
+	for _, selectors := range self {
+		if selectors.HasParentSelector() {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ResolveParent joins the selectors with the selectors of the parent ruleset,
+the result is the cross product of the parent selectors and the selectors:
+
+	.a, .b { .c, .d {} }    // .a .c, .a .d, .b .c, .b .d
+
+Each "&" of a selector is replaced by every parent selector:
+
+	.a, .b { & + & {} }    // .a + .a, .a + .b, .b + .a, .b + .b
+*/
+func (self SelectorGroup) ResolveParent(parents SelectorGroup) SelectorGroup {
+	var out = SelectorGroup{}
+	for _, parent := range parents {
+		for _, selectors := range self {
+			out = append(out, selectors.resolveParents(parent, parents)...)
+		}
+	}
+	return out
+}
+
+/*
+CheckParentSuffix returns an error if a selector like "&-suffix" appends the
+suffix to a parent selector which doesn't end with a name, e.g. ".a:hover".
+*/
+func (self SelectorGroup) CheckParentSuffix(parents SelectorGroup) error {
+	for _, selectors := range self {
+		for _, sel := range selectors {
+			if parentSel, ok := sel.(*ParentSelector); !ok || parentSel.Suffix == "" {
+				continue
+			}
+			for _, parent := range parents {
+				if len(parent) == 0 || !canHaveSuffix(parent[len(parent)-1]) {
+					return fmt.Errorf("Invalid parent selector for \"%s\": \"%s\" can't have a suffix", selectors.String(), parent.String())
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (self SelectorGroup) String() string {
+	var items = []string{}
+	for _, selectors := range self {
+		items = append(items, selectors.String())
+	}
+	return strings.Join(items, ", ")
 }
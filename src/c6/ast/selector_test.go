@@ -17,3 +17,34 @@ func TestSelectorList(t *testing.T) {
 	combined := SelectorList{e, id, cls1, cls2}
 	assert.Equal(t, "div#myId.foo.bar", combined.String())
 }
+
+func TestSelectorListResolveParent(t *testing.T) {
+	var parent = SelectorList{NewClassSelector(".a"), NewDescendantCombinator(), NewClassSelector(".b")}
+
+	var hover = SelectorList{&ParentSelector{}, &PseudoSelector{PseudoClass: "hover"}}
+	assert.Equal(t, ".a .b:hover", hover.ResolveParent(parent).String())
+
+	var ancestor = SelectorList{NewClassSelector(".c"), NewDescendantCombinator(), &ParentSelector{}}
+	assert.Equal(t, ".c .a .b", ancestor.ResolveParent(parent).String())
+
+	var suffix = SelectorList{&ParentSelector{Suffix: "-title"}}
+	assert.Equal(t, ".a .b-title", suffix.ResolveParent(parent).String())
+
+	var descendant = SelectorList{NewClassSelector(".c")}
+	assert.Equal(t, ".a .b .c", descendant.ResolveParent(parent).String())
+
+	var child = SelectorList{NewChildCombinator(), NewClassSelector(".c")}
+	assert.Equal(t, ".a .b > .c", child.ResolveParent(parent).String())
+}
+
+func TestSelectorGroupResolveRepeatedParent(t *testing.T) {
+	var parents = SelectorGroup{{NewClassSelector(".a")}, {NewClassSelector(".b")}}
+	var sibling = SelectorGroup{{&ParentSelector{}, NewAdjacentCombinatorWithToken(nil), &ParentSelector{}}}
+	assert.Equal(t, ".a + .a, .a + .b, .b + .a, .b + .b", sibling.ResolveParent(parents).String())
+}
+
+func TestSelectorGroupCheckParentSuffix(t *testing.T) {
+	var suffix = SelectorGroup{{&ParentSelector{Suffix: "-x"}}}
+	assert.Nil(t, suffix.CheckParentSuffix(SelectorGroup{{NewClassSelector(".a")}}))
+	assert.NotNil(t, suffix.CheckParentSuffix(SelectorGroup{{NewClassSelector(".a"), &PseudoSelector{PseudoClass: "hover"}}}))
+}
@@ -32,3 +32,24 @@ When string length is greater than 0, return true for boolean context
 func (str String) Boolean() bool {
 	return len(str.Value) > 0
 }
+
+/*
+StringTemplate is the quoted string with the interpolations, e.g.
+"icon-#{$name}.png". The template is the concatenation of the text and the
+interpolations, it's evaluated to a quoted string.
+*/
+type StringTemplate struct {
+	Quote    byte
+	Template Expression
+	Token    *Token
+}
+
+func (self StringTemplate) CanBeNode() {}
+
+func (self StringTemplate) String() string {
+	return string(self.Quote) + self.Template.String() + string(self.Quote)
+}
+
+func NewStringTemplate(quote byte, template Expression, token *Token) *StringTemplate {
+	return &StringTemplate{quote, template, token}
+}
@@ -0,0 +1,45 @@
+package ast
+
+/*
+SupportsStatement presents the @supports statement, the condition is a
+SupportsDeclaration or the conditions joined by "not", "and" and "or":
+
+	@supports (display: flex) and (not (display: grid)) { ... }
+*/
+type SupportsStatement struct {
+	Condition Expression
+	Block     *Block
+	Token     *Token
+}
+
+func (stm SupportsStatement) CanBeStatement() {}
+
+func (stm SupportsStatement) String() string {
+	return "@supports " + stm.Condition.String()
+}
+
+func NewSupportsStatementWithToken(token *Token) *SupportsStatement {
+	return &SupportsStatement{Token: token}
+}
+
+/*
+SupportsDeclaration is the declaration tested by @supports, e.g.
+"(display: flex)".
+*/
+type SupportsDeclaration struct {
+	Property Expression
+	Value    Expression
+	Token    *Token
+}
+
+func NewSupportsDeclaration(property, value Expression) *SupportsDeclaration {
+	return &SupportsDeclaration{property, value, nil}
+}
+
+func NewSupportsDeclarationWithToken(property, value Expression, token *Token) *SupportsDeclaration {
+	return &SupportsDeclaration{property, value, token}
+}
+
+func (self SupportsDeclaration) String() string {
+	return "(" + self.Property.String() + ": " + self.Value.String() + ")"
+}
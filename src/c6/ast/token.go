@@ -35,15 +35,31 @@ var KeywordList = []KeywordToken{
 	KeywordToken{"@else if", T_ELSE_IF},
 	KeywordToken{"@else", T_ELSE},
 	KeywordToken{"@if", T_IF},
+	KeywordToken{"@import-once", T_IMPORT_ONCE},
 	KeywordToken{"@import", T_IMPORT},
+	KeywordToken{"@charset", T_CHARSET},
 	KeywordToken{"@media", T_MEDIA},
 	KeywordToken{"@return", T_RETURN},
+	KeywordToken{"@debug", T_DEBUG},
+	KeywordToken{"@warn", T_WARN},
+	KeywordToken{"@error", T_ERROR},
 	KeywordToken{"@include", T_INCLUDE},
 	KeywordToken{"@function", T_FUNCTION},
 	KeywordToken{"@mixin", T_MIXIN},
+	KeywordToken{"@content", T_CONTENT},
+	KeywordToken{"@extend", T_EXTEND},
 	KeywordToken{"@font-face", T_FONT_FACE},
+	KeywordToken{"@at-root", T_AT_ROOT},
+	KeywordToken{"@supports", T_SUPPORTS},
+	KeywordToken{"@keyframes", T_KEYFRAMES},
+	KeywordToken{"@page", T_PAGE},
+	KeywordToken{"@namespace", T_NAMESPACE},
 	KeywordToken{"@for", T_FOR},
 	KeywordToken{"@while", T_WHILE},
+	KeywordToken{"@each", T_EACH},
+	KeywordToken{"@switch", T_SWITCH},
+	KeywordToken{"@case", T_CASE},
+	KeywordToken{"@default", T_CASE_DEFAULT},
 }
 
 var AtRuleTokenMap = KeywordTokenMap{
@@ -51,14 +67,32 @@ var AtRuleTokenMap = KeywordTokenMap{
 	"@else":      T_ELSE,
 	"@if":        T_IF,
 	"@import":    T_IMPORT,
+	"@charset":   T_CHARSET,
 	"@media":     T_MEDIA,
 	"@return":    T_RETURN,
+	"@debug":     T_DEBUG,
+	"@warn":      T_WARN,
+	"@error":     T_ERROR,
 	"@include":   T_INCLUDE,
 	"@function":  T_FUNCTION,
 	"@mixin":     T_MIXIN,
+	"@content":   T_CONTENT,
+	"@extend":    T_EXTEND,
 	"@font-face": T_FONT_FACE,
+	"@at-root":   T_AT_ROOT,
+	"@supports":  T_SUPPORTS,
+	"@keyframes": T_KEYFRAMES,
+	"@page":      T_PAGE,
+	"@namespace": T_NAMESPACE,
 	"@for":       T_FOR,
 	"@while":     T_WHILE,
+	"@each":      T_EACH,
+
+	// ECSS at-rules
+	"@import-once": T_IMPORT_ONCE,
+	"@switch":      T_SWITCH,
+	"@case":        T_CASE,
+	"@default":     T_CASE_DEFAULT,
 }
 
 var ExprTokenMap = KeywordTokenMap{
@@ -109,6 +143,12 @@ type Token struct {
 	Pos                   int
 	Line                  int
 	ContainsInterpolation bool
+
+	// the column of the token in the line, counted in characters.
+	Column int
+
+	// the source file of the token
+	File string
 }
 
 type TokenChannel chan *Token
@@ -134,7 +174,8 @@ func (tok Token) IsSelectorCombinator() bool {
 func (tok Token) IsSelector() bool {
 	switch tok.Type {
 	case T_TYPE_SELECTOR, T_UNIVERSAL_SELECTOR, T_ID_SELECTOR,
-		T_CLASS_SELECTOR, T_PARENT_SELECTOR, T_PSEUDO_SELECTOR,
+		T_CLASS_SELECTOR, T_PARENT_SELECTOR, T_PLACEHOLDER_SELECTOR, T_PSEUDO_SELECTOR,
+		T_INTERPOLATION_SELECTOR, T_BRACKET_LEFT, T_KEYFRAME_SELECTOR,
 		T_ADJACENT_SIBLING_COMBINATOR, T_GENERAL_SIBLING_COMBINATOR,
 		T_CHILD_COMBINATOR, T_DESCENDANT_COMBINATOR:
 		return true
@@ -212,9 +253,10 @@ const (
 	T_CLASS_SELECTOR
 	T_TYPE_SELECTOR
 	T_UNIVERSAL_SELECTOR
-	T_PARENT_SELECTOR   // SASS parent selector
-	T_PSEUDO_SELECTOR   // :hover, :visited , ...
-	T_FUNCTIONAL_PSEUDO // lang(...), nth(...)
+	T_PARENT_SELECTOR      // SASS parent selector
+	T_PLACEHOLDER_SELECTOR // SASS placeholder selector: '%foo'
+	T_PSEUDO_SELECTOR      // :hover, :visited , ...
+	T_FUNCTIONAL_PSEUDO    // lang(...), nth(...)
 
 	/*
 		An interpolation selector token presents one or two more selector strings,
@@ -250,15 +292,25 @@ const (
 	T_ELSE_IF
 	T_INCLUDE
 	T_MIXIN
+	T_CONTENT
+	T_EXTEND
 	T_FUNCTION
 	T_FOR
 	T_FOR_FROM
 	T_FOR_THROUGH
 	T_FOR_TO
 	T_FOR_IN
+	T_EACH
 	T_WHILE
 	T_RETURN
-	T_RANGE // for '..'
+	T_DEBUG
+	T_WARN
+	T_ERROR
+	T_SWITCH       // ECSS '@switch'
+	T_CASE         // ECSS '@case'
+	T_CASE_DEFAULT // ECSS '@default' inside '@switch'
+	T_RANGE        // for '..'
+	T_ELLIPSIS     // for '...' of the variable arguments
 
 	// Flag token types
 	T_GLOBAL
@@ -267,6 +319,13 @@ const (
 	T_OPTIONAL
 
 	T_FONT_FACE
+	T_SUPPORTS
+	T_KEYFRAMES // '@keyframes' and the vendor-prefixed forms, e.g. '@-webkit-keyframes'
+	T_PAGE
+	T_NAMESPACE
+	T_AT_ROOT
+
+	T_KEYFRAME_SELECTOR // the percentage selector inside '@keyframes', e.g. '50%'
 
 	T_LOGICAL_NOT // 'not' used in conditions
 	T_LOGICAL_OR  // 'or' used in conditions query
@@ -303,6 +362,7 @@ const (
 	T_VARIABLE
 
 	T_IMPORT
+	T_IMPORT_ONCE // ECSS '@import-once'
 	T_AT_RULE
 
 	T_CHARSET
@@ -19,6 +19,9 @@ func (unit Unit) String() string {
 	if unit.Token != nil {
 		return unit.Token.Str
 	}
+	if unit.Type == T_UNIT_PERCENT {
+		return "%"
+	}
 	var name = string(unit.Type.String())
 	return strings.ToLower(strings.TrimPrefix(name, "T_UNIT_"))
 }
@@ -0,0 +1,20 @@
+package ast
+
+/*
+WarnStatement presents the @warn statement, the value is reported to the
+diagnostics sink as a warning.
+*/
+type WarnStatement struct {
+	Value Expression
+	Token *Token
+}
+
+func (stm WarnStatement) CanBeStatement() {}
+
+func (stm WarnStatement) String() string {
+	return "@warn " + stm.Value.String()
+}
+
+func NewWarnStatement(value Expression, token *Token) *WarnStatement {
+	return &WarnStatement{value, token}
+}
@@ -8,7 +8,7 @@ type WhileStatement struct {
 
 func (stm WhileStatement) CanBeStatement() {}
 
-func (stm WhileStatement) SetElseBlock(block *Block) {
+func (stm *WhileStatement) SetElseBlock(block *Block) {
 	stm.ElseBlock = block
 }
 
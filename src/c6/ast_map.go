@@ -1,6 +1,160 @@
 package c6
 
-/**
-We will cache the compiled ast.Block in the map,
+import "c6/ast"
+import "os"
+import "path/filepath"
+import "sync"
+import "time"
+
+/*
+FileAst is the parsed statements of a file, the files imported by the file
+are recorded for tracking the dependencies.
+*/
+type FileAst struct {
+	Path       string
+	ModTime    time.Time
+	Size       int64
+	Statements []ast.Statement
+
+	// the resolved paths of the files imported directly or indirectly, the
+	// statements of the imported files are merged into the statements.
+	Imports []string
+
+	// the modification time of the imported files when the file is parsed
+	ImportModTimes map[string]time.Time
+
+	// the statements depend on the files imported before the file, it's
+	// not cached.
+	HasImportOnce bool
+}
+
+/*
+IsFresh returns true if the file and the imported files are not modified since
+the file is parsed.
+*/
+func (self *FileAst) IsFresh(info os.FileInfo) bool {
+	if !self.ModTime.Equal(info.ModTime()) || self.Size != info.Size() {
+		return false
+	}
+	for path, modTime := range self.ImportModTimes {
+		importInfo, err := os.Stat(path)
+		if err != nil || !modTime.Equal(importInfo.ModTime()) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+FileAstMap caches the parsed statements by the file path, the cached
+statements are reused until the file is modified or invalidated.
+*/
+type FileAstMap struct {
+	files map[string]*FileAst
+	mutex sync.Mutex
+}
+
+func NewFileAstMap() *FileAstMap {
+	return &FileAstMap{files: map[string]*FileAst{}}
+}
+
+func (self *FileAstMap) Get(path string) (*FileAst, bool) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	fileAst, ok := self.files[filepath.Clean(path)]
+	return fileAst, ok
+}
+
+func (self *FileAstMap) Set(fileAst *FileAst) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.files[filepath.Clean(fileAst.Path)] = fileAst
+}
+
+/*
+Invalidate drops the cached statements of the file.
+*/
+func (self *FileAstMap) Invalidate(path string) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	delete(self.files, filepath.Clean(path))
+}
+
+/*
+InvalidateDependents drops the cached statements of the file and the files
+importing the file.
+*/
+func (self *FileAstMap) InvalidateDependents(path string) {
+	path = filepath.Clean(path)
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	delete(self.files, path)
+	for filePath, fileAst := range self.files {
+		for _, imported := range fileAst.Imports {
+			if imported == path {
+				delete(self.files, filePath)
+				break
+			}
+		}
+	}
+}
+
+/*
+ParseFile returns the cached statements of the file if the file is not
+modified since the last parsing, otherwise the file is parsed with a new
+parser of the context. The files using @import-once are not cached.
+*/
+func (self *FileAstMap) ParseFile(context *Context, path string) (*FileAst, error) {
+	path = filepath.Clean(path)
+	info, err := os.Stat(path)
+	if err != nil {
+		self.Invalidate(path)
+		return nil, err
+	}
+	if fileAst, ok := self.Get(path); ok && fileAst.IsFresh(info) {
+		return fileAst, nil
+	}
+
+	var parser = NewParser(context)
+	stmts, err := parser.ParseFile(path)
+	if err != nil {
+		self.Invalidate(path)
+		return nil, err
+	}
+	var fileAst = &FileAst{
+		Path:           path,
+		ModTime:        info.ModTime(),
+		Size:           info.Size(),
+		Statements:     stmts,
+		Imports:        parser.Imports,
+		ImportModTimes: map[string]time.Time{},
+		HasImportOnce:  parser.HasImportOnce,
+	}
+	if fileAst.Imports == nil {
+		fileAst.Imports = []string{}
+	}
+	for _, imported := range fileAst.Imports {
+		if importInfo, err := os.Stat(imported); err == nil {
+			fileAst.ImportModTimes[imported] = importInfo.ModTime()
+		}
+	}
+	if !fileAst.HasImportOnce {
+		self.Set(fileAst)
+	}
+	return fileAst, nil
+}
+
+/*
+ImportGraph returns the files imported by the file directly or indirectly.
 */
-var fileAstMap map[string]interface{} = map[string]interface{}{}
+func (self *FileAstMap) ImportGraph(context *Context, path string) (map[string]bool, error) {
+	fileAst, err := self.ParseFile(context, path)
+	if err != nil {
+		return nil, err
+	}
+	var graph = map[string]bool{}
+	for _, imported := range fileAst.Imports {
+		graph[imported] = true
+	}
+	return graph, nil
+}
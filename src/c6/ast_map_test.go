@@ -0,0 +1,78 @@
+package c6
+
+import "io/ioutil"
+import "os"
+import "path/filepath"
+import "testing"
+import "time"
+import "github.com/stretchr/testify/assert"
+
+func TestFileAstMapParseFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var path = filepath.Join(dir, "main.scss")
+	ioutil.WriteFile(path, []byte(`@import "partial"; a { color: red; }`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "_partial.scss"), []byte(`b { color: red; }`), 0644)
+
+	var fileAstMap = NewFileAstMap()
+	fileAst, err := fileAstMap.ParseFile(NewContext(), path)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(fileAst.Statements))
+	assert.Equal(t, []string{filepath.Join(dir, "_partial.scss")}, fileAst.Imports)
+
+	cached, err := fileAstMap.ParseFile(NewContext(), path)
+	assert.Nil(t, err)
+	assert.True(t, fileAst == cached)
+
+	fileAstMap.Invalidate(path)
+	parsed, err := fileAstMap.ParseFile(NewContext(), path)
+	assert.Nil(t, err)
+	assert.False(t, fileAst == parsed)
+}
+
+func TestFileAstMapImportGraph(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ioutil.WriteFile(filepath.Join(dir, "main.scss"), []byte(`@import "a"; @media print { @import "b"; }`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "_a.scss"), []byte(`@import "c";`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "_b.scss"), []byte(``), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "_c.scss"), []byte(`@import "foo.css";`), 0644)
+
+	var fileAstMap = NewFileAstMap()
+	graph, err := fileAstMap.ImportGraph(NewContext(), filepath.Join(dir, "main.scss"))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]bool{
+		filepath.Join(dir, "_a.scss"): true,
+		filepath.Join(dir, "_b.scss"): true,
+		filepath.Join(dir, "_c.scss"): true,
+	}, graph)
+}
+
+func TestFileAstMapImportedFileModified(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var path = filepath.Join(dir, "main.scss")
+	var partial = filepath.Join(dir, "_partial.scss")
+	ioutil.WriteFile(path, []byte(`@import "partial";`), 0644)
+	ioutil.WriteFile(partial, []byte(`b { color: red; }`), 0644)
+
+	var fileAstMap = NewFileAstMap()
+	fileAst, err := fileAstMap.ParseFile(NewContext(), path)
+	assert.Nil(t, err)
+
+	var modTime = time.Now().Add(time.Hour)
+	os.Chtimes(partial, modTime, modTime)
+	parsed, err := fileAstMap.ParseFile(NewContext(), path)
+	assert.Nil(t, err)
+	assert.False(t, fileAst == parsed)
+
+	fileAstMap.InvalidateDependents(partial)
+	_, ok := fileAstMap.Get(path)
+	assert.False(t, ok)
+}
@@ -0,0 +1,94 @@
+package c6
+
+import "testing"
+import "c6/compiler"
+import "github.com/stretchr/testify/assert"
+
+func TestEvaluateAtRoot(t *testing.T) {
+	var cases = map[string]string{
+		`.a { color: red; @at-root .b { color: blue; } }`:                  ".a { color: red; }\n\n.b { color: blue; }\n",
+		`.a { @at-root .b & { color: blue; } }`:                            ".b .a { color: blue; }\n",
+		`.a { .b { @at-root { .c { color: blue; } } } }`:                   ".c { color: blue; }\n",
+		`.block { &__elem { @at-root .block--mod & { x: 1; } } }`:          ".block--mod .block__elem { x: 1; }\n",
+		`@at-root .a { color: red; }`:                                      ".a { color: red; }\n",
+		`.a { @at-root (without: media) { .b { color: red; } } }`:          ".a .b { color: red; }\n",
+		`@media print { .a { @at-root .b { color: red; } } }`:              "@media print { .b { color: red; } }\n",
+		`@media print { .a { @at-root (without: all) { .b { x: 1; } } } }`: ".b { x: 1; }\n",
+	}
+	for code, expected := range cases {
+		css, err := evaluateScss(code)
+		assert.Nil(t, err, code)
+		assert.Equal(t, expected, css, code)
+	}
+}
+
+func TestEvaluateAtRootWithoutMedia(t *testing.T) {
+	css, err := evaluateScss(`.a { @media print { .b { @at-root (without: media) { color: red; } } } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a .b { color: red; }\n", css)
+}
+
+func TestEvaluateAtRootWithoutMediaInAllStyles(t *testing.T) {
+	var code = `
+@media print {
+  .a { @at-root (without: media) { color: red; } }
+}
+@supports (display: grid) {
+  .b { @at-root (without: supports) { .c { x: 1; } } }
+}`
+	var expected = map[compiler.OutputStyle]string{
+		compiler.NestedStyle:     ".a {\n  color: red; }\n\n.b .c {\n  x: 1; }\n",
+		compiler.ExpandedStyle:   ".a {\n  color: red;\n}\n\n.b .c {\n  x: 1;\n}\n",
+		compiler.CompactStyle:    ".a { color: red; }\n\n.b .c { x: 1; }\n",
+		compiler.CompressedStyle: ".a{color:red}.b .c{x:1}\n",
+	}
+	for style, css := range expected {
+		out, err := evaluateScssWithStyle(code, style)
+		assert.Nil(t, err, style.String())
+		assert.Equal(t, css, out, style.String())
+	}
+}
+
+func TestEvaluateAtRootWithSupports(t *testing.T) {
+	css, err := evaluateScss(`
+@supports (display: grid) {
+  .a {
+    @media screen {
+      @at-root (with: supports) { .b { x: 1; } }
+      @at-root (with: rule supports) { x: 2; }
+    }
+  }
+}`)
+	assert.Nil(t, err)
+	assert.Equal(t, "@supports (display: grid) { .a { x: 2; } .b { x: 1; } }\n", css)
+}
+
+func TestEvaluateAtRootInMixin(t *testing.T) {
+	css, err := evaluateScss(`
+@mixin fade-in($name) {
+  animation: $name 1s;
+  @at-root { @keyframes #{$name} { from { opacity: 0; } to { opacity: 1; } } }
+}
+.a { .b { @include fade-in(fade); } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a .b { animation: fade 1s; }\n\n@keyframes fade { from { opacity: 0; } to { opacity: 1; } }\n", css)
+}
+
+func TestEvaluateAtRootWithExtend(t *testing.T) {
+	css, err := evaluateScss(`.x { color: red; } .a { @at-root .b { @extend .x; } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".x, .b { color: red; }\n", css)
+}
+
+func TestEvaluateAtRootErrors(t *testing.T) {
+	var cases = map[string]string{
+		`.a { @at-root { color: red; } }`: "Properties are only allowed within rulesets",
+		`@at-root & { color: red; }`:      "Top-level selectors may not contain the parent selector",
+	}
+	for code, message := range cases {
+		_, err := evaluateScss(code)
+		if assert.NotNil(t, err, code) {
+			assert.Contains(t, err.Error(), message)
+		}
+	}
+}
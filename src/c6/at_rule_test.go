@@ -0,0 +1,13 @@
+package c6
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestEvaluateAtRules(t *testing.T) {
+	out, err := evaluateScss(`$prop: display; $name: spin;
+@supports (#{$prop}: flex) { .a { x: y; } }
+@keyframes #{$name}-x { to { opacity: 0.5 * 2; } }
+@-moz-document url-prefix() { .b { color: $name; } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, "@supports (display: flex) { .a { x: y; } }\n\n@keyframes spin-x { to { opacity: 1; } }\n\n@-moz-document url-prefix() { .b { color: spin; } }\n", out)
+}
@@ -1,5 +1,389 @@
+/*
+c6c compiles SCSS files to CSS.
+
+	c6c [options] input.scss [output.css]
+	c6c [options] < input.scss > output.css
+	c6c [options] src/ dist/
+	c6c [options] --watch src:dist
+
+When the input is a directory, every entry file in the directory is compiled
+to the output directory, the partial files (the files start with "_") are
+skipped.
+*/
 package main
 
-func main() {
+import "bytes"
+import "c6"
+import "c6/compile"
+import "c6/compiler"
+import "c6/sourcemap"
+import "encoding/json"
+import "flag"
+import "fmt"
+import "io"
+import "io/ioutil"
+import "os"
+import "path/filepath"
+import "strings"
+
+const (
+	ExitSuccess = iota
+	ExitCompileError
+	ExitUsageError
+)
+
+/*
+LoadPathList collects the repeated -I/--load-path options.
+*/
+type LoadPathList []string
+
+func (self *LoadPathList) String() string {
+	return strings.Join(*self, string(os.PathListSeparator))
+}
+
+func (self *LoadPathList) Set(path string) error {
+	*self = append(*self, path)
+	return nil
+}
+
+type Options struct {
+	Style     compiler.OutputStyle
+	LoadPaths []string
+
+	// write the source map to "<output>.map"
+	SourceMap bool
+
+	// embed the source map in the CSS as a data URL
+	SourceMapInline bool
+
+	// include the contents of the sources in the source map
+	SourceMapContents bool
+
+	// the standard input is in the indented syntax
+	Indented bool
+}
+
+func usage(flags *flag.FlagSet, stderr io.Writer) {
+	fmt.Fprintln(stderr, "Usage: c6c [options] [input.scss|input-dir|-] [output.css|output-dir]")
+	fmt.Fprintln(stderr, "")
+	fmt.Fprintln(stderr, "Options:")
+	flags.SetOutput(stderr)
+	flags.PrintDefaults()
+}
+
+/*
+parseArguments parses the options and the positional arguments, the options
+are allowed after the positional arguments, e.g. "c6c in.scss --style compact".
+*/
+func parseArguments(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional = []string{}
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		var rest = flags.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	var flags = flag.NewFlagSet("c6c", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+
+	var styleName string
+	var loadPaths LoadPathList
+	var watchArg string
+	var poll bool
+	var sourceMap, sourceMapInline, sourceMapContents bool
+	var indented bool
+	flags.StringVar(&styleName, "style", "nested", "output style: nested, expanded, compact or compressed")
+	flags.StringVar(&watchArg, "watch", "", "watch the input directory and compile the changed files, e.g. --watch src:dist")
+	flags.BoolVar(&poll, "poll", false, "check the changes by polling instead of the native file system events")
+	flags.BoolVar(&sourceMap, "sourcemap", false, "write the source map to <output>.map")
+	flags.BoolVar(&sourceMapInline, "sourcemap-inline", false, "embed the source map in the CSS")
+	flags.BoolVar(&sourceMapContents, "sourcemap-contents", false, "include the sources in the source map")
+	flags.BoolVar(&indented, "indented", false, "parse the standard input as the indented syntax (.sass)")
+	flags.Var(&loadPaths, "I", "add the directory to the import load paths")
+	flags.Var(&loadPaths, "load-path", "add the directory to the import load paths")
+
+	positional, err := parseArguments(flags, args)
+	if err == flag.ErrHelp {
+		usage(flags, stdout)
+		return ExitSuccess
+	} else if err != nil {
+		fmt.Fprintf(stderr, "c6c: %s\n", err)
+		usage(flags, stderr)
+		return ExitUsageError
+	}
+	if len(positional) > 2 {
+		fmt.Fprintf(stderr, "c6c: too many arguments: %s\n", strings.Join(positional[2:], " "))
+		usage(flags, stderr)
+		return ExitUsageError
+	}
+
+	style, err := compiler.ParseOutputStyle(styleName)
+	if err != nil {
+		fmt.Fprintf(stderr, "c6c: %s\n", err)
+		return ExitUsageError
+	}
+	var options = Options{
+		Style:             style,
+		LoadPaths:         loadPaths,
+		SourceMap:         sourceMap && !sourceMapInline,
+		SourceMapInline:   sourceMapInline,
+		SourceMapContents: sourceMapContents,
+		Indented:          indented,
+	}
+
+	if watchArg != "" {
+		if len(positional) > 0 {
+			fmt.Fprintln(stderr, "c6c: --watch doesn't take the input and output arguments")
+			return ExitUsageError
+		}
+		return watch(watchArg, poll, stdout, stderr, options)
+	}
+
+	var input, output string
+	if len(positional) > 0 {
+		input = positional[0]
+	}
+	if len(positional) > 1 {
+		output = positional[1]
+	}
+
+	if options.SourceMap && output == "" {
+		fmt.Fprintln(stderr, "c6c: --sourcemap requires the output file, use --sourcemap-inline for the standard output")
+		return ExitUsageError
+	}
+
+	if input == "" || input == "-" {
+		err = compileStdin(stdin, output, stdout, options)
+	} else if info, statErr := os.Stat(input); statErr != nil {
+		err = statErr
+	} else if info.IsDir() {
+		if output == "" {
+			fmt.Fprintln(stderr, "c6c: the output directory is required when the input is a directory")
+			return ExitUsageError
+		}
+		err = CompileDirectory(input, output, options)
+	} else if output == "" {
+		err = CompileFile(input, stdout, options)
+	} else {
+		err = CompileFileTo(input, output, options)
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "c6c: %s\n", err)
+		return ExitCompileError
+	}
+	return ExitSuccess
+}
+
+/*
+compileOptions converts the options to the options of compile.Compile, the
+input is read from the reader instead of the file if it's not nil.
+*/
+func compileOptions(options Options, input io.Reader) compile.Options {
+	var compileOptions = compile.Options{
+		Style:     options.Style,
+		LoadPaths: options.LoadPaths,
+		Input:     input,
+	}
+	if input != nil && options.Indented {
+		compileOptions.FileType = c6.SassFileType
+	}
+	return compileOptions
+}
+
+func compileStdin(stdin io.Reader, output string, stdout io.Writer, options Options) error {
+	data, err := ioutil.ReadAll(stdin)
+	if err != nil {
+		return err
+	}
+	var compileOptions = compileOptions(options, bytes.NewReader(data))
+	var sources = map[string]string{"{stdin}": string(data)}
+	if output == "" {
+		css, _, err := CompileCSS("{stdin}", "", sources, compileOptions, options)
+		if err != nil {
+			return err
+		}
+		_, err = stdout.Write(css)
+		return err
+	}
+	return WriteCSS("{stdin}", output, sources, compileOptions, options)
+}
+
+/*
+CompileFile compiles the file and writes the CSS to the writer.
+*/
+func CompileFile(input string, writer io.Writer, options Options) error {
+	css, _, err := CompileCSS(input, "", nil, compileOptions(options, nil), options)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(css)
+	return err
+}
+
+/*
+CompileFileTo compiles the file to the output file, the output file is not
+touched when the compilation fails.
+*/
+func CompileFileTo(input string, output string, options Options) error {
+	return WriteCSS(input, output, nil, compileOptions(options, nil), options)
+}
+
+/*
+CompileCSS compiles the input file by compile.Compile and builds the source
+map by the options. The output is the path of the CSS file, the sources in the
+source map are relative to the directory of the output, it's empty for the
+standard output. The sources contains the contents of the sources which are
+not files, e.g. the standard input.
+*/
+func CompileCSS(input string, output string, sources map[string]string, compileOptions compile.Options, options Options) ([]byte, []byte, error) {
+	var buf bytes.Buffer
+	if !options.SourceMap && !options.SourceMapInline {
+		err := compile.Compile(&buf, input, compileOptions)
+		return buf.Bytes(), nil, err
+	}
+
+	var sourceMap = sourcemap.NewSourceMap(filepath.Base(output))
+	if output == "" {
+		sourceMap.File = ""
+	}
+	sourceMap.IncludeSourcesContent = options.SourceMapContents
+	compileOptions.SourceMap = sourceMap
+	if err := compile.Compile(&buf, input, compileOptions); err != nil {
+		return nil, nil, err
+	}
+
+	for _, source := range sourceMap.Sources {
+		if content, ok := sources[source]; ok {
+			sourceMap.SetSourceContent(source, content)
+		} else if options.SourceMapContents {
+			if data, err := ioutil.ReadFile(source); err == nil {
+				sourceMap.SetSourceContent(source, string(data))
+			}
+		}
+	}
+	var mapDir = "."
+	if output != "" {
+		mapDir = filepath.Dir(output)
+	}
+	sourceMap.RenameSources(func(source string) string {
+		return SourceMapPath(mapDir, source)
+	})
+
+	if options.SourceMapInline {
+		url, err := sourceMap.DataURL()
+		if err != nil {
+			return nil, nil, err
+		}
+		buf.WriteString(sourcemap.MappingURLComment(url) + "\n")
+		return buf.Bytes(), nil, nil
+	}
+
+	mapData, err := json.Marshal(sourceMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	buf.WriteString(sourcemap.MappingURLComment(filepath.Base(output)+".map") + "\n")
+	return buf.Bytes(), mapData, nil
+}
+
+/*
+WriteCSS compiles the input file to the output file, the source map is
+written to "<output>.map" if it's enabled.
+*/
+func WriteCSS(input string, output string, sources map[string]string, compileOptions compile.Options, options Options) error {
+	css, mapData, err := CompileCSS(input, output, sources, compileOptions, options)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(output, css); err != nil {
+		return err
+	}
+	if mapData != nil {
+		return writeFile(output+".map", mapData)
+	}
+	return nil
+}
+
+/*
+SourceMapPath returns the path of the source relative to the directory of the
+source map, the names like "{stdin}" are kept.
+*/
+func SourceMapPath(mapDir string, source string) string {
+	if strings.HasPrefix(source, "{") {
+		return source
+	}
+	absSource, err := filepath.Abs(source)
+	if err != nil {
+		return filepath.ToSlash(source)
+	}
+	absDir, err := filepath.Abs(mapDir)
+	if err != nil {
+		return filepath.ToSlash(source)
+	}
+	rel, err := filepath.Rel(absDir, absSource)
+	if err != nil {
+		return filepath.ToSlash(source)
+	}
+	return filepath.ToSlash(rel)
+}
+
+/*
+IsEntryFile returns true if the file should be compiled to a CSS file, the
+partials, e.g. "_variables.scss", are only compiled via @import.
+*/
+func IsEntryFile(path string) bool {
+	return c6.IsSourceFile(path) && !strings.HasPrefix(filepath.Base(path), "_")
+}
 
+/*
+OutputPath returns the path of the CSS file compiled from the input file in
+the input directory.
+*/
+func OutputPath(inputDir string, outputDir string, input string) (string, error) {
+	rel, err := filepath.Rel(inputDir, input)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(outputDir, strings.TrimSuffix(rel, filepath.Ext(rel))+".css"), nil
+}
+
+/*
+CompileDirectory compiles the entry files in the input directory to the output
+directory, the directory structure is kept. The first error stops the
+compilation.
+*/
+func CompileDirectory(inputDir string, outputDir string, options Options) error {
+	return filepath.Walk(inputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !IsEntryFile(path) {
+			return nil
+		}
+		output, err := OutputPath(inputDir, outputDir, path)
+		if err != nil {
+			return err
+		}
+		return CompileFileTo(path, output, options)
+	})
+}
+
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
 }
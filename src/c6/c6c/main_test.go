@@ -0,0 +1,220 @@
+package main
+
+import "bytes"
+import "encoding/base64"
+import "encoding/json"
+import "io/ioutil"
+import "os"
+import "path/filepath"
+import "strings"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func runCommand(args []string, stdin string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	var code = run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+}
+
+func TestCompileStdin(t *testing.T) {
+	code, stdout, stderr := runCommand([]string{"--style", "compressed"}, `div { color: red; }`)
+	assert.Equal(t, ExitSuccess, code)
+	assert.Equal(t, "div{color:red}\n", stdout)
+	assert.Equal(t, "", stderr)
+}
+
+func TestCompileIndentedStdin(t *testing.T) {
+	code, stdout, stderr := runCommand([]string{"--style", "compressed", "--indented"}, "div\n  color: red\n")
+	assert.Equal(t, ExitSuccess, code, stderr)
+	assert.Equal(t, "div{color:red}\n", stdout)
+}
+
+func TestCompileFileToStdout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6c")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var input = filepath.Join(dir, "main.scss")
+	writeTestFile(t, input, `div { color: red; }`)
+
+	code, stdout, _ := runCommand([]string{input, "-style=expanded"}, "")
+	assert.Equal(t, ExitSuccess, code)
+	assert.Equal(t, "div {\n  color: red;\n}\n", stdout)
+}
+
+func TestCompileFileToOutputFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6c")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var input = filepath.Join(dir, "main.scss")
+	var output = filepath.Join(dir, "css", "main.css")
+	writeTestFile(t, input, `div { color: red; }`)
+
+	code, _, _ := runCommand([]string{"--style=compact", input, output}, "")
+	assert.Equal(t, ExitSuccess, code)
+	data, err := ioutil.ReadFile(output)
+	assert.Nil(t, err)
+	assert.Equal(t, "div { color: red; }\n", string(data))
+}
+
+func TestCompileFileWithImports(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6c")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var input = filepath.Join(dir, "src", "main.scss")
+	writeTestFile(t, input, `@import "base", "print.css"; div { @import "mixins"; }`)
+	writeTestFile(t, filepath.Join(dir, "src", "_base.scss"), `a { color: red; }`)
+	writeTestFile(t, filepath.Join(dir, "lib", "mixins", "_index.scss"), `span { float: left; }`)
+
+	code, stdout, stderr := runCommand([]string{"--style=compact", "-I", filepath.Join(dir, "lib"), input}, "")
+	assert.Equal(t, ExitSuccess, code, stderr)
+	assert.Equal(t, "@import \"print.css\";\na { color: red; }\n\ndiv span { float: left; }\n", stdout)
+}
+
+func TestCompileMixinsFromPartial(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6c")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var input = filepath.Join(dir, "main.scss")
+	writeTestFile(t, input, `@import "mixins"; .btn { @include button(red, $size: 14px); }`)
+	writeTestFile(t, filepath.Join(dir, "_mixins.scss"), `@mixin button($color, $size: 12px) { color: $color; font-size: $size; }`)
+
+	code, stdout, stderr := runCommand([]string{"--style=compressed", input}, "")
+	assert.Equal(t, ExitSuccess, code, stderr)
+	assert.Equal(t, ".btn{color:red;font-size:14px}\n", stdout)
+
+	writeTestFile(t, input, `.btn { @include missing; }`)
+	code, _, stderr = runCommand([]string{input}, "")
+	assert.Equal(t, ExitCompileError, code)
+	assert.Contains(t, stderr, "main.scss:1: Undefined mixin 'missing'")
+}
+
+func TestCompileIndentedMixinShorthands(t *testing.T) {
+	code, stdout, stderr := runCommand([]string{"--style", "compressed", "--indented"}, "=rounded($r: 2px)\n  border-radius: $r\n.box\n  +rounded(3px)\n")
+	assert.Equal(t, ExitSuccess, code, stderr)
+	assert.Equal(t, ".box{border-radius:3px}\n", stdout)
+}
+
+func TestCompileDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6c")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var src = filepath.Join(dir, "src")
+	var dist = filepath.Join(dir, "dist")
+	writeTestFile(t, filepath.Join(src, "main.scss"), `div { color: red; }`)
+	writeTestFile(t, filepath.Join(src, "pages", "home.scss"), `p { margin: 0; }`)
+	writeTestFile(t, filepath.Join(src, "_partial.scss"), `span { float: left; }`)
+	writeTestFile(t, filepath.Join(src, "README.md"), `readme`)
+
+	code, _, stderr := runCommand([]string{"-I", "vendor", "--load-path", "lib", src, dist}, "")
+	assert.Equal(t, ExitSuccess, code)
+	assert.Equal(t, "", stderr)
+
+	_, err = os.Stat(filepath.Join(dist, "main.css"))
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(dist, "pages", "home.css"))
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(dist, "_partial.css"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dist, "README.css"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCompileDirectoryWithoutOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6c")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	code, _, stderr := runCommand([]string{dir}, "")
+	assert.Equal(t, ExitUsageError, code)
+	assert.Contains(t, stderr, "output directory is required")
+}
+
+func TestCompileMissingFile(t *testing.T) {
+	code, _, stderr := runCommand([]string{"does-not-exist.scss"}, "")
+	assert.Equal(t, ExitCompileError, code)
+	assert.Contains(t, stderr, "does-not-exist.scss")
+}
+
+func TestCompileSyntaxError(t *testing.T) {
+	code, stdout, stderr := runCommand([]string{}, "div { color: red; }\np { color: red;")
+	assert.Equal(t, ExitCompileError, code)
+	assert.Equal(t, "", stdout)
+	assert.Contains(t, stderr, "c6c: {stdin}:2:")
+}
+
+func TestUnknownStyle(t *testing.T) {
+	code, _, stderr := runCommand([]string{"--style", "minified"}, "")
+	assert.Equal(t, ExitUsageError, code)
+	assert.Contains(t, stderr, "Unknown output style")
+}
+
+func TestUnknownOption(t *testing.T) {
+	code, _, stderr := runCommand([]string{"--foo"}, "")
+	assert.Equal(t, ExitUsageError, code)
+	assert.Contains(t, stderr, "Usage: c6c")
+}
+
+func TestIsEntryFile(t *testing.T) {
+	assert.True(t, IsEntryFile("src/main.scss"))
+	assert.True(t, IsEntryFile("src/main.sass"))
+	assert.True(t, IsEntryFile("src/main.ecss"))
+	assert.False(t, IsEntryFile("src/_variables.scss"))
+	assert.False(t, IsEntryFile("src/main.css"))
+}
+
+func TestCompileWithSourceMapFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6c")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var input = filepath.Join(dir, "scss", "main.scss")
+	var output = filepath.Join(dir, "css", "main.css")
+	writeTestFile(t, input, "div {\n  color: red;\n}")
+
+	code, _, stderr := runCommand([]string{"--sourcemap", "--sourcemap-contents", input, output}, "")
+	assert.Equal(t, ExitSuccess, code)
+	assert.Equal(t, "", stderr)
+
+	css, err := ioutil.ReadFile(output)
+	assert.Nil(t, err)
+	assert.Equal(t, "div {\n  color: red; }\n/*# sourceMappingURL=main.css.map */\n", string(css))
+
+	data, err := ioutil.ReadFile(output + ".map")
+	assert.Nil(t, err)
+	var sourceMap map[string]interface{}
+	assert.Nil(t, json.Unmarshal(data, &sourceMap))
+	assert.Equal(t, float64(3), sourceMap["version"])
+	assert.Equal(t, "main.css", sourceMap["file"])
+	assert.Equal(t, []interface{}{"../scss/main.scss"}, sourceMap["sources"])
+	assert.Equal(t, []interface{}{"div {\n  color: red;\n}"}, sourceMap["sourcesContent"])
+	assert.Equal(t, "AAAA;EACE", sourceMap["mappings"])
+}
+
+func TestCompileWithInlineSourceMap(t *testing.T) {
+	code, stdout, _ := runCommand([]string{"--sourcemap-inline", "--sourcemap-contents", "--style", "compressed"}, "div { color: red; }")
+	assert.Equal(t, ExitSuccess, code)
+
+	var prefix = "div{color:red}\n/*# sourceMappingURL=data:application/json;charset=utf-8;base64,"
+	assert.True(t, strings.HasPrefix(stdout, prefix))
+	var encoded = strings.TrimSuffix(stdout[len(prefix):], " */\n")
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"sources":["{stdin}"]`)
+	assert.Contains(t, string(data), `"sourcesContent":["div { color: red; }"]`)
+}
+
+func TestSourceMapFileRequiresOutput(t *testing.T) {
+	code, _, stderr := runCommand([]string{"--sourcemap"}, "div { color: red; }")
+	assert.Equal(t, ExitUsageError, code)
+	assert.Contains(t, stderr, "--sourcemap-inline")
+}
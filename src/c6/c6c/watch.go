@@ -0,0 +1,287 @@
+package main
+
+import "c6"
+import "fmt"
+import "io"
+import "os"
+import "path/filepath"
+import "sort"
+import "strings"
+import "time"
+
+/*
+The changes reported within the interval are compiled together, editors
+usually write a file with more than one system call.
+*/
+const DebounceInterval = 50 * time.Millisecond
+
+/*
+Project compiles the entry files of the input directory to the output
+directory and tracks the import graph of every entry file, so only the entry
+files depending on the changed files are recompiled.
+*/
+type Project struct {
+	InputDir  string
+	OutputDir string
+	Options   Options
+
+	// the parsed files are cached until they are changed
+	FileAstMap *c6.FileAstMap
+
+	// the entry file => the files imported by the entry file directly or
+	// indirectly
+	Dependencies map[string]map[string]bool
+}
+
+func NewProject(inputDir string, outputDir string, options Options) (*Project, error) {
+	inputDir, err := filepath.Abs(inputDir)
+	if err != nil {
+		return nil, err
+	}
+	outputDir, err = filepath.Abs(outputDir)
+	if err != nil {
+		return nil, err
+	}
+	var loadPaths = []string{}
+	for _, loadPath := range options.LoadPaths {
+		if loadPath, err = filepath.Abs(loadPath); err != nil {
+			return nil, err
+		}
+		loadPaths = append(loadPaths, loadPath)
+	}
+	options.LoadPaths = loadPaths
+	return &Project{
+		InputDir:     inputDir,
+		OutputDir:    outputDir,
+		Options:      options,
+		FileAstMap:   c6.NewFileAstMap(),
+		Dependencies: map[string]map[string]bool{},
+	}, nil
+}
+
+/*
+ParseWatchArgument splits the "src:dist" argument of --watch.
+*/
+func ParseWatchArgument(arg string) (string, string, error) {
+	var idx = strings.LastIndex(arg, ":")
+	if idx <= 0 || idx == len(arg)-1 {
+		return "", "", fmt.Errorf("invalid --watch argument '%s', expecting 'input-dir:output-dir'", arg)
+	}
+	return arg[:idx], arg[idx+1:], nil
+}
+
+func (self *Project) EntryFiles() ([]string, error) {
+	var entries = []string{}
+	var err = filepath.Walk(self.InputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && IsEntryFile(path) {
+			entries = append(entries, path)
+		}
+		return nil
+	})
+	return entries, err
+}
+
+/*
+CompileEntry compiles the entry file via the cached files and updates the
+import graph of the entry file.
+*/
+func (self *Project) CompileEntry(entry string) error {
+	output, err := OutputPath(self.InputDir, self.OutputDir, entry)
+	if err != nil {
+		return err
+	}
+	var compileOptions = compileOptions(self.Options, nil)
+	compileOptions.FileAstMap = self.FileAstMap
+	err = WriteCSS(entry, output, nil, compileOptions, self.Options)
+
+	// the entry file is in the map unless the parsing failed
+	if fileAst, ok := self.FileAstMap.Get(filepath.Clean(entry)); ok {
+		var dependencies = map[string]bool{}
+		for _, imported := range fileAst.Imports {
+			dependencies[imported] = true
+		}
+		self.Dependencies[entry] = dependencies
+	}
+	return err
+}
+
+/*
+Build compiles the entry files which are changed or depend on the changed
+files, all the entry files are compiled when changed is nil. The compiled
+entry files are returned with the errors.
+*/
+func (self *Project) Build(changed []string) ([]string, []error) {
+	var changedSet = map[string]bool{}
+	for _, path := range changed {
+		path = filepath.Clean(path)
+		changedSet[path] = true
+		self.FileAstMap.InvalidateDependents(path)
+	}
+
+	entries, err := self.EntryFiles()
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	var existing = map[string]bool{}
+	var compiled = []string{}
+	var errs = []error{}
+	for _, entry := range entries {
+		existing[entry] = true
+		if changed != nil && !self.dependsOn(entry, changedSet) {
+			continue
+		}
+		if err := self.CompileEntry(entry); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		compiled = append(compiled, entry)
+	}
+
+	for entry := range self.Dependencies {
+		if !existing[entry] {
+			delete(self.Dependencies, entry)
+		}
+	}
+	return compiled, errs
+}
+
+func (self *Project) dependsOn(entry string, changedSet map[string]bool) bool {
+	if changedSet[entry] {
+		return true
+	}
+	dependencies, ok := self.Dependencies[entry]
+	if !ok {
+		// a new entry file, or the last compilation failed
+		return true
+	}
+	for path := range dependencies {
+		if changedSet[path] {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+WatchDirs returns the directories to watch, the input directory, the load
+paths and the directories of the imported files outside of them.
+*/
+func (self *Project) WatchDirs() []string {
+	var dirs = []string{self.InputDir}
+	var candidates = append([]string{}, self.Options.LoadPaths...)
+	for _, dependencies := range self.Dependencies {
+		for path := range dependencies {
+			candidates = append(candidates, filepath.Dir(path))
+		}
+	}
+	sort.Strings(candidates)
+	for _, candidate := range candidates {
+		if !containsDir(dirs, candidate) {
+			if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+				dirs = append(dirs, candidate)
+			}
+		}
+	}
+	return dirs
+}
+
+func containsDir(dirs []string, path string) bool {
+	for _, dir := range dirs {
+		if rel, err := filepath.Rel(dir, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (self *Project) report(stdout io.Writer, stderr io.Writer, compiled []string, errs []error, elapsed time.Duration) {
+	for _, entry := range compiled {
+		output, _ := OutputPath(self.InputDir, self.OutputDir, entry)
+		fmt.Fprintf(stdout, "c6c: compiled %s to %s\n", entry, output)
+	}
+	for _, err := range errs {
+		fmt.Fprintf(stderr, "c6c: %s\n", err)
+	}
+	if len(compiled) > 0 {
+		fmt.Fprintf(stdout, "c6c: %d file(s) compiled in %s\n", len(compiled), elapsed)
+	}
+}
+
+/*
+Watch recompiles the affected entry files on every change reported by the
+watcher until the watcher is closed or done is closed, the directories of the
+new imports are added to the watcher after every build.
+*/
+func (self *Project) Watch(watcher Watcher, stdout io.Writer, stderr io.Writer, done <-chan bool) {
+	var changes = watcher.Changes()
+	for {
+		var changed = []string{}
+		select {
+		case <-done:
+			return
+		case path, ok := <-changes:
+			if !ok {
+				return
+			}
+			changed = append(changed, path)
+		}
+
+		// collect the changes until the watcher is quiet
+		var timer = time.NewTimer(DebounceInterval)
+	collect:
+		for {
+			select {
+			case path, ok := <-changes:
+				if !ok {
+					break collect
+				}
+				changed = append(changed, path)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+
+		var start = time.Now()
+		compiled, errs := self.Build(changed)
+		self.report(stdout, stderr, compiled, errs, time.Since(start))
+
+		// the entry files may import the files of the new directories
+		for _, dir := range self.WatchDirs() {
+			if err := watcher.Add(dir); err != nil {
+				fmt.Fprintf(stderr, "c6c: %s\n", err)
+			}
+		}
+	}
+}
+
+func watch(arg string, poll bool, stdout io.Writer, stderr io.Writer, options Options) int {
+	inputDir, outputDir, err := ParseWatchArgument(arg)
+	if err != nil {
+		fmt.Fprintf(stderr, "c6c: %s\n", err)
+		return ExitUsageError
+	}
+	if info, err := os.Stat(inputDir); err != nil || !info.IsDir() {
+		fmt.Fprintf(stderr, "c6c: the input directory '%s' is not found\n", inputDir)
+		return ExitUsageError
+	}
+	project, err := NewProject(inputDir, outputDir, options)
+	if err != nil {
+		fmt.Fprintf(stderr, "c6c: %s\n", err)
+		return ExitCompileError
+	}
+
+	var start = time.Now()
+	compiled, errs := project.Build(nil)
+	project.report(stdout, stderr, compiled, errs, time.Since(start))
+
+	var watcher = NewWatcher(project.WatchDirs(), poll)
+	defer watcher.Close()
+	fmt.Fprintf(stdout, "c6c: watching %s\n", strings.Join(project.WatchDirs(), ", "))
+	project.Watch(watcher, stdout, stderr, nil)
+	return ExitSuccess
+}
@@ -0,0 +1,210 @@
+package main
+
+import "bytes"
+import "io/ioutil"
+import "os"
+import "path/filepath"
+import "sort"
+import "testing"
+import "time"
+import "github.com/stretchr/testify/assert"
+
+func setupProject(t *testing.T) (string, *Project) {
+	dir, err := ioutil.TempDir("", "c6c")
+	assert.Nil(t, err)
+	dir, _ = filepath.EvalSymlinks(dir)
+
+	writeTestFile(t, filepath.Join(dir, "src", "a.scss"), `@import "partial"; a { color: red; }`)
+	writeTestFile(t, filepath.Join(dir, "src", "b.scss"), `@import url(foo.css); b { color: blue; }`)
+	writeTestFile(t, filepath.Join(dir, "src", "_partial.scss"), `@import "mixins"; p { margin: 0; }`)
+	writeTestFile(t, filepath.Join(dir, "lib", "_mixins.scss"), `span { float: left; }`)
+
+	project, err := NewProject(filepath.Join(dir, "src"), filepath.Join(dir, "dist"), Options{LoadPaths: []string{filepath.Join(dir, "lib")}})
+	assert.Nil(t, err)
+	return dir, project
+}
+
+func TestParseWatchArgument(t *testing.T) {
+	input, output, err := ParseWatchArgument("src/scss:dist/css")
+	assert.Nil(t, err)
+	assert.Equal(t, "src/scss", input)
+	assert.Equal(t, "dist/css", output)
+
+	_, _, err = ParseWatchArgument("src")
+	assert.NotNil(t, err)
+	_, _, err = ParseWatchArgument("src:")
+	assert.NotNil(t, err)
+}
+
+func TestProjectBuild(t *testing.T) {
+	dir, project := setupProject(t)
+	defer os.RemoveAll(dir)
+
+	compiled, errs := project.Build(nil)
+	assert.Equal(t, 0, len(errs))
+	sort.Strings(compiled)
+	assert.Equal(t, []string{filepath.Join(dir, "src", "a.scss"), filepath.Join(dir, "src", "b.scss")}, compiled)
+
+	_, err := os.Stat(filepath.Join(dir, "dist", "a.css"))
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(dir, "dist", "_partial.css"))
+	assert.True(t, os.IsNotExist(err))
+
+	var dependencies = project.Dependencies[filepath.Join(dir, "src", "a.scss")]
+	assert.True(t, dependencies[filepath.Join(dir, "src", "_partial.scss")])
+	assert.True(t, dependencies[filepath.Join(dir, "lib", "_mixins.scss")])
+	assert.Equal(t, 0, len(project.Dependencies[filepath.Join(dir, "src", "b.scss")]))
+}
+
+func TestProjectBuildChangedPartial(t *testing.T) {
+	dir, project := setupProject(t)
+	defer os.RemoveAll(dir)
+	project.Build(nil)
+
+	// the files importing the changed file are parsed again, the untouched
+	// files stay in the cache
+	partial, ok := project.FileAstMap.Get(filepath.Join(dir, "src", "_partial.scss"))
+	assert.True(t, ok)
+	entry, ok := project.FileAstMap.Get(filepath.Join(dir, "src", "b.scss"))
+	assert.True(t, ok)
+
+	compiled, errs := project.Build([]string{filepath.Join(dir, "lib", "_mixins.scss")})
+	assert.Equal(t, 0, len(errs))
+	assert.Equal(t, []string{filepath.Join(dir, "src", "a.scss")}, compiled)
+
+	cached, ok := project.FileAstMap.Get(filepath.Join(dir, "src", "_partial.scss"))
+	assert.True(t, ok)
+	assert.False(t, partial == cached)
+	cached, ok = project.FileAstMap.Get(filepath.Join(dir, "src", "b.scss"))
+	assert.True(t, ok)
+	assert.True(t, entry == cached)
+
+	compiled, errs = project.Build([]string{filepath.Join(dir, "src", "b.scss")})
+	assert.Equal(t, 0, len(errs))
+	assert.Equal(t, []string{filepath.Join(dir, "src", "b.scss")}, compiled)
+
+	compiled, errs = project.Build([]string{filepath.Join(dir, "src", "unrelated.txt")})
+	assert.Equal(t, 0, len(errs))
+	assert.Equal(t, 0, len(compiled))
+}
+
+func TestProjectBuildError(t *testing.T) {
+	dir, project := setupProject(t)
+	defer os.RemoveAll(dir)
+	project.Build(nil)
+
+	var partial = filepath.Join(dir, "src", "_partial.scss")
+	writeTestFile(t, partial, `p { margin: 0;`)
+	// the import graph is kept after a failed compilation of the partial
+	compiled, errs := project.Build([]string{partial})
+	assert.Equal(t, 0, len(compiled))
+	assert.Equal(t, 1, len(errs))
+
+	writeTestFile(t, partial, `p { margin: 0; }`)
+	compiled, errs = project.Build([]string{partial})
+	assert.Equal(t, 0, len(errs))
+	assert.Equal(t, []string{filepath.Join(dir, "src", "a.scss")}, compiled)
+}
+
+func TestProjectWatchDirs(t *testing.T) {
+	dir, project := setupProject(t)
+	defer os.RemoveAll(dir)
+	project.Build(nil)
+	assert.Equal(t, []string{filepath.Join(dir, "src"), filepath.Join(dir, "lib")}, project.WatchDirs())
+}
+
+type fakeWatcher struct {
+	changes chan string
+	dirs    []string
+}
+
+func (self *fakeWatcher) Changes() <-chan string { return self.changes }
+func (self *fakeWatcher) Close() error           { close(self.changes); return nil }
+
+func (self *fakeWatcher) Add(dir string) error {
+	if !containsDir(self.dirs, dir) {
+		self.dirs = append(self.dirs, dir)
+	}
+	return nil
+}
+
+func TestProjectWatch(t *testing.T) {
+	dir, project := setupProject(t)
+	defer os.RemoveAll(dir)
+	project.Build(nil)
+
+	var watcher = &fakeWatcher{make(chan string, 2), project.WatchDirs()}
+	watcher.changes <- filepath.Join(dir, "src", "_partial.scss")
+	watcher.changes <- filepath.Join(dir, "src", "_partial.scss")
+	watcher.Close()
+
+	var stdout, stderr bytes.Buffer
+	project.Watch(watcher, &stdout, &stderr, nil)
+	assert.Contains(t, stdout.String(), "compiled "+filepath.Join(dir, "src", "a.scss"))
+	assert.NotContains(t, stdout.String(), "b.scss")
+	assert.Contains(t, stdout.String(), "1 file(s) compiled")
+	assert.Equal(t, "", stderr.String())
+}
+
+func TestProjectWatchAddsImportedDirs(t *testing.T) {
+	dir, project := setupProject(t)
+	defer os.RemoveAll(dir)
+	project.Build(nil)
+
+	var watcher = &fakeWatcher{make(chan string, 1), project.WatchDirs()}
+	writeTestFile(t, filepath.Join(dir, "vendor", "_grid.scss"), `.grid { float: left; }`)
+	writeTestFile(t, filepath.Join(dir, "src", "b.scss"), `@import "../vendor/grid"; b { color: blue; }`)
+	watcher.changes <- filepath.Join(dir, "src", "b.scss")
+	watcher.Close()
+
+	var stdout, stderr bytes.Buffer
+	project.Watch(watcher, &stdout, &stderr, nil)
+	assert.Equal(t, "", stderr.String())
+	assert.Equal(t, []string{filepath.Join(dir, "src"), filepath.Join(dir, "lib"), filepath.Join(dir, "vendor")}, watcher.dirs)
+}
+
+func waitForChange(t *testing.T, watcher Watcher, path string) {
+	var timeout = time.After(5 * time.Second)
+	for {
+		select {
+		case changed := <-watcher.Changes():
+			if changed == path {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("the change of %s is not reported", path)
+			return
+		}
+	}
+}
+
+func TestPollingWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6c")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var watcher = NewPollingWatcher([]string{dir}, 10*time.Millisecond)
+	defer watcher.Close()
+
+	var path = filepath.Join(dir, "sub", "_foo.scss")
+	writeTestFile(t, path, `a { color: red; }`)
+	waitForChange(t, watcher, path)
+
+	assert.Nil(t, os.Remove(path))
+	waitForChange(t, watcher, path)
+
+	// the existing files of the added directory are not changes
+	other, err := ioutil.TempDir("", "c6c")
+	assert.Nil(t, err)
+	defer os.RemoveAll(other)
+	writeTestFile(t, filepath.Join(other, "_old.scss"), `a { color: red; }`)
+	assert.Nil(t, watcher.Add(other))
+	var added = filepath.Join(other, "_new.scss")
+	writeTestFile(t, added, `a { color: red; }`)
+	select {
+	case changed := <-watcher.Changes():
+		assert.Equal(t, added, changed)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the change of %s is not reported", added)
+	}
+}
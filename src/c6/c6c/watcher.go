@@ -0,0 +1,146 @@
+package main
+
+import "os"
+import "path/filepath"
+import "sync"
+import "time"
+
+const DefaultPollInterval = 500 * time.Millisecond
+
+/*
+Watcher reports the paths of the files changed, created or removed in the
+watched directories.
+*/
+type Watcher interface {
+	Changes() <-chan string
+
+	// Add watches the directory as well, the directory within the watched
+	// directories is ignored.
+	Add(dir string) error
+
+	Close() error
+}
+
+/*
+NewWatcher creates the native file system watcher of the platform, e.g.
+inotify on Linux, the polling watcher is used when the native watcher is not
+available or poll is true.
+*/
+func NewWatcher(dirs []string, poll bool) Watcher {
+	if !poll {
+		if watcher, err := NewNativeWatcher(dirs); err == nil {
+			return watcher
+		}
+	}
+	return NewPollingWatcher(dirs, DefaultPollInterval)
+}
+
+type fileStat struct {
+	ModTime time.Time
+	Size    int64
+}
+
+/*
+PollingWatcher scans the directories periodically and compares the
+modification time and the size of the files.
+*/
+type PollingWatcher struct {
+	Dirs     []string
+	Interval time.Duration
+
+	files   map[string]fileStat
+	changes chan string
+	done    chan bool
+
+	// guards Dirs and files, the directories are added while scanning
+	mutex sync.Mutex
+}
+
+func NewPollingWatcher(dirs []string, interval time.Duration) *PollingWatcher {
+	var watcher = &PollingWatcher{
+		Dirs:     dirs,
+		Interval: interval,
+		changes:  make(chan string, 64),
+		done:     make(chan bool),
+	}
+	watcher.files = watcher.scan()
+	go watcher.loop()
+	return watcher
+}
+
+func (self *PollingWatcher) Changes() <-chan string {
+	return self.changes
+}
+
+func (self *PollingWatcher) Add(dir string) error {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if containsDir(self.Dirs, dir) {
+		return nil
+	}
+	self.Dirs = append(self.Dirs, dir)
+	// the existing files of the directory are not changes
+	for path, stat := range scanDirs([]string{dir}) {
+		self.files[path] = stat
+	}
+	return nil
+}
+
+func (self *PollingWatcher) Close() error {
+	close(self.done)
+	return nil
+}
+
+func (self *PollingWatcher) scan() map[string]fileStat {
+	return scanDirs(self.Dirs)
+}
+
+func scanDirs(dirs []string) map[string]fileStat {
+	var files = map[string]fileStat{}
+	for _, dir := range dirs {
+		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && info.Mode().IsRegular() {
+				files[path] = fileStat{info.ModTime(), info.Size()}
+			}
+			return nil
+		})
+	}
+	return files
+}
+
+func (self *PollingWatcher) loop() {
+	var ticker = time.NewTicker(self.Interval)
+	defer ticker.Stop()
+	defer close(self.changes)
+	for {
+		select {
+		case <-self.done:
+			return
+		case <-ticker.C:
+		}
+
+		self.mutex.Lock()
+		var files = self.scan()
+		var changed = []string{}
+		for path, stat := range files {
+			if old, ok := self.files[path]; !ok || old != stat {
+				changed = append(changed, path)
+			}
+		}
+		for path := range self.files {
+			if _, ok := files[path]; !ok {
+				changed = append(changed, path)
+			}
+		}
+		self.files = files
+		self.mutex.Unlock()
+
+		for _, path := range changed {
+			select {
+			case self.changes <- path:
+			case <-self.done:
+				return
+			}
+		}
+	}
+}
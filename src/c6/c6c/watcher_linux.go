@@ -0,0 +1,160 @@
+//go:build linux
+// +build linux
+
+package main
+
+import "os"
+import "path/filepath"
+import "strings"
+import "sync"
+import "syscall"
+import "unsafe"
+
+const inotifyMask = syscall.IN_CLOSE_WRITE | syscall.IN_MODIFY | syscall.IN_CREATE |
+	syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+
+/*
+InotifyWatcher watches the directories recursively with inotify, the
+directories created after the watcher started are watched as well.
+*/
+type InotifyWatcher struct {
+	file    *os.File
+	fd      int
+	dirs    []string
+	watches map[int]string
+	changes chan string
+	done    chan bool
+	mutex   sync.Mutex
+}
+
+func NewNativeWatcher(dirs []string) (Watcher, error) {
+	return NewInotifyWatcher(dirs)
+}
+
+func NewInotifyWatcher(dirs []string) (*InotifyWatcher, error) {
+	// the non-blocking descriptor is read via the runtime poller, so
+	// closing the file stops the pending read.
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+	var watcher = &InotifyWatcher{
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		fd:      fd,
+		watches: map[int]string{},
+		changes: make(chan string, 64),
+		done:    make(chan bool),
+	}
+	for _, dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.file.Close()
+			return nil, err
+		}
+	}
+	go watcher.readEvents()
+	return watcher, nil
+}
+
+func (self *InotifyWatcher) Changes() <-chan string {
+	return self.changes
+}
+
+func (self *InotifyWatcher) Add(dir string) error {
+	self.mutex.Lock()
+	var watched = containsDir(self.dirs, dir)
+	if !watched {
+		self.dirs = append(self.dirs, dir)
+	}
+	self.mutex.Unlock()
+	if watched {
+		return nil
+	}
+	return self.addRecursive(dir)
+}
+
+/*
+Close stops reading the events, the pending change which is not received is
+dropped.
+*/
+func (self *InotifyWatcher) Close() error {
+	close(self.done)
+	return self.file.Close()
+}
+
+// send reports the change unless the watcher is closed.
+func (self *InotifyWatcher) send(path string) bool {
+	select {
+	case self.changes <- path:
+		return true
+	case <-self.done:
+		return false
+	}
+}
+
+func (self *InotifyWatcher) addRecursive(dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return err
+		}
+		wd, err := syscall.InotifyAddWatch(self.fd, path, inotifyMask)
+		if err != nil {
+			return os.NewSyscallError("inotify_add_watch", err)
+		}
+		self.mutex.Lock()
+		self.watches[wd] = path
+		self.mutex.Unlock()
+		return nil
+	})
+}
+
+func (self *InotifyWatcher) readEvents() {
+	defer close(self.changes)
+	var buf [syscall.SizeofInotifyEvent * 4096]byte
+	for {
+		n, err := self.file.Read(buf[:])
+		if err != nil {
+			return
+		}
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			var event = (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			var nameStart = offset + syscall.SizeofInotifyEvent
+			var name = strings.TrimRight(string(buf[nameStart:nameStart+int(event.Len)]), "\x00")
+			offset = nameStart + int(event.Len)
+
+			self.mutex.Lock()
+			dir, ok := self.watches[int(event.Wd)]
+			if event.Mask&syscall.IN_IGNORED != 0 {
+				delete(self.watches, int(event.Wd))
+			}
+			self.mutex.Unlock()
+			if !ok || name == "" {
+				continue
+			}
+
+			var path = filepath.Join(dir, name)
+			if event.Mask&syscall.IN_ISDIR != 0 {
+				if event.Mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 && !self.addDirectory(path) {
+					return
+				}
+				continue
+			}
+			if !self.send(path) {
+				return
+			}
+		}
+	}
+}
+
+// addDirectory watches the new directory and reports the files in it, false
+// is returned when the watcher is closed.
+func (self *InotifyWatcher) addDirectory(dir string) bool {
+	self.addRecursive(dir)
+	var open = true
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && open && info.Mode().IsRegular() {
+			open = self.send(path)
+		}
+		return nil
+	})
+	return open
+}
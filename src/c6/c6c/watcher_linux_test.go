@@ -0,0 +1,64 @@
+package main
+
+import "fmt"
+import "io/ioutil"
+import "os"
+import "path/filepath"
+import "testing"
+import "time"
+import "github.com/stretchr/testify/assert"
+
+func TestInotifyWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6c")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	watcher, err := NewInotifyWatcher([]string{dir})
+	assert.Nil(t, err)
+	defer watcher.Close()
+
+	var path = filepath.Join(dir, "_foo.scss")
+	writeTestFile(t, path, `a { color: red; }`)
+	waitForChange(t, watcher, path)
+
+	// the files in the new directories are watched as well
+	var nested = filepath.Join(dir, "sub", "_bar.scss")
+	writeTestFile(t, nested, `a { color: red; }`)
+	waitForChange(t, watcher, nested)
+	writeTestFile(t, nested, `a { color: blue; }`)
+	waitForChange(t, watcher, nested)
+}
+
+func TestInotifyWatcherClose(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6c")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	watcher, err := NewInotifyWatcher([]string{dir})
+	assert.Nil(t, err)
+
+	// more changes than the buffer of the channel, nobody receives them
+	for i := 0; i < 100; i++ {
+		writeTestFile(t, filepath.Join(dir, fmt.Sprintf("_%d.scss", i)), `a { color: red; }`)
+	}
+	time.Sleep(50 * time.Millisecond)
+	watcher.Close()
+
+	// the reading stops at the full channel instead of waiting for the
+	// receiver, only the buffered changes are left
+	var received = 0
+	var timeout = time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-watcher.Changes():
+			if !ok {
+				assert.True(t, received < 100, "%d changes received after closing", received)
+				return
+			}
+			received++
+		case <-timeout:
+			t.Fatalf("the changes are not closed")
+			return
+		}
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package main
+
+import "errors"
+
+func NewNativeWatcher(dirs []string) (Watcher, error) {
+	return nil, errors.New("the native file system watcher is not supported on this platform")
+}
@@ -0,0 +1,73 @@
+package c6
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestColorFunctions(t *testing.T) {
+	var cases = map[string]string{
+		`rgb(255, 0, 0)`:                    "#ff0000",
+		`rgb(100%, 50%, 0)`:                 "#ff8000",
+		`rgba(0, 0, 0, 0.5)`:                "rgba(0, 0, 0, 0.5)",
+		`rgba(red, 0.3)`:                    "rgba(255, 0, 0, 0.3)",
+		`rgb(var(red), 0, 0)`:               "rgb(var(red), 0, 0)",
+		`hsl(120, 100%, 50%)`:               "#00ff00",
+		`hsla(0.5turn, 50%, 50%, 0.5)`:      "rgba(64, 191, 191, 0.5)",
+		`red(#123456)`:                      "18",
+		`green(#123456)`:                    "52",
+		`blue(#123456)`:                     "86",
+		`hue(#336699)`:                      "210deg",
+		`saturation(#336699)`:               "50%",
+		`lightness(#336699)`:                "40%",
+		`alpha(rgba(0, 0, 0, 0.4))`:         "0.4",
+		`opacity(red)`:                      "1",
+		`mix(#f00, #00f)`:                   "#800080",
+		`mix(#f00, #00f, 25%)`:              "#4000bf",
+		`mix(transparent, #fff)`:            "rgba(255, 255, 255, 0.5)",
+		`lighten(#336699, 20%)`:             "#6699cc",
+		`darken(#336699, 20%)`:              "#1a334d",
+		`saturate(#336699, 20%)`:            "#1f66ad",
+		`desaturate(#336699, 20%)`:          "#476685",
+		`adjust-hue(#336699, 180deg)`:       "#996633",
+		`grayscale(#336699)`:                "#666666",
+		`complement(#336699)`:               "#996633",
+		`invert(#336699)`:                   "#cc9966",
+		`invert(#336699, 50%)`:              "#808080",
+		`opacify(rgba(0, 0, 0, 0.5), 0.2)`:  "rgba(0, 0, 0, 0.7)",
+		`fade-in(rgba(0, 0, 0, 0.5), 0.5)`:  "#000000",
+		`transparentize(#000, 0.25)`:        "rgba(0, 0, 0, 0.75)",
+		`fade-out(rgba(0, 0, 0, 0.5), 0.8)`: "rgba(0, 0, 0, 0)",
+		`saturate(50%)`:                     "saturate(50%)",
+		`grayscale(1)`:                      "grayscale(1)",
+		`invert(20%)`:                       "invert(20%)",
+		`opacity(50%)`:                      "opacity(50%)",
+	}
+	for expr, expected := range cases {
+		css, err := evaluateScss(`.a { value: ` + expr + `; }`)
+		if assert.Nil(t, err, expr) {
+			assert.Equal(t, ".a { value: "+expected+"; }\n", css, expr)
+		}
+	}
+}
+
+func TestColorFunctionErrors(t *testing.T) {
+	var cases = map[string]string{
+		`.a { color: red(10px); }`:                 "$color: 10px is not a color for function 'red'",
+		`.a { color: rgb(1px, 0, 0); }`:            "$red: 1px should be unitless or a percentage for function 'rgb'",
+		`.a { color: rgba(0, 0, 0, 50%); }`:        "$alpha: 50% should be unitless for function 'rgba'",
+		`.a { color: rgba(#f00); }`:                "Missing argument $alpha of function 'rgba'",
+		`.a { color: rgba(#f00, 2); }`:             "$alpha: 2 is not within 0 and 1 for function 'rgba'",
+		`.a { color: rgb(10, 20); }`:               "Missing argument $blue of function 'rgb'",
+		`.a { color: rgb(10); }`:                   "Missing argument $green of function 'rgb'",
+		`.a { color: hsl(1px, 50%, 50%); }`:        "$hue: 1px should be unitless or an angle for function 'hsl'",
+		`.a { color: lighten(#333, 120%); }`:       "$amount: 120% is not within 0% and 100% for function 'lighten'",
+		`.a { color: opacify(#333, 2); }`:          "$amount: 2 is not within 0 and 1 for function 'opacify'",
+		`.a { color: mix(#333); }`:                 "Missing argument $color2 of function 'mix'",
+		`.a { color: adjust-hue(#333, $foo: 1); }`: "No argument named $foo for function 'adjust-hue'",
+	}
+	for code, message := range cases {
+		_, err := evaluateScss(code)
+		if assert.NotNil(t, err, code) {
+			assert.Contains(t, err.Error(), message)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Package compile is the entry point of the library, it compiles the SCSS, the
+indented syntax and ECSS files to CSS.
+*/
+package compile
+
+import "c6"
+import "c6/ast"
+import "c6/compiler"
+import "c6/logger"
+import "c6/runtime"
+import "c6/sourcemap"
+import "io"
+import "io/ioutil"
+
+/*
+Options are the options of Compile, the zero value compiles the file in the
+nested style.
+*/
+type Options struct {
+	// the output style of the CSS
+	Style compiler.OutputStyle
+
+	// the directories to look up the imported files
+	LoadPaths []string
+
+	// the importers to try before the load paths
+	Importers []c6.Importer
+
+	// the code to compile instead of the content of the file, e.g. the
+	// standard input. The file is the name of the code in the error messages
+	// and the source map then.
+	Input io.Reader
+
+	// the syntax of the Input, e.g. c6.SassFileType, SCSS is used if it's
+	// c6.UnknownFileType. The syntax of the file is decided by its extension.
+	FileType uint
+
+	// the file and the imported files are parsed via the map if it's not nil
+	FileAstMap *c6.FileAstMap
+
+	// the mappings of the output are added to the source map if it's not nil
+	SourceMap *sourcemap.SourceMap
+
+	// receives the messages of @debug and @warn, nil means logger.DefaultSink
+	Diagnostics logger.Sink
+}
+
+/*
+Compile compiles the file to CSS and writes the CSS to the writer, it parses
+the file, evaluates the statements and compiles them in the output style:
+
+	var buf bytes.Buffer
+	err := compile.Compile(&buf, "main.scss", compile.Options{Style: compiler.CompressedStyle})
+*/
+func Compile(writer io.Writer, file string, options Options) error {
+	var context = c6.NewContext()
+	context.LoadPaths = append(context.LoadPaths, options.LoadPaths...)
+	context.Importers = append(context.Importers, options.Importers...)
+	context.FileAstMap = options.FileAstMap
+
+	stmts, err := parseInput(context, file, options)
+	if err != nil {
+		return err
+	}
+
+	var interpreter = runtime.NewInterpreter()
+	interpreter.ParseSelectors = c6.ParseSelectorGroup
+	interpreter.ParseMediaQueries = c6.ParseMediaQueryList
+	interpreter.Diagnostics = options.Diagnostics
+	if stmts, err = interpreter.EvaluateStatements(stmts); err != nil {
+		return err
+	}
+
+	var cssCompiler = compiler.NewCompiler(writer, options.Style)
+	if options.SourceMap != nil {
+		cssCompiler.SetSourceMap(options.SourceMap)
+	}
+	return cssCompiler.CompileStatements(stmts)
+}
+
+func parseInput(context *c6.Context, file string, options Options) ([]ast.Statement, error) {
+	if options.Input == nil {
+		if context.FileAstMap != nil {
+			fileAst, err := context.FileAstMap.ParseFile(context, file)
+			if err != nil {
+				return nil, err
+			}
+			return fileAst.Statements, nil
+		}
+		return c6.NewParser(context).ParseFile(file)
+	}
+
+	data, err := ioutil.ReadAll(options.Input)
+	if err != nil {
+		return nil, err
+	}
+	var parser = c6.NewParser(context)
+	parser.File = file
+	return parser.Parse(string(data), options.FileType)
+}
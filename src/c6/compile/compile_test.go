@@ -0,0 +1,54 @@
+package compile
+
+import "c6"
+import "c6/compiler"
+import "c6/logger"
+import "bytes"
+import "io/ioutil"
+import "os"
+import "path/filepath"
+import "strings"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestCompileFileInStyles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "main.scss"), []byte(`@import "base"; .a { &:hover { x: $x; } }`), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "_base.scss"), []byte(`$x: 1px;`), 0644))
+
+	var expected = map[compiler.OutputStyle]string{
+		compiler.NestedStyle:     ".a:hover {\n  x: 1px; }\n",
+		compiler.ExpandedStyle:   ".a:hover {\n  x: 1px;\n}\n",
+		compiler.CompactStyle:    ".a:hover { x: 1px; }\n",
+		compiler.CompressedStyle: ".a:hover{x:1px}\n",
+	}
+	for style, css := range expected {
+		var buf bytes.Buffer
+		assert.Nil(t, Compile(&buf, filepath.Join(dir, "main.scss"), Options{Style: style}), style.String())
+		assert.Equal(t, css, buf.String(), style.String())
+	}
+}
+
+func TestCompileInput(t *testing.T) {
+	var buf bytes.Buffer
+	var sink = logger.NewCollector()
+	var err = Compile(&buf, "{stdin}", Options{
+		Style:       compiler.CompactStyle,
+		Input:       strings.NewReader(".a\n  @debug hello\n  x: 1\n"),
+		FileType:    c6.SassFileType,
+		Diagnostics: sink,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { x: 1; }\n", buf.String())
+	assert.Equal(t, 1, len(sink.Diagnostics))
+}
+
+func TestCompileError(t *testing.T) {
+	var buf bytes.Buffer
+	var err = Compile(&buf, "{stdin}", Options{Input: strings.NewReader(".a {\n  x: $nope;\n}")})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "{stdin}:2: Undefined variable $nope", err.Error())
+	}
+}
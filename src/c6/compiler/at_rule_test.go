@@ -0,0 +1,38 @@
+package compiler
+
+import "testing"
+
+func TestCompactStyleSupports(t *testing.T) {
+	AssertCompile(t, CompactStyle, `@supports (display: flex) and (not ((display: grid) or (display: table))) { .a { x: y; } }`,
+		"@supports (display: flex) and (not ((display: grid) or (display: table))) { .a { x: y; } }\n")
+	AssertCompile(t, CompressedStyle, `@supports not (display: flex) { .a { x: y; } }`,
+		"@supports not (display:flex){.a{x:y}}\n")
+}
+
+func TestBubbleSupportsOutOfRuleSet(t *testing.T) {
+	AssertCompile(t, CompactStyle, `.a { color: red; @supports (display: flex) { display: flex; .b { x: y; } @media screen { z: w; } } }`,
+		".a { color: red; }\n\n@supports (display: flex) { .a { display: flex; } .a .b { x: y; } @media screen { .a { z: w; } } }\n")
+}
+
+func TestCompactStyleKeyframes(t *testing.T) {
+	AssertCompile(t, CompactStyle, `@-webkit-keyframes spin { from { x: 1; } 50%, 75.5% { x: 2; } to { x: 3; } }`,
+		"@-webkit-keyframes spin { from { x: 1; } 50%, 75.5% { x: 2; } to { x: 3; } }\n")
+	AssertCompile(t, CompactStyle, `.a { x: 1; @keyframes fade { to { opacity: 1; } } }`,
+		".a { x: 1; }\n\n@keyframes fade { to { opacity: 1; } }\n")
+}
+
+func TestCompactStyleFontFaceAndPage(t *testing.T) {
+	AssertCompile(t, CompactStyle, `@font-face { font-family: "Open Sans"; src: url(a.woff) format("woff"); } @page :first { margin: 1in; }`,
+		"@font-face { font-family: \"Open Sans\"; src: url(a.woff) format(\"woff\"); }\n\n@page :first { margin: 1in; }\n")
+	AssertCompile(t, CompressedStyle, `@font-face { font-family: x; font-weight: bold; }`,
+		"@font-face{font-family:x;font-weight:bold}\n")
+	AssertCompile(t, CompressedStyle, `@page { margin: 1in; @top-left { content: "a"; } size: A4; }`,
+		"@page{margin:1in;@top-left{content:\"a\"}size:A4}\n")
+}
+
+func TestNestedStyleUnknownAtRules(t *testing.T) {
+	AssertCompile(t, NestedStyle, `@namespace svg url(http://www.w3.org/2000/svg); @custom-selector :--heading h1, h2; @viewport { width: device-width; }`,
+		"@namespace svg url(http://www.w3.org/2000/svg);\n@custom-selector :--heading h1, h2;\n@viewport {\n  width: device-width; }\n")
+	AssertCompile(t, CompactStyle, `.a { @-moz-document url-prefix() { color: red; } }`,
+		"@-moz-document url-prefix() { .a { color: red; } }\n")
+}
@@ -0,0 +1,691 @@
+package compiler
+
+import "c6/ast"
+import "c6/sourcemap"
+import "fmt"
+import "io"
+import "math"
+import "sort"
+import "strconv"
+import "strings"
+import "unicode/utf8"
+
+/*
+BaseCompiler contains the output buffer state and the code generation of the
+values, selectors and at-rule preludes, which are shared by all the output
+styles.
+*/
+type BaseCompiler struct {
+	Writer io.Writer
+
+	// current indent level
+	Indent int
+
+	// render the values without the optional whitespaces
+	Compressed bool
+
+	// there is an unterminated line in the output
+	lineOpen bool
+
+	// add a blank line before the next line
+	separate bool
+
+	// the first write error
+	err error
+
+	// the mappings of the output are added to the source map if it's not nil
+	SourceMap *sourcemap.SourceMap
+
+	// the tokens of the source map markers in the pending output
+	markedTokens []*ast.Token
+
+	// the current position of the output
+	line   int
+	column int
+}
+
+func (self *BaseCompiler) SetSourceMap(sourceMap *sourcemap.SourceMap) {
+	self.SourceMap = sourceMap
+}
+
+/*
+The source map marker is put in the output before the code generated from the
+token, the markers are removed from the output by write, which adds the
+mapping of the position of the marker. The marker is empty when there is no
+source map.
+*/
+const (
+	sourceMapMarkerStart = '\x00'
+	sourceMapMarkerEnd   = '\x01'
+)
+
+func (self *BaseCompiler) mark(token *ast.Token) string {
+	if self.SourceMap == nil || token == nil {
+		return ""
+	}
+	self.markedTokens = append(self.markedTokens, token)
+	return string(sourceMapMarkerStart) + strconv.Itoa(len(self.markedTokens)-1) + string(sourceMapMarkerEnd)
+}
+
+func (self *BaseCompiler) write(str string) {
+	if self.err != nil {
+		return
+	}
+	if self.SourceMap == nil {
+		_, self.err = io.WriteString(self.Writer, str)
+		return
+	}
+
+	for {
+		var start = strings.IndexByte(str, sourceMapMarkerStart)
+		if start == -1 {
+			break
+		}
+		var end = strings.IndexByte(str[start:], sourceMapMarkerEnd)
+		if end == -1 {
+			break
+		}
+		end += start
+		self.writeText(str[:start])
+		if idx, err := strconv.Atoi(str[start+1 : end]); err == nil && idx < len(self.markedTokens) {
+			var token = self.markedTokens[idx]
+			self.SourceMap.AddMapping(sourcemap.Mapping{
+				GeneratedLine:   self.line,
+				GeneratedColumn: self.column,
+				Source:          token.File,
+				SourceLine:      token.Line,
+				SourceColumn:    token.Column,
+			})
+		}
+		str = str[end+1:]
+	}
+	self.writeText(str)
+}
+
+// writeText writes the text and updates the output position.
+func (self *BaseCompiler) writeText(str string) {
+	if self.err != nil || str == "" {
+		return
+	}
+	_, self.err = io.WriteString(self.Writer, str)
+	if idx := strings.LastIndexByte(str, '\n'); idx != -1 {
+		self.line += strings.Count(str, "\n")
+		self.column = utf8.RuneCountInString(str[idx+1:])
+	} else {
+		self.column += utf8.RuneCountInString(str)
+	}
+}
+
+// writeLine terminates the previous line and starts a new indented line.
+func (self *BaseCompiler) writeLine(line string) {
+	if self.lineOpen {
+		self.write("\n")
+		if self.separate {
+			self.write("\n")
+		}
+	}
+	self.separate = false
+	self.write(strings.Repeat("  ", self.Indent) + line)
+	self.lineOpen = true
+}
+
+// finish terminates the last line and returns the first write error.
+func (self *BaseCompiler) finish() error {
+	if self.lineOpen {
+		self.write("\n")
+		self.lineOpen = false
+	}
+	return self.err
+}
+
+/*
+Declarations returns the statements rendered inside the braces of the
+ruleset, they are the properties and the comments.
+*/
+func (self *BaseCompiler) Declarations(ruleset *ast.RuleSet) []ast.Statement {
+	var declarations = []ast.Statement{}
+	for _, stm := range ruleset.Block.Statements {
+		switch stm.(type) {
+		case *ast.Property, *ast.CommentStatement:
+			declarations = append(declarations, stm)
+		}
+	}
+	return declarations
+}
+
+func (self *BaseCompiler) CompileCharsetStatement(stm *ast.CharsetStatement) string {
+	return self.mark(stm.Token) + "@charset \"" + stm.Encoding + "\";"
+}
+
+func (self *BaseCompiler) CompileImportStatement(stm *ast.ImportStatement) string {
+	var out = self.mark(stm.Token) + "@import "
+	switch url := stm.Url.(type) {
+	case ast.Url:
+		out += "url(" + string(url) + ")"
+	case ast.RelativeUrl:
+		out += "\"" + string(url) + "\""
+	}
+	if len(stm.MediaQueryList) > 0 {
+		out += " " + self.CompileMediaQueryList(stm.MediaQueryList)
+	}
+	return out + ";"
+}
+
+/*
+CompileMediaQueryPrelude renders the "@media ..." part before the block.
+*/
+func (self *BaseCompiler) CompileMediaQueryPrelude(stm *ast.MediaQueryStatement) string {
+	var out = self.mark(stm.Token) + "@media"
+	if len(stm.MediaQueryList) > 0 {
+		out += " " + self.CompileMediaQueryList(stm.MediaQueryList)
+	}
+	return out
+}
+
+func (self *BaseCompiler) CompileMediaQueryList(queries []*ast.MediaQuery) string {
+	var items = []string{}
+	for _, query := range queries {
+		items = append(items, self.CompileMediaQuery(query))
+	}
+	return strings.Join(items, self.commaSeparator())
+}
+
+func (self *BaseCompiler) CompileMediaQuery(query *ast.MediaQuery) (out string) {
+	if query.MediaType != nil {
+		out += self.CompileMediaQueryExpression(query.MediaType)
+	}
+	if query.MediaExpression != nil {
+		if query.MediaType != nil {
+			out += " and "
+		}
+		out += self.CompileMediaQueryExpression(query.MediaExpression)
+	}
+	return out
+}
+
+func (self *BaseCompiler) CompileMediaQueryExpression(anyExpr ast.Expression) string {
+	switch expr := anyExpr.(type) {
+	case *ast.UnaryExpression:
+		// "not screen", "only screen"
+		return expr.Op.String() + " " + self.CompileMediaQueryExpression(expr.Expr)
+	case *ast.BinaryExpression:
+		return self.CompileMediaQueryExpression(expr.Left) + " and " + self.CompileMediaQueryExpression(expr.Right)
+	case *ast.MediaFeature:
+		var out = "(" + self.CompileValue(expr.Feature)
+		if expr.Value != nil {
+			out += self.colonSeparator() + self.CompileValue(expr.Value)
+		}
+		return out + ")"
+	}
+	return self.CompileValue(anyExpr)
+}
+
+/*
+CompileAtRulePrelude returns the part of the at-rule before the block, e.g.
+"@supports (display: flex)", and the statements inside the block. The
+statements are nil for the at-rules ending with a semicolon.
+*/
+func (self *BaseCompiler) CompileAtRulePrelude(anyStm ast.Statement) (string, []ast.Statement) {
+	switch stm := anyStm.(type) {
+	case *ast.MediaQueryStatement:
+		return self.CompileMediaQueryPrelude(stm), blockStatements(stm.Block)
+
+	case *ast.SupportsStatement:
+		return self.mark(stm.Token) + "@supports " + self.CompileSupportsCondition(stm.Condition), blockStatements(stm.Block)
+
+	case *ast.KeyframesStatement:
+		return self.mark(stm.Token) + stm.Keyword + " " + self.CompileValue(stm.Name), blockStatements(stm.Block)
+
+	case *ast.FontFaceStatement:
+		return self.mark(stm.Token) + "@font-face", declarationBlockStatements(stm.Block)
+
+	case *ast.PageStatement:
+		var prelude = self.mark(stm.Token) + "@page"
+		if stm.Selector != nil {
+			prelude += " " + self.CompileValue(stm.Selector)
+		}
+		return prelude, declarationBlockStatements(stm.Block)
+
+	case *ast.AtRuleStatement:
+		var prelude = self.mark(stm.Token) + stm.Name
+		if stm.Prelude != nil {
+			prelude += " " + self.CompileValue(stm.Prelude)
+		}
+		if stm.Block == nil {
+			return prelude, nil
+		}
+		return prelude, blockStatements(stm.Block)
+	}
+	return "", nil
+}
+
+/*
+atRuleStatements returns the statements inside the block of the at-rule, nil
+is returned for the at-rules ending with a semicolon.
+*/
+func atRuleStatements(anyStm ast.Statement) []ast.Statement {
+	switch stm := anyStm.(type) {
+	case *ast.MediaQueryStatement:
+		return blockStatements(stm.Block)
+	case *ast.SupportsStatement:
+		return blockStatements(stm.Block)
+	case *ast.KeyframesStatement:
+		return blockStatements(stm.Block)
+	case *ast.FontFaceStatement:
+		return declarationBlockStatements(stm.Block)
+	case *ast.PageStatement:
+		return declarationBlockStatements(stm.Block)
+	case *ast.AtRuleStatement:
+		if stm.Block != nil {
+			return blockStatements(stm.Block)
+		}
+	}
+	return nil
+}
+
+/*
+HasOutput returns true if the statement generates any CSS. The rulesets
+without declarations or with only the placeholder selectors generate
+nothing, so do the at-rule blocks containing only such rulesets.
+*/
+func (self *BaseCompiler) HasOutput(anyStm ast.Statement, parentSelectors ast.SelectorGroup) bool {
+	switch stm := anyStm.(type) {
+	case *ast.RuleSet:
+		if stm.Block == nil {
+			return false
+		}
+		var selectors = self.CompileRuleSetSelectors(parentSelectors, stm)
+		if len(selectors) > 0 && len(self.Declarations(stm)) > 0 {
+			return true
+		}
+		for _, subRuleSet := range stm.Block.SubRuleSets {
+			if self.HasOutput(subRuleSet, selectors) {
+				return true
+			}
+		}
+		return false
+
+	case *ast.MediaQueryStatement, *ast.SupportsStatement, *ast.KeyframesStatement,
+		*ast.FontFaceStatement, *ast.PageStatement, *ast.AtRuleStatement:
+		var stmts = atRuleStatements(stm)
+		if stmts == nil {
+			return true
+		}
+		for _, subStm := range stmts {
+			if self.HasOutput(subStm, parentSelectors) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+/*
+blockStyleCompiler is implemented by the compilers writing the blocks on
+multiple lines, i.e. the nested and the expanded styles. They share the walk
+of the statements and differ in how the rulesets and the at-rules are closed.
+*/
+type blockStyleCompiler interface {
+	CompileRuleSet(ruleset *ast.RuleSet, parentSelectors ast.SelectorGroup)
+	CompileAtRule(stm ast.Statement, parentSelectors ast.SelectorGroup)
+}
+
+func (self *BaseCompiler) compileStatements(compiler blockStyleCompiler, stmts []ast.Statement) error {
+	for _, stm := range BubbleAtRules(stmts) {
+		self.compileStatement(compiler, stm, nil)
+	}
+	return self.finish()
+}
+
+/*
+compileStatement writes the statement by the compiler of the style. The
+parent selectors are used for the statements inside a ruleset, they're nil
+for the top level statements.
+*/
+func (self *BaseCompiler) compileStatement(compiler blockStyleCompiler, anyStm ast.Statement, parentSelectors ast.SelectorGroup) {
+	switch stm := anyStm.(type) {
+
+	case *ast.CharsetStatement:
+		self.writeLine(self.CompileCharsetStatement(stm))
+
+	case *ast.ImportStatement:
+		self.writeLine(self.CompileImportStatement(stm))
+
+	case *ast.CommentStatement:
+		self.writeLine(stm.String())
+
+	case *ast.RuleSet:
+		compiler.CompileRuleSet(stm, parentSelectors)
+		// top level rulesets are separated by a blank line
+		if self.Indent == 0 {
+			self.separate = true
+		}
+
+	case *ast.NamespaceStatement:
+		self.writeLine(self.CompileNamespaceStatement(stm))
+
+	case *ast.MediaQueryStatement, *ast.SupportsStatement, *ast.KeyframesStatement,
+		*ast.FontFaceStatement, *ast.PageStatement, *ast.AtRuleStatement:
+		if self.HasOutput(stm, parentSelectors) {
+			compiler.CompileAtRule(stm, parentSelectors)
+		}
+
+	case *ast.Property:
+		for _, property := range self.CompileProperties(stm) {
+			self.writeLine(property + ";")
+		}
+	}
+}
+
+func blockStatements(block *ast.Block) []ast.Statement {
+	var stmts = []ast.Statement{}
+	if block == nil {
+		return stmts
+	}
+	return append(stmts, block.Statements...)
+}
+
+func declarationBlockStatements(block *ast.DeclarationBlock) []ast.Statement {
+	var stmts = []ast.Statement{}
+	if block == nil {
+		return stmts
+	}
+	stmts = append(stmts, block.Statements...)
+	for _, ruleset := range block.SubRuleSets {
+		stmts = append(stmts, ruleset)
+	}
+	return stmts
+}
+
+/*
+CompileSupportsCondition renders the condition of @supports, the nested
+conditions are wrapped in the parentheses:
+
+	(display: flex) and (not (display: grid))
+*/
+func (self *BaseCompiler) CompileSupportsCondition(anyExpr ast.Expression) string {
+	switch expr := anyExpr.(type) {
+	case *ast.SupportsDeclaration:
+		return "(" + self.CompileValue(expr.Property) + self.colonSeparator() + self.CompileValue(expr.Value) + ")"
+	case *ast.UnaryExpression:
+		return "not " + self.compileSupportsOperand(expr.Expr, nil)
+	case *ast.BinaryExpression:
+		return self.compileSupportsOperand(expr.Left, expr.Op) + " " + expr.Op.String() + " " + self.compileSupportsOperand(expr.Right, expr.Op)
+	}
+	return self.CompileValue(anyExpr)
+}
+
+/*
+compileSupportsOperand renders the operand of "not", "and" or "or", the
+conditions joined by the same operator don't need the parentheses.
+*/
+func (self *BaseCompiler) compileSupportsOperand(anyExpr ast.Expression, op *ast.Op) string {
+	switch expr := anyExpr.(type) {
+	case *ast.UnaryExpression:
+		return "(" + self.CompileSupportsCondition(expr) + ")"
+	case *ast.BinaryExpression:
+		if op == nil || expr.Op.Type != op.Type {
+			return "(" + self.CompileSupportsCondition(expr) + ")"
+		}
+	}
+	return self.CompileSupportsCondition(anyExpr)
+}
+
+func (self *BaseCompiler) CompileNamespaceStatement(stm *ast.NamespaceStatement) string {
+	return self.mark(stm.Token) + "@namespace " + self.CompileValue(stm.Prelude) + ";"
+}
+
+/*
+CompileRuleSetSelectors returns the selectors of the ruleset joined with the
+parent selectors, followed by the selectors added by @extend which are
+resolved already. The result is the cross product of the parent selectors and
+the selectors of the ruleset, the parent selector "&" is replaced by the
+parent selector. The selectors
+containing a placeholder are not generated. The parent selectors are nil for
+the top level rulesets.
+*/
+func (self *BaseCompiler) CompileRuleSetSelectors(parentSelectors ast.SelectorGroup, ruleset *ast.RuleSet) ast.SelectorGroup {
+	var selectors = ruleset.ResolvedSelectors(parentSelectors)
+
+	var out = ast.SelectorGroup{}
+	for _, selector := range selectors {
+		if !selector.HasPlaceholder() {
+			out = append(out, selector)
+		}
+	}
+	return out
+}
+
+func (self *BaseCompiler) CompileSelectors(selectors ast.SelectorList) string {
+	var out = ""
+	for _, sel := range selectors {
+		switch sel.(type) {
+		case *ast.ChildCombinator, *ast.AdjacentCombinator, *ast.GeneralSiblingCombinator:
+			if self.Compressed {
+				out += strings.TrimSpace(sel.String())
+				continue
+			}
+		}
+		out += sel.String()
+	}
+	return out
+}
+
+// JoinSelectors joins the selectors of a ruleset by commas.
+func (self *BaseCompiler) JoinSelectors(selectors ast.SelectorGroup) string {
+	var items = []string{}
+	for _, selector := range selectors {
+		items = append(items, self.CompileSelectors(selector))
+	}
+	return strings.Join(items, self.commaSeparator())
+}
+
+/*
+CompileProperties renders the property and its nested properties without the
+trailing semicolons, the names of the nested properties are joined by hyphens,
+e.g. "font-family". The property without values only renders the nested
+properties.
+*/
+func (self *BaseCompiler) CompileProperties(property *ast.Property) []string {
+	return self.compileProperties(property, "")
+}
+
+func (self *BaseCompiler) compileProperties(property *ast.Property, prefix string) []string {
+	var name = prefix + property.Name.String()
+	var out = []string{}
+	if len(property.Values) > 0 {
+		out = append(out, self.compileProperty(property, name))
+	}
+	for _, subProperty := range property.Properties {
+		out = append(out, self.compileProperties(subProperty, name+"-")...)
+	}
+	return out
+}
+
+/*
+CompileProperty renders the property without the trailing semicolon.
+*/
+func (self *BaseCompiler) CompileProperty(property *ast.Property) string {
+	return self.compileProperty(property, property.Name.String())
+}
+
+func (self *BaseCompiler) compileProperty(property *ast.Property, name string) string {
+	var values = []string{}
+	for _, value := range property.Values {
+		values = append(values, self.CompileValue(value))
+	}
+	var out = self.mark(property.Name.Token) + name + self.colonSeparator() + strings.Join(values, " ")
+	if property.Important {
+		if self.Compressed {
+			out += "!important"
+		} else {
+			out += " !important"
+		}
+	}
+	return out
+}
+
+/*
+CompileValue renders the value of a property as CSS.
+*/
+func (self *BaseCompiler) CompileValue(anyExpr ast.Expression) string {
+	switch expr := anyExpr.(type) {
+
+	case *ast.List:
+		var items = []string{}
+		for _, subexpr := range expr.Expressions {
+			items = append(items, self.CompileValue(subexpr))
+		}
+		var separator = expr.Separator
+		if self.Compressed && strings.TrimSpace(separator) != "" {
+			separator = strings.TrimSpace(separator)
+		}
+		return strings.Join(items, separator)
+
+	case *ast.String:
+		if expr.Quote != 0 {
+			return string(expr.Quote) + expr.Value + string(expr.Quote)
+		}
+		return expr.Value
+
+	case *ast.Number:
+		var out = FormatNumber(expr.Value)
+		if self.Compressed {
+			out = StripLeadingZero(out)
+		}
+		if expr.Unit != nil {
+			out += expr.Unit.String()
+		}
+		return out
+
+	case *ast.HexColor:
+		if self.Compressed {
+			return ShortestColor(expr.R, expr.G, expr.B)
+		}
+		return expr.String()
+
+	case *ast.RGBColor:
+		if self.Compressed {
+			return ShortestColor(expr.R, expr.G, expr.B)
+		}
+		return expr.String()
+
+	case *ast.RGBAColor:
+		if self.Compressed {
+			if expr.A == 1 {
+				return ShortestColor(expr.R, expr.G, expr.B)
+			}
+			return fmt.Sprintf("rgba(%d,%d,%d,%s)", expr.R, expr.G, expr.B,
+				StripLeadingZero(FormatNumber(float64(expr.A))))
+		}
+		return expr.String()
+
+	case *ast.FunctionCall:
+		var args = []string{}
+		for _, arg := range expr.Arguments {
+			if arg.Name != "" {
+				args = append(args, arg.Name+self.colonSeparator()+self.CompileValue(arg.Value))
+			} else {
+				args = append(args, self.CompileValue(arg.Value))
+			}
+		}
+		return expr.Function + "(" + strings.Join(args, self.commaSeparator()) + ")"
+
+	case *ast.BinaryExpression:
+		// the expression which can't be computed, e.g. "100% - 10px" inside
+		// calc(), the spaces around "+" and "-" are required by CSS.
+		if expr.Op.Type == ast.T_PLUS || expr.Op.Type == ast.T_MINUS {
+			return self.CompileValue(expr.Left) + " " + expr.Op.String() + " " + self.CompileValue(expr.Right)
+		}
+		return self.CompileValue(expr.Left) + expr.Op.String() + self.CompileValue(expr.Right)
+
+	case *ast.Interpolation:
+		// the interpolation output is always unquoted
+		if str, ok := expr.Expression.(*ast.String); ok {
+			return str.Value
+		}
+		return self.CompileValue(expr.Expression)
+
+	case *ast.LiteralConcat:
+		return self.CompileValue(expr.Left) + self.CompileValue(expr.Right)
+	}
+	return anyExpr.String()
+}
+
+func (self *BaseCompiler) commaSeparator() string {
+	if self.Compressed {
+		return ","
+	}
+	return ", "
+}
+
+func (self *BaseCompiler) colonSeparator() string {
+	if self.Compressed {
+		return ":"
+	}
+	return ": "
+}
+
+/*
+FormatNumber rounds the number by the NumberPrecision and strips the trailing
+zeros.
+*/
+func FormatNumber(val float64) string {
+	var scale = math.Pow(10, NumberPrecision)
+	val = math.Floor(val*scale+0.5) / scale
+	if val == 0 {
+		// avoid "-0"
+		val = 0
+	}
+	return strconv.FormatFloat(val, 'f', -1, 64)
+}
+
+/*
+StripLeadingZero converts "0.5" to ".5" and "-0.5" to "-.5".
+*/
+func StripLeadingZero(number string) string {
+	if strings.HasPrefix(number, "0.") {
+		return number[1:]
+	}
+	if strings.HasPrefix(number, "-0.") {
+		return "-" + number[2:]
+	}
+	return number
+}
+
+// the shortest keyword of the hex code, e.g. "#ff0000" => "red"
+var shortestColorKeywords map[string]string
+
+func init() {
+	var names = []string{}
+	for name := range ast.ColorKeywords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	shortestColorKeywords = map[string]string{}
+	for _, name := range names {
+		var hex = strings.ToLower(ast.ColorKeywords[name])
+		if keyword, ok := shortestColorKeywords[hex]; ok && len(keyword) <= len(name) {
+			continue
+		}
+		shortestColorKeywords[hex] = name
+	}
+}
+
+/*
+ShortestColor returns the shortest representation of the color, either the
+three digit hex code, the six digit hex code or the color keyword.
+*/
+func ShortestColor(r, g, b uint32) string {
+	var hex = fmt.Sprintf("#%02x%02x%02x", r&0xFF, g&0xFF, b&0xFF)
+	var out = hex
+	if hex[1] == hex[2] && hex[3] == hex[4] && hex[5] == hex[6] {
+		out = "#" + hex[1:2] + hex[3:4] + hex[5:6]
+	}
+	if keyword, ok := shortestColorKeywords[hex]; ok && len(keyword) < len(out) {
+		return keyword
+	}
+	return out
+}
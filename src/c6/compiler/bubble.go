@@ -0,0 +1,251 @@
+package compiler
+
+import "c6/ast"
+
+/*
+BubbleAtRules moves the at-rules nested in the rulesets out of the rulesets.
+The declarations of a bubbled @media, @supports or unknown at-rule are
+wrapped by the selectors of the enclosing rulesets, and the queries of the
+nested @media statements are merged with the enclosing queries, e.g.
+
+	.foo { @media screen { @media (min-width: 600px) { color: red } } }
+
+becomes
+
+	@media screen and (min-width: 600px) { .foo { color: red } }
+
+The @media statements are always moved to the top level or the block of the
+enclosing at-rule, those with the queries that can't be merged are dropped.
+@keyframes, @font-face and @page are moved out of the rulesets as they are.
+The plain CSS imports are moved to the start of the output, see HoistImports.
+The given statements are not modified.
+*/
+func BubbleAtRules(stmts []ast.Statement) []ast.Statement {
+	return HoistImports(bubbleStatements(stmts, nil))
+}
+
+/*
+HoistImports moves the plain CSS imports of the top level before the other
+statements except @charset, CSS requires @import to precede all the other
+rules:
+
+	a { color: red } @import "foo.css";
+
+becomes
+
+	@import "foo.css"; a { color: red }
+*/
+func HoistImports(stmts []ast.Statement) []ast.Statement {
+	var charsets = []ast.Statement{}
+	var imports = []ast.Statement{}
+	var others = []ast.Statement{}
+	for _, anyStm := range stmts {
+		switch anyStm.(type) {
+		case *ast.CharsetStatement:
+			charsets = append(charsets, anyStm)
+		case *ast.ImportStatement:
+			imports = append(imports, anyStm)
+		default:
+			others = append(others, anyStm)
+		}
+	}
+	return append(append(charsets, imports...), others...)
+}
+
+/*
+bubbleStatements bubbles the at-rules of the statements at the top level or
+inside the block of an at-rule. The queries are the queries of the enclosing
+@media statement, nil if there is none.
+*/
+func bubbleStatements(stmts []ast.Statement, queries []*ast.MediaQuery) []ast.Statement {
+	var out = []ast.Statement{}
+	for _, anyStm := range stmts {
+		switch stm := anyStm.(type) {
+		case *ast.RuleSet:
+			out = append(out, bubbleRuleSet(stm, nil, queries)...)
+		case *ast.MediaQueryStatement:
+			out = append(out, bubbleMediaQuery(stm, nil, queries)...)
+		case *ast.SupportsStatement, *ast.AtRuleStatement:
+			out = append(out, bubbleAtRule(stm, nil, queries))
+		default:
+			out = append(out, anyStm)
+		}
+	}
+	return out
+}
+
+/*
+bubbleRuleSet returns the copies of the ruleset without the nested at-rules,
+and the at-rules bubbled out of it in the source order. The declarations and
+the nested rulesets after a bubbled at-rule go to a new copy of the ruleset
+after the at-rule:
+
+	a { @media x { b: 1 } c { d: 2 } }
+
+becomes
+
+	a {} @media x { a { b: 1 } } a { c { d: 2 } }
+
+The parents are the resolved selectors of the enclosing ruleset, nil for the
+top level ruleset.
+*/
+func bubbleRuleSet(ruleset *ast.RuleSet, parents ast.SelectorGroup, queries []*ast.MediaQuery) []ast.Statement {
+	if ruleset.Block == nil {
+		return []ast.Statement{ruleset}
+	}
+
+	var selectors = ruleset.ResolvedSelectors(parents)
+
+	var current = copyRuleSet(ruleset)
+	var out = []ast.Statement{current}
+	var bubble = func(stmts ...ast.Statement) {
+		if len(stmts) > 0 {
+			out = append(out, stmts...)
+			current = nil
+		}
+	}
+	var keep = func(stm ast.Statement) {
+		if current == nil {
+			current = copyRuleSet(ruleset)
+			out = append(out, current)
+		}
+		if subRuleSet, ok := stm.(*ast.RuleSet); ok {
+			current.Block.AppendSubRuleSet(subRuleSet)
+		} else {
+			current.Block.Append(stm)
+		}
+	}
+
+	for _, anyStm := range ruleset.Block.OrderedStatements() {
+		switch stm := anyStm.(type) {
+		case *ast.RuleSet:
+			// the copies of the nested ruleset stay nested, the at-rules
+			// bubbled out of it are bubbled out of this ruleset too
+			for _, subStm := range bubbleRuleSet(stm, selectors, queries) {
+				if _, ok := subStm.(*ast.RuleSet); ok {
+					keep(subStm)
+				} else {
+					bubble(subStm)
+				}
+			}
+		case *ast.MediaQueryStatement:
+			bubble(bubbleMediaQuery(stm, selectors, queries)...)
+		case *ast.SupportsStatement:
+			bubble(bubbleAtRule(stm, selectors, queries))
+		case *ast.AtRuleStatement:
+			if stm.Block == nil {
+				keep(stm)
+			} else {
+				bubble(bubbleAtRule(stm, selectors, queries))
+			}
+		case *ast.KeyframesStatement, *ast.FontFaceStatement, *ast.PageStatement:
+			bubble(stm)
+		default:
+			keep(anyStm)
+		}
+	}
+	return out
+}
+
+/*
+copyRuleSet returns an empty ruleset with the selectors of the ruleset.
+*/
+func copyRuleSet(ruleset *ast.RuleSet) *ast.RuleSet {
+	var out = ast.NewRuleSet()
+	out.Selectors = ruleset.Selectors
+	out.ExtendedSelectors = ruleset.ExtendedSelectors
+	out.SelectorTemplate = ruleset.SelectorTemplate
+	out.Token = ruleset.Token
+	out.Block = ast.NewDeclarationBlock()
+	out.Block.SymTable = ruleset.Block.SymTable
+	return out
+}
+
+/*
+bubbleMediaQuery returns the @media statement with the queries merged with
+the enclosing queries, followed by the @media statements nested in it.
+*/
+func bubbleMediaQuery(stm *ast.MediaQueryStatement, parents ast.SelectorGroup, queries []*ast.MediaQuery) []ast.Statement {
+	var mediaQueryList = stm.MediaQueryList
+	if queries != nil {
+		mediaQueryList = MergeMediaQueryLists(queries, stm.MediaQueryList)
+		if len(mediaQueryList) == 0 {
+			return nil
+		}
+	}
+
+	var media = ast.NewMediaQueryStatement()
+	media.MediaQueryList = mediaQueryList
+	media.Token = stm.Token
+	media.Block = ast.NewBlock()
+	if stm.Block == nil {
+		return []ast.Statement{media}
+	}
+	media.Block.SymTable = stm.Block.SymTable
+
+	var out = []ast.Statement{media}
+	for _, subStm := range bubbleStatements(wrapStatements(stm.Block.Statements, parents, stm.Token), mediaQueryList) {
+		if _, ok := subStm.(*ast.MediaQueryStatement); ok {
+			out = append(out, subStm)
+		} else {
+			media.Block.AppendStatement(subStm)
+		}
+	}
+	return out
+}
+
+/*
+bubbleAtRule returns a copy of the @supports statement or the unknown at-rule,
+the statements inside the block are wrapped by the parent selectors.
+*/
+func bubbleAtRule(anyStm ast.Statement, parents ast.SelectorGroup, queries []*ast.MediaQuery) ast.Statement {
+	switch stm := anyStm.(type) {
+	case *ast.SupportsStatement:
+		var supports = ast.NewSupportsStatementWithToken(stm.Token)
+		supports.Condition = stm.Condition
+		supports.Block = bubbleBlock(stm.Block, parents, queries, stm.Token)
+		return supports
+	case *ast.AtRuleStatement:
+		if stm.Block == nil {
+			return stm
+		}
+		var atRule = ast.NewAtRuleStatementWithToken(stm.Token)
+		atRule.Name = stm.Name
+		atRule.Prelude = stm.Prelude
+		atRule.Block = bubbleBlock(stm.Block, parents, queries, stm.Token)
+		return atRule
+	}
+	return anyStm
+}
+
+func bubbleBlock(block *ast.Block, parents ast.SelectorGroup, queries []*ast.MediaQuery, token *ast.Token) *ast.Block {
+	var out = ast.NewBlock()
+	if block != nil {
+		out.SymTable = block.SymTable
+		out.Statements = bubbleStatements(wrapStatements(block.Statements, parents, token), queries)
+	}
+	return out
+}
+
+/*
+wrapStatements wraps the statements of the at-rule nested in a ruleset by a
+ruleset with the parent selectors, the nested rulesets become the sub
+rulesets of it.
+*/
+func wrapStatements(stmts []ast.Statement, parents ast.SelectorGroup, token *ast.Token) []ast.Statement {
+	if parents == nil {
+		return stmts
+	}
+	var wrapper = ast.NewRuleSet()
+	wrapper.Selectors = parents
+	wrapper.Token = token
+	wrapper.Block = ast.NewDeclarationBlock()
+	for _, anyStm := range stmts {
+		if ruleset, ok := anyStm.(*ast.RuleSet); ok {
+			wrapper.Block.AppendSubRuleSet(ruleset)
+		} else {
+			wrapper.Block.Append(anyStm)
+		}
+	}
+	return []ast.Statement{wrapper}
+}
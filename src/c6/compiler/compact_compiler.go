@@ -0,0 +1,121 @@
+package compiler
+
+import "c6/ast"
+import "io"
+import "strings"
+
+/*
+CompactStyleCompiler generates the SASS "compact" output style, every ruleset
+takes only one line:
+
+	.foo { color: red; background: #fff; }
+	.foo .bar { color: blue; }
+*/
+type CompactStyleCompiler struct {
+	BaseCompiler
+}
+
+func NewCompactStyleCompiler(writer io.Writer) *CompactStyleCompiler {
+	return &CompactStyleCompiler{BaseCompiler{Writer: writer}}
+}
+
+func (self *CompactStyleCompiler) CompileStatements(stmts []ast.Statement) error {
+	for _, stm := range BubbleAtRules(stmts) {
+		var lines = self.CompileStatement(stm, nil)
+		for _, line := range lines {
+			self.writeLine(line)
+		}
+		// top level rulesets and at-rule blocks are separated by a blank line
+		if len(lines) > 0 {
+			switch stm.(type) {
+			case *ast.RuleSet, *ast.MediaQueryStatement, *ast.SupportsStatement, *ast.KeyframesStatement,
+				*ast.FontFaceStatement, *ast.PageStatement, *ast.AtRuleStatement:
+				self.separate = true
+			}
+		}
+	}
+	return self.finish()
+}
+
+func (self *CompactStyleCompiler) CompileBlock(block *ast.Block) error {
+	return self.CompileStatements(block.Statements)
+}
+
+/*
+CompileStatement returns the output lines of the statement.
+*/
+func (self *CompactStyleCompiler) CompileStatement(anyStm ast.Statement, parentSelectors ast.SelectorGroup) []string {
+	switch stm := anyStm.(type) {
+
+	case *ast.CharsetStatement:
+		return []string{self.CompileCharsetStatement(stm)}
+
+	case *ast.ImportStatement:
+		return []string{self.CompileImportStatement(stm)}
+
+	case *ast.CommentStatement:
+		return []string{stm.String()}
+
+	case *ast.RuleSet:
+		return self.CompileRuleSet(stm, parentSelectors)
+
+	case *ast.NamespaceStatement:
+		return []string{self.CompileNamespaceStatement(stm)}
+
+	case *ast.MediaQueryStatement, *ast.SupportsStatement, *ast.KeyframesStatement,
+		*ast.FontFaceStatement, *ast.PageStatement, *ast.AtRuleStatement:
+		return self.CompileAtRule(stm, parentSelectors)
+
+	case *ast.Property:
+		var items = []string{}
+		for _, property := range self.CompileProperties(stm) {
+			items = append(items, property+";")
+		}
+		return items
+	}
+	return nil
+}
+
+/*
+CompileAtRule returns the at-rule and its block in one line, e.g. @media or
+@supports.
+*/
+func (self *CompactStyleCompiler) CompileAtRule(stm ast.Statement, parentSelectors ast.SelectorGroup) []string {
+	if !self.HasOutput(stm, parentSelectors) {
+		return nil
+	}
+	var prelude, stmts = self.CompileAtRulePrelude(stm)
+	if stmts == nil {
+		return []string{prelude + ";"}
+	}
+	var items = []string{}
+	for _, subStm := range stmts {
+		items = append(items, self.CompileStatement(subStm, parentSelectors)...)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return []string{prelude + " { " + strings.Join(items, " ") + " }"}
+}
+
+func (self *CompactStyleCompiler) CompileRuleSet(ruleset *ast.RuleSet, parentSelectors ast.SelectorGroup) []string {
+	var selectors = self.CompileRuleSetSelectors(parentSelectors, ruleset)
+	if ruleset.Block == nil {
+		return nil
+	}
+
+	var lines = []string{}
+	var declarations = self.Declarations(ruleset)
+	if len(declarations) > 0 && len(selectors) > 0 {
+		var items = []string{}
+		for _, stm := range declarations {
+			items = append(items, self.CompileStatement(stm, selectors)...)
+		}
+		lines = append(lines, self.mark(ruleset.Token)+self.JoinSelectors(selectors)+" { "+strings.Join(items, " ")+" }")
+	}
+
+	for _, subRuleSet := range ruleset.Block.SubRuleSets {
+		lines = append(lines, self.CompileRuleSet(subRuleSet, selectors)...)
+	}
+	return lines
+}
@@ -0,0 +1,43 @@
+package compiler
+
+import "testing"
+
+func TestCompactStyleRuleSet(t *testing.T) {
+	AssertCompile(t, CompactStyle, `.foo { color: red; background: #fff; }`,
+		".foo { color: red; background: #fff; }\n")
+}
+
+func TestCompactStyleSubRuleSet(t *testing.T) {
+	AssertCompile(t, CompactStyle, `.foo { color: red; .bar { color: blue; } } p { margin: 0 }`,
+		".foo { color: red; }\n.foo .bar { color: blue; }\n\np { margin: 0; }\n")
+}
+
+func TestCompactStyleMediaQuery(t *testing.T) {
+	AssertCompile(t, CompactStyle, `@media screen and (min-width: 500px) { .foo { color: blue } .bar { color: red } }`,
+		"@media screen and (min-width: 500px) { .foo { color: blue; } .bar { color: red; } }\n")
+}
+
+func TestCompactStyleCharsetAndImport(t *testing.T) {
+	AssertCompile(t, CompactStyle, `@charset "UTF-8"; @import "bar.css"; div { x: y }`,
+		"@charset \"UTF-8\";\n@import \"bar.css\";\ndiv { x: y; }\n")
+}
+
+func TestCompactStyleHoistImports(t *testing.T) {
+	AssertCompile(t, CompactStyle, `div { x: y } @import url(foo.css); @charset "UTF-8"; @import "http://foo.com/bar";`,
+		"@charset \"UTF-8\";\n@import url(foo.css);\n@import \"http://foo.com/bar\";\ndiv { x: y; }\n")
+}
+
+func TestCompactStyleNestedProperties(t *testing.T) {
+	AssertCompile(t, CompactStyle, `.foo { font: { family: serif; weight: bold; } margin: 0 { left: 4px; } }`,
+		".foo { font-family: serif; font-weight: bold; margin: 0; margin-left: 4px; }\n")
+}
+
+func TestCompactStyleParentSelector(t *testing.T) {
+	AssertCompile(t, CompactStyle, `.a { &:hover { x: 1; } .b & { x: 2; } &-title { x: 3; } > .c { x: 4; } ~ .d { x: 5; } }`,
+		".a:hover { x: 1; }\n.b .a { x: 2; }\n.a-title { x: 3; }\n.a > .c { x: 4; }\n.a ~ .d { x: 5; }\n")
+}
+
+func TestCompactStyleSelectorGroup(t *testing.T) {
+	AssertCompile(t, CompactStyle, `h1, h2 { x: 1; .a, & > .b { y: 2; } }`,
+		"h1, h2 { x: 1; }\nh1 .a, h1 > .b, h2 .a, h2 > .b { y: 2; }\n")
+}
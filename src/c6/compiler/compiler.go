@@ -0,0 +1,75 @@
+package compiler
+
+import "c6/ast"
+import "c6/sourcemap"
+import "fmt"
+import "io"
+
+type Compiler interface {
+	CompileStatements(stmts []ast.Statement) error
+	CompileBlock(block *ast.Block) error
+
+	// the mappings of the output are added to the source map
+	SetSourceMap(sourceMap *sourcemap.SourceMap)
+}
+
+/*
+The number precision of the output, the same as the default precision of
+SASS.
+*/
+const NumberPrecision = 5
+
+/*
+OutputStyle selects the format of the generated CSS, the styles are the same
+as the SASS output styles.
+*/
+type OutputStyle int
+
+const (
+	NestedStyle OutputStyle = iota
+	ExpandedStyle
+	CompactStyle
+	CompressedStyle
+)
+
+var OutputStyleNames = map[OutputStyle]string{
+	NestedStyle:     "nested",
+	ExpandedStyle:   "expanded",
+	CompactStyle:    "compact",
+	CompressedStyle: "compressed",
+}
+
+func (style OutputStyle) String() string {
+	if name, ok := OutputStyleNames[style]; ok {
+		return name
+	}
+	return fmt.Sprintf("OutputStyle(%d)", int(style))
+}
+
+/*
+ParseOutputStyle converts the style name, e.g. "compressed", to the
+OutputStyle.
+*/
+func ParseOutputStyle(name string) (OutputStyle, error) {
+	for style, styleName := range OutputStyleNames {
+		if styleName == name {
+			return style, nil
+		}
+	}
+	return NestedStyle, fmt.Errorf("Unknown output style: %s", name)
+}
+
+/*
+NewCompiler creates the compiler of the output style.
+*/
+func NewCompiler(writer io.Writer, style OutputStyle) Compiler {
+	switch style {
+	case ExpandedStyle:
+		return NewExpandedStyleCompiler(writer)
+	case CompactStyle:
+		return NewCompactStyleCompiler(writer)
+	case CompressedStyle:
+		return NewCompressedStyleCompiler(writer)
+	}
+	return NewNestedStyleCompiler(writer)
+}
@@ -0,0 +1,95 @@
+package compiler
+
+import "c6"
+import "c6/sourcemap"
+import "bytes"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func AssertCompile(t *testing.T, style OutputStyle, scss string, css string) {
+	var parser = c6.NewParser(c6.NewContext())
+	var stmts = parser.ParseScss(scss)
+	var buf bytes.Buffer
+	var compiler = NewCompiler(&buf, style)
+	assert.Nil(t, compiler.CompileStatements(stmts))
+	assert.Equal(t, css, buf.String())
+}
+
+func TestParseOutputStyle(t *testing.T) {
+	for _, name := range []string{"nested", "expanded", "compact", "compressed"} {
+		style, err := ParseOutputStyle(name)
+		assert.Nil(t, err)
+		assert.Equal(t, name, style.String())
+	}
+	_, err := ParseOutputStyle("minified")
+	assert.NotNil(t, err)
+}
+
+func TestNewCompiler(t *testing.T) {
+	var buf bytes.Buffer
+	assert.IsType(t, &NestedStyleCompiler{}, NewCompiler(&buf, NestedStyle))
+	assert.IsType(t, &ExpandedStyleCompiler{}, NewCompiler(&buf, ExpandedStyle))
+	assert.IsType(t, &CompactStyleCompiler{}, NewCompiler(&buf, CompactStyle))
+	assert.IsType(t, &CompressedStyleCompiler{}, NewCompiler(&buf, CompressedStyle))
+}
+
+func newMapping(line, column, sourceLine, sourceColumn int) sourcemap.Mapping {
+	return sourcemap.Mapping{
+		GeneratedLine:   line,
+		GeneratedColumn: column,
+		Source:          "main.scss",
+		SourceLine:      sourceLine,
+		SourceColumn:    sourceColumn,
+	}
+}
+
+func TestCompileWithSourceMap(t *testing.T) {
+	var parser = c6.NewParser(c6.NewContext())
+	parser.File = "main.scss"
+	var stmts = parser.ParseScss("@charset \"UTF-8\";\n.foo {\n  color: red;\n  .bar { width: 10px; }\n}\n")
+
+	var buf bytes.Buffer
+	var sourceMap = sourcemap.NewSourceMap("main.css")
+	var compiler = NewCompiler(&buf, NestedStyle)
+	compiler.SetSourceMap(sourceMap)
+	assert.Nil(t, compiler.CompileStatements(stmts))
+
+	// the markers are removed from the output
+	assert.Equal(t, "@charset \"UTF-8\";\n.foo {\n  color: red; }\n  .foo .bar {\n    width: 10px; }\n", buf.String())
+	assert.Equal(t, []sourcemap.Mapping{
+		newMapping(0, 0, 0, 0),
+		newMapping(1, 0, 1, 0),
+		newMapping(2, 2, 2, 2),
+		newMapping(3, 2, 3, 2),
+		newMapping(4, 4, 3, 9),
+	}, sourceMap.Mappings)
+}
+
+func TestCompressedWithSourceMap(t *testing.T) {
+	var parser = c6.NewParser(c6.NewContext())
+	parser.File = "main.scss"
+	var stmts = parser.ParseScss(".foo { color: red; }\n@media print {\n  .bar { width: 10px; }\n}")
+
+	var buf bytes.Buffer
+	var sourceMap = sourcemap.NewSourceMap("main.css")
+	var compiler = NewCompiler(&buf, CompressedStyle)
+	compiler.SetSourceMap(sourceMap)
+	assert.Nil(t, compiler.CompileStatements(stmts))
+
+	assert.Equal(t, ".foo{color:red}@media print{.bar{width:10px}}\n", buf.String())
+	assert.Equal(t, []sourcemap.Mapping{
+		newMapping(0, 0, 0, 0),
+		newMapping(0, 5, 0, 7),
+		newMapping(0, 15, 1, 0),
+		newMapping(0, 28, 2, 2),
+		newMapping(0, 33, 2, 9),
+	}, sourceMap.Mappings)
+}
+
+func TestEmptyAtRulesInAllStyles(t *testing.T) {
+	var scss = `@media print { .a { } %p { x: 1; } } @supports (display: grid) { .b { .c { } } } @media screen { .d { y: 2; } }`
+	AssertCompile(t, NestedStyle, scss, "@media screen {\n  .d {\n    y: 2; } }\n")
+	AssertCompile(t, ExpandedStyle, scss, "@media screen {\n  .d {\n    y: 2;\n  }\n}\n")
+	AssertCompile(t, CompactStyle, scss, "@media screen { .d { y: 2; } }\n")
+	AssertCompile(t, CompressedStyle, scss, "@media screen{.d{y:2}}\n")
+}
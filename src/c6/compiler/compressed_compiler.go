@@ -0,0 +1,127 @@
+package compiler
+
+import "c6/ast"
+import "io"
+import "strings"
+
+/*
+CompressedStyleCompiler generates the minified output, the optional
+whitespaces, the comments and the last semicolon of the rulesets are
+removed, the numbers and the colors are rendered in the shortest form:
+
+	.foo{color:red;background:#fff}.foo .bar{color:blue}
+*/
+type CompressedStyleCompiler struct {
+	BaseCompiler
+}
+
+func NewCompressedStyleCompiler(writer io.Writer) *CompressedStyleCompiler {
+	return &CompressedStyleCompiler{BaseCompiler{Writer: writer, Compressed: true}}
+}
+
+func (self *CompressedStyleCompiler) CompileStatements(stmts []ast.Statement) error {
+	for _, stm := range BubbleAtRules(stmts) {
+		var out = self.CompileStatement(stm, nil)
+		if out != "" {
+			self.write(out)
+			self.lineOpen = true
+		}
+	}
+	return self.finish()
+}
+
+func (self *CompressedStyleCompiler) CompileBlock(block *ast.Block) error {
+	return self.CompileStatements(block.Statements)
+}
+
+/*
+CompileStatement returns the minified output of the statement. The property
+is returned without the semicolon, the semicolons are only put between the
+declarations.
+*/
+func (self *CompressedStyleCompiler) CompileStatement(anyStm ast.Statement, parentSelectors ast.SelectorGroup) string {
+	switch stm := anyStm.(type) {
+
+	case *ast.CharsetStatement:
+		return self.CompileCharsetStatement(stm)
+
+	case *ast.ImportStatement:
+		return self.CompileImportStatement(stm)
+
+	case *ast.CommentStatement:
+		// only the loud comments "/*! ... */" are kept, e.g. the license.
+		if strings.HasPrefix(stm.Text, "!") {
+			return stm.String()
+		}
+
+	case *ast.RuleSet:
+		return self.CompileRuleSet(stm, parentSelectors)
+
+	case *ast.NamespaceStatement:
+		return self.CompileNamespaceStatement(stm)
+
+	case *ast.MediaQueryStatement, *ast.SupportsStatement, *ast.KeyframesStatement,
+		*ast.FontFaceStatement, *ast.PageStatement, *ast.AtRuleStatement:
+		return self.CompileAtRule(stm, parentSelectors)
+
+	case *ast.Property:
+		return strings.Join(self.CompileProperties(stm), ";")
+	}
+	return ""
+}
+
+/*
+CompileAtRule returns the at-rule and its block, e.g. @media or @supports, a
+declaration directly inside the block is followed by a semicolon unless it's
+the last item of the block.
+*/
+func (self *CompressedStyleCompiler) CompileAtRule(stm ast.Statement, parentSelectors ast.SelectorGroup) string {
+	if !self.HasOutput(stm, parentSelectors) {
+		return ""
+	}
+	var prelude, stmts = self.CompileAtRulePrelude(stm)
+	if stmts == nil {
+		return prelude + ";"
+	}
+	var out = ""
+	var afterProperty = false
+	for _, subStm := range stmts {
+		var item = self.CompileStatement(subStm, parentSelectors)
+		if item == "" {
+			continue
+		}
+		var _, isProperty = subStm.(*ast.Property)
+		if afterProperty {
+			out += ";"
+		}
+		out += item
+		afterProperty = isProperty
+	}
+	if out == "" {
+		return ""
+	}
+	return prelude + "{" + out + "}"
+}
+
+func (self *CompressedStyleCompiler) CompileRuleSet(ruleset *ast.RuleSet, parentSelectors ast.SelectorGroup) string {
+	var selectors = self.CompileRuleSetSelectors(parentSelectors, ruleset)
+	if ruleset.Block == nil {
+		return ""
+	}
+
+	var out = ""
+	var items = []string{}
+	for _, stm := range self.Declarations(ruleset) {
+		if item := self.CompileStatement(stm, selectors); item != "" {
+			items = append(items, item)
+		}
+	}
+	if len(items) > 0 && len(selectors) > 0 {
+		out += self.mark(ruleset.Token) + self.JoinSelectors(selectors) + "{" + strings.Join(items, ";") + "}"
+	}
+
+	for _, subRuleSet := range ruleset.Block.SubRuleSets {
+		out += self.CompileRuleSet(subRuleSet, selectors)
+	}
+	return out
+}
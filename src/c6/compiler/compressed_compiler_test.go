@@ -0,0 +1,58 @@
+package compiler
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestCompressedStyleRuleSet(t *testing.T) {
+	AssertCompile(t, CompressedStyle, `.foo { color: red; background: #ffffff; } .foo > .bar { color: blue; }`,
+		".foo{color:red;background:#fff}.foo>.bar{color:blue}\n")
+}
+
+func TestCompressedStyleSubRuleSet(t *testing.T) {
+	AssertCompile(t, CompressedStyle, `.foo { color: red; .bar { color: blue; } }`,
+		".foo{color:red}.foo .bar{color:blue}\n")
+}
+
+func TestCompressedStyleValues(t *testing.T) {
+	AssertCompile(t, CompressedStyle, `div { opacity: 0.5; margin: -0.25em 0 auto; font: 12px/1.5 "Helvetica", Arial; color: #ff0000 !important; }`,
+		"div{opacity:.5;margin:-.25em 0 auto;font:12px/1.5 \"Helvetica\",Arial;color:red!important}\n")
+}
+
+func TestCompressedStyleMediaQuery(t *testing.T) {
+	AssertCompile(t, CompressedStyle, `@media screen and (min-width: 500px), print { .foo { color: blue } }`,
+		"@media screen and (min-width:500px),print{.foo{color:blue}}\n")
+}
+
+func TestCompressedStyleComment(t *testing.T) {
+	AssertCompile(t, CompressedStyle, `/*! license */ /* dropped */ div { /* dropped */ color: red; }`,
+		"/*! license */div{color:red}\n")
+}
+
+func TestShortestColor(t *testing.T) {
+	assert.Equal(t, "#fff", ShortestColor(255, 255, 255))
+	assert.Equal(t, "red", ShortestColor(255, 0, 0))
+	assert.Equal(t, "#f00f01", ShortestColor(240, 15, 1))
+	assert.Equal(t, "gray", ShortestColor(128, 128, 128))
+	assert.Equal(t, "#000", ShortestColor(0, 0, 0))
+}
+
+func TestStripLeadingZero(t *testing.T) {
+	assert.Equal(t, ".5", StripLeadingZero("0.5"))
+	assert.Equal(t, "-.5", StripLeadingZero("-0.5"))
+	assert.Equal(t, "10.5", StripLeadingZero("10.5"))
+	assert.Equal(t, "0", StripLeadingZero("0"))
+}
+
+func TestCompressedStyleNestedProperties(t *testing.T) {
+	AssertCompile(t, CompressedStyle, `.foo { border: { top: { width: 1px; color: red; } } }`,
+		".foo{border-top-width:1px;border-top-color:red}\n")
+}
+
+func TestCompressedStyleParentSelector(t *testing.T) {
+	AssertCompile(t, CompressedStyle, `.a { & > .b { x: 1; } ~ .c { x: 2; } }`,
+		".a>.b{x:1}.a~.c{x:2}\n")
+}
+
+func TestCompressedStyleSelectorGroup(t *testing.T) {
+	AssertCompile(t, CompressedStyle, `h1, h2 > a { x: 1; }`, "h1,h2>a{x:1}\n")
+}
@@ -0,0 +1,79 @@
+package compiler
+
+import "c6/ast"
+import "io"
+
+/*
+ExpandedStyleCompiler generates the SASS "expanded" output style, every
+declaration is on its own line and the closing brace is on a separate line:
+
+	.foo {
+	  color: red;
+	}
+	.foo .bar {
+	  color: blue;
+	}
+*/
+type ExpandedStyleCompiler struct {
+	BaseCompiler
+}
+
+func NewExpandedStyleCompiler(writer io.Writer) *ExpandedStyleCompiler {
+	return &ExpandedStyleCompiler{BaseCompiler{Writer: writer}}
+}
+
+func (self *ExpandedStyleCompiler) CompileStatements(stmts []ast.Statement) error {
+	return self.compileStatements(self, stmts)
+}
+
+func (self *ExpandedStyleCompiler) CompileBlock(block *ast.Block) error {
+	return self.CompileStatements(block.Statements)
+}
+
+func (self *ExpandedStyleCompiler) CompileStatement(anyStm ast.Statement, parentSelectors ast.SelectorGroup) {
+	self.compileStatement(self, anyStm, parentSelectors)
+}
+
+/*
+CompileAtRule writes the at-rule and its block, e.g. @media or @supports.
+*/
+func (self *ExpandedStyleCompiler) CompileAtRule(stm ast.Statement, parentSelectors ast.SelectorGroup) {
+	var prelude, stmts = self.CompileAtRulePrelude(stm)
+	if stmts == nil {
+		self.writeLine(prelude + ";")
+		return
+	}
+	self.writeLine(prelude + " {")
+	self.Indent++
+	for _, subStm := range stmts {
+		self.CompileStatement(subStm, parentSelectors)
+	}
+	self.separate = false
+	self.Indent--
+	self.writeLine("}")
+	if self.Indent == 0 {
+		self.separate = true
+	}
+}
+
+func (self *ExpandedStyleCompiler) CompileRuleSet(ruleset *ast.RuleSet, parentSelectors ast.SelectorGroup) {
+	var selectors = self.CompileRuleSetSelectors(parentSelectors, ruleset)
+	if ruleset.Block == nil {
+		return
+	}
+
+	var declarations = self.Declarations(ruleset)
+	if len(declarations) > 0 && len(selectors) > 0 {
+		self.writeLine(self.mark(ruleset.Token) + self.JoinSelectors(selectors) + " {")
+		self.Indent++
+		for _, stm := range declarations {
+			self.CompileStatement(stm, selectors)
+		}
+		self.Indent--
+		self.writeLine("}")
+	}
+
+	for _, subRuleSet := range ruleset.Block.SubRuleSets {
+		self.CompileRuleSet(subRuleSet, selectors)
+	}
+}
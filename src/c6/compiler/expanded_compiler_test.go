@@ -0,0 +1,23 @@
+package compiler
+
+import "testing"
+
+func TestExpandedStyleRuleSet(t *testing.T) {
+	AssertCompile(t, ExpandedStyle, `.foo { color: red; background: #fff; }`,
+		".foo {\n  color: red;\n  background: #fff;\n}\n")
+}
+
+func TestExpandedStyleSubRuleSet(t *testing.T) {
+	AssertCompile(t, ExpandedStyle, `.foo { color: red; .bar { color: blue; } } p { margin: 0 }`,
+		".foo {\n  color: red;\n}\n.foo .bar {\n  color: blue;\n}\n\np {\n  margin: 0;\n}\n")
+}
+
+func TestExpandedStyleMediaQuery(t *testing.T) {
+	AssertCompile(t, ExpandedStyle, `@media screen { .foo { color: blue } .bar { color: red } } p { x: y }`,
+		"@media screen {\n  .foo {\n    color: blue;\n  }\n  .bar {\n    color: red;\n  }\n}\n\np {\n  x: y;\n}\n")
+}
+
+func TestExpandedStyleComment(t *testing.T) {
+	AssertCompile(t, ExpandedStyle, `/* header */ div { /* inside */ color: red; }`,
+		"/* header */\ndiv {\n  /* inside */\n  color: red;\n}\n")
+}
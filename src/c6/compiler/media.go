@@ -0,0 +1,113 @@
+package compiler
+
+import (
+	"c6/ast"
+	"strings"
+)
+
+/*
+MergeMediaQueryLists merges the queries of a nested @media statement with the
+queries of the enclosing @media statement. The result is the cross product of
+the two lists, the queries that can't be merged are dropped.
+*/
+func MergeMediaQueryLists(outer, inner []*ast.MediaQuery) []*ast.MediaQuery {
+	var out = []*ast.MediaQuery{}
+	for _, outerQuery := range outer {
+		for _, innerQuery := range inner {
+			if query := MergeMediaQuery(outerQuery, innerQuery); query != nil {
+				out = append(out, query)
+			}
+		}
+	}
+	return out
+}
+
+/*
+MergeMediaQuery returns the query matching both of the queries, e.g. "screen"
+and "(min-width: 600px)" are merged into "screen and (min-width: 600px)". It
+returns nil when the queries can't be merged, e.g. "screen" and "print".
+*/
+func MergeMediaQuery(outer, inner *ast.MediaQuery) *ast.MediaQuery {
+	var outerModifier, outerType = splitMediaType(outer.MediaType)
+	var innerModifier, innerType = splitMediaType(inner.MediaType)
+
+	var mediaType ast.Expression
+	var features = joinMediaFeatures(outer.MediaExpression, inner.MediaExpression)
+
+	if (outerModifier == "not") != (innerModifier == "not") {
+		// "not screen" and "print" is "print", but "not screen" and "screen"
+		// or "not screen" and "all" can't be represented.
+		if outerType == innerType || isAllMediaType(outerType) || isAllMediaType(innerType) {
+			return nil
+		}
+		if outerModifier == "not" {
+			mediaType, features = inner.MediaType, inner.MediaExpression
+		} else {
+			mediaType, features = outer.MediaType, outer.MediaExpression
+		}
+	} else if outerModifier == "not" {
+		// both of the queries are negated, they're merged only if they're the same.
+		if outerType != innerType || mediaFeaturesString(outer.MediaExpression) != mediaFeaturesString(inner.MediaExpression) {
+			return nil
+		}
+		mediaType, features = outer.MediaType, outer.MediaExpression
+	} else if isAllMediaType(outerType) {
+		mediaType = inner.MediaType
+		if mediaType == nil {
+			mediaType = outer.MediaType
+		}
+	} else if isAllMediaType(innerType) {
+		mediaType = outer.MediaType
+	} else if outerType != innerType {
+		return nil
+	} else if outerModifier != "" {
+		mediaType = outer.MediaType
+	} else {
+		mediaType = inner.MediaType
+	}
+	return ast.NewMediaQuery(mediaType, features)
+}
+
+/*
+splitMediaType returns the modifier ("not" or "only") and the lower-cased
+name of the media type, both are empty when the query has no media type.
+*/
+func splitMediaType(anyExpr ast.Expression) (modifier string, name string) {
+	switch expr := anyExpr.(type) {
+	case *ast.UnaryExpression:
+		_, name = splitMediaType(expr.Expr)
+		switch expr.Op.Type {
+		case ast.T_LOGICAL_NOT:
+			modifier = "not"
+		case ast.T_ONLY:
+			modifier = "only"
+		}
+		return modifier, name
+	case *ast.Ident:
+		return "", strings.ToLower(expr.Ident)
+	case nil:
+		return "", ""
+	}
+	return "", strings.ToLower(anyExpr.String())
+}
+
+func isAllMediaType(name string) bool {
+	return name == "" || name == "all"
+}
+
+func joinMediaFeatures(left, right ast.Expression) ast.Expression {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	return ast.NewBinaryExpression(ast.NewOp(ast.T_LOGICAL_AND), left, right, false)
+}
+
+func mediaFeaturesString(expr ast.Expression) string {
+	if expr == nil {
+		return ""
+	}
+	return expr.String()
+}
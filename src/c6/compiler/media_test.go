@@ -0,0 +1,44 @@
+package compiler
+
+import "testing"
+
+func TestBubbleMediaQueryOutOfRuleSet(t *testing.T) {
+	AssertCompile(t, CompactStyle, `.a { color: red; @media screen { color: blue; .b { x: y; } } .c { z: w; } }`,
+		".a { color: red; }\n\n@media screen { .a { color: blue; } .a .b { x: y; } }\n\n.a .c { z: w; }\n")
+}
+
+func TestBubbleMediaQueryKeepsSourceOrder(t *testing.T) {
+	AssertCompile(t, CompactStyle, `a { @media x { b: 1; } c { d: 2; } @media x { e: 3; } }`,
+		"@media x { a { b: 1; } }\n\na c { d: 2; }\n\n@media x { a { e: 3; } }\n")
+	AssertCompile(t, CompactStyle, `a { b: 1; @media x { c: 2; } d: 3; e { @media y { f: 4; } g: 5; } h { i: 6; } }`,
+		"a { b: 1; }\n\n@media x { a { c: 2; } }\n\na { d: 3; }\n\n@media y { a e { f: 4; } }\n\na e { g: 5; }\na h { i: 6; }\n")
+}
+
+func TestBubbleMediaQueryWithParentSelector(t *testing.T) {
+	AssertCompile(t, CompactStyle, `.a, .b { @media print { &:hover { x: 1; } } }`,
+		"@media print { .a:hover, .b:hover { x: 1; } }\n")
+}
+
+func TestMergeNestedMediaQueries(t *testing.T) {
+	AssertCompile(t, CompactStyle, `@media screen, print { @media (min-width: 600px) { .a { x: 1; } } }`,
+		"@media screen and (min-width: 600px), print and (min-width: 600px) { .a { x: 1; } }\n")
+	AssertCompile(t, CompactStyle, `.a { @media only screen { @media (min-width: 600px) and (max-width: 900px) { x: 1; } } }`,
+		"@media only screen and (min-width: 600px) and (max-width: 900px) { .a { x: 1; } }\n")
+	AssertCompile(t, CompactStyle, `@media all { @media screen { .a { x: 1; } } }`,
+		"@media screen { .a { x: 1; } }\n")
+	AssertCompile(t, CompactStyle, `@media not screen { @media print { .a { x: 1; } } }`,
+		"@media print { .a { x: 1; } }\n")
+}
+
+func TestDropUnmergeableMediaQueries(t *testing.T) {
+	AssertCompile(t, CompactStyle, `@media screen { @media print { .a { x: 1; } } } .b { x: 2; }`,
+		".b { x: 2; }\n")
+	AssertCompile(t, CompactStyle, `@media screen { @media print, (min-width: 600px) { .a { x: 1; } } }`,
+		"@media screen and (min-width: 600px) { .a { x: 1; } }\n")
+	AssertCompile(t, CompactStyle, `@media not screen { @media screen { .a { x: 1; } } }`, "")
+}
+
+func TestNestedStyleBubbledMediaQuery(t *testing.T) {
+	AssertCompile(t, NestedStyle, `.a { color: red; @media screen { color: blue; } }`,
+		".a {\n  color: red; }\n\n@media screen {\n  .a {\n    color: blue; } }\n")
+}
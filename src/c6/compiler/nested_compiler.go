@@ -1,32 +1,89 @@
 package compiler
 
 import "c6/ast"
+import "io"
 
-type Compiler interface {
-	CompileBlock(block *ast.Block) string
-}
+/*
+NestedStyleCompiler generates the SASS "nested" output style, the indentation
+of a ruleset reflects the nesting level of the original stylesheet, the
+closing brace is put at the end of the last declaration:
 
+	.foo {
+	  color: red; }
+	  .foo .bar {
+	    color: blue; }
+*/
 type NestedStyleCompiler struct {
-	Indent int
-	Output string
+	BaseCompiler
 }
 
-func NewNestedStyleCompiler() *NestedStyleCompiler {
-	return &NestedStyleCompiler{}
+func NewNestedStyleCompiler(writer io.Writer) *NestedStyleCompiler {
+	return &NestedStyleCompiler{BaseCompiler{Writer: writer}}
 }
 
-func (self *NestedStyleCompiler) CompileProperty(property *ast.Property) {
-
+// closeBlock puts the closing brace at the end of the current line.
+func (self *NestedStyleCompiler) closeBlock() {
+	self.write(" }")
 }
 
-func (self *NestedStyleCompiler) CompileSeletors(selectors *ast.Selector) {
+func (self *NestedStyleCompiler) CompileStatements(stmts []ast.Statement) error {
+	return self.compileStatements(self, stmts)
+}
 
+func (self *NestedStyleCompiler) CompileBlock(block *ast.Block) error {
+	return self.CompileStatements(block.Statements)
 }
 
-func (self *NestedStyleCompiler) CompileRuleSet(ruleset *ast.RuleSet) {
+/*
+The parent selectors are used for the statements inside a ruleset, they're nil
+for the top level statements.
+*/
+func (self *NestedStyleCompiler) CompileStatement(anyStm ast.Statement, parentSelectors ast.SelectorGroup) {
+	self.compileStatement(self, anyStm, parentSelectors)
+}
 
+/*
+CompileAtRule writes the at-rule and its block, e.g. @media or @supports.
+*/
+func (self *NestedStyleCompiler) CompileAtRule(stm ast.Statement, parentSelectors ast.SelectorGroup) {
+	var prelude, stmts = self.CompileAtRulePrelude(stm)
+	if stmts == nil {
+		self.writeLine(prelude + ";")
+		return
+	}
+	self.writeLine(prelude + " {")
+	self.Indent++
+	for _, subStm := range stmts {
+		self.CompileStatement(subStm, parentSelectors)
+	}
+	self.closeBlock()
+	self.Indent--
+	if self.Indent == 0 {
+		self.separate = true
+	}
 }
 
-func (self *NestedStyleCompiler) CompileBlock(block *ast.Block) {
+func (self *NestedStyleCompiler) CompileRuleSet(ruleset *ast.RuleSet, parentSelectors ast.SelectorGroup) {
+	var selectors = self.CompileRuleSetSelectors(parentSelectors, ruleset)
+	if ruleset.Block == nil {
+		return
+	}
+
+	var declarations = self.Declarations(ruleset)
+	if len(declarations) > 0 && len(selectors) > 0 {
+		self.writeLine(self.mark(ruleset.Token) + self.JoinSelectors(selectors) + " {")
+		self.Indent++
+		for _, stm := range declarations {
+			self.CompileStatement(stm, selectors)
+		}
+		self.closeBlock()
+	}
+
+	for _, subRuleSet := range ruleset.Block.SubRuleSets {
+		self.CompileRuleSet(subRuleSet, selectors)
+	}
 
+	if len(declarations) > 0 && len(selectors) > 0 {
+		self.Indent--
+	}
 }
@@ -0,0 +1,88 @@
+package compiler
+
+import "c6"
+import "bytes"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func AssertNestedStyle(t *testing.T, scss string, css string) {
+	var parser = c6.NewParser(c6.NewContext())
+	var stmts = parser.ParseScss(scss)
+	var buf bytes.Buffer
+	var compiler = NewNestedStyleCompiler(&buf)
+	assert.Nil(t, compiler.CompileStatements(stmts))
+	assert.Equal(t, css, buf.String())
+}
+
+func TestNestedStyleRuleSet(t *testing.T) {
+	AssertNestedStyle(t, `.foo { color: red; background: #fff; }`,
+		".foo {\n  color: red;\n  background: #fff; }\n")
+}
+
+func TestNestedStyleRuleSets(t *testing.T) {
+	AssertNestedStyle(t, `div { width: auto } p { margin: 0 auto; }`,
+		"div {\n  width: auto; }\n\np {\n  margin: 0 auto; }\n")
+}
+
+func TestNestedStyleLineComments(t *testing.T) {
+	AssertNestedStyle(t, "a { // x\n color: red; // y\n // z\n b { c: 1; } }",
+		"a {\n  color: red; }\n  a b {\n    c: 1; }\n")
+}
+
+func TestNestedStyleEmptyRuleSet(t *testing.T) {
+	AssertNestedStyle(t, `div { } p { float: left; }`, "p {\n  float: left; }\n")
+}
+
+func TestNestedStyleSubRuleSet(t *testing.T) {
+	AssertNestedStyle(t, `.foo { color: red; .bar { color: blue; } }`,
+		".foo {\n  color: red; }\n  .foo .bar {\n    color: blue; }\n")
+}
+
+func TestNestedStyleSubRuleSetWithoutDeclaration(t *testing.T) {
+	AssertNestedStyle(t, `.foo { .bar { color: blue; } }`,
+		".foo .bar {\n  color: blue; }\n")
+}
+
+func TestNestedStyleChildCombinator(t *testing.T) {
+	AssertNestedStyle(t, `div > p { x: y }`, "div > p {\n  x: y; }\n")
+}
+
+func TestNestedStyleExpressionValue(t *testing.T) {
+	AssertNestedStyle(t, `div { width: 10px + 2px; height: 0.1 + 0.2; }`,
+		"div {\n  width: 12px;\n  height: 0.3; }\n")
+}
+
+func TestNestedStyleCssSlash(t *testing.T) {
+	AssertNestedStyle(t, `div { font: 12px/24px "Helvetica", Arial, sans-serif; }`,
+		"div {\n  font: 12px/24px \"Helvetica\", Arial, sans-serif; }\n")
+}
+
+func TestNestedStyleImportant(t *testing.T) {
+	AssertNestedStyle(t, `div { color: red !important; }`, "div {\n  color: red !important; }\n")
+}
+
+func TestNestedStyleFunctionCall(t *testing.T) {
+	AssertNestedStyle(t, `div { background: url(../images/foo.png) no-repeat; color: rgba(255,255,255,0.5); }`,
+		"div {\n  background: url(../images/foo.png) no-repeat;\n  color: rgba(255, 255, 255, 0.5); }\n")
+}
+
+func TestNestedStyleComment(t *testing.T) {
+	AssertNestedStyle(t, "/* header */\n// dropped\ndiv { /* inside */ color: red; }",
+		"/* header */\ndiv {\n  /* inside */\n  color: red; }\n")
+}
+
+func TestNestedStyleMediaQuery(t *testing.T) {
+	AssertNestedStyle(t, `@media screen and (min-width: 500px), not print { .foo { color: blue } }`,
+		"@media screen and (min-width: 500px), not print {\n  .foo {\n    color: blue; } }\n")
+}
+
+func TestNestedStyleCharsetAndImport(t *testing.T) {
+	AssertNestedStyle(t, `@charset "UTF-8"; @import url(foo.css) screen; @import "bar.css";`,
+		"@charset \"UTF-8\";\n@import url(foo.css) screen;\n@import \"bar.css\";\n")
+}
+
+func TestFormatNumber(t *testing.T) {
+	assert.Equal(t, "0.33333", FormatNumber(1.0/3))
+	assert.Equal(t, "10", FormatNumber(10.0))
+	assert.Equal(t, "0", FormatNumber(-0.000001))
+}
@@ -10,10 +10,30 @@ type Context struct {
 	RuleSetStack   []*ast.RuleSet
 	GlobalBlock    *ast.Block
 	GlobalSymTable *symtable.SymTable
+
+	// the directories to look up the imported files
+	LoadPaths []string
+
+	// the importers to try before the load paths
+	Importers []Importer
+
+	// the imported files are parsed via the map if it's not nil
+	FileAstMap *FileAstMap
+
+	// the files being imported, for detecting the @import loop
+	ImportStack []string
+
+	// the files imported in the compilation, for @import-once
+	ImportedFiles map[string]bool
 }
 
 func NewContext() *Context {
-	return &Context{[]*ast.RuleSet{}, nil, &symtable.SymTable{}}
+	return &Context{
+		RuleSetStack:   []*ast.RuleSet{},
+		GlobalSymTable: &symtable.SymTable{},
+		LoadPaths:      []string{},
+		ImportedFiles:  map[string]bool{},
+	}
 }
 
 func (context *Context) PushRuleSet(ruleSet *ast.RuleSet) {
@@ -33,7 +53,7 @@ func (context *Context) PopRuleSet() (*ast.RuleSet, bool) {
 	}
 	var idx = len(context.RuleSetStack) - 1
 	var ruleSet = context.RuleSetStack[idx]
-	context.RuleSetStack = context.RuleSetStack[:idx-1]
+	context.RuleSetStack = context.RuleSetStack[:idx]
 	return ruleSet, true
 }
 
@@ -44,13 +64,24 @@ func (context *Context) TopRuleSet() *ast.RuleSet {
 	return nil
 }
 
+/*
+GetVariable looks up the variable from the innermost ruleset to the global
+scope.
+*/
 func (context *Context) GetVariable(name string) (symtable.SymTableItem, bool) {
-	var idx = len(context.RuleSetStack) - 1
-	for ; idx > 0; idx-- {
+	for idx := len(context.RuleSetStack) - 1; idx >= 0; idx-- {
 		ruleset := context.RuleSetStack[idx]
+		if ruleset.Block == nil || ruleset.Block.SymTable == nil {
+			continue
+		}
 		if variable, ok := ruleset.Block.SymTable.Get(name); ok {
 			return variable, true
 		}
 	}
-	return nil, false
+	if context.GlobalBlock != nil && context.GlobalBlock.SymTable != nil {
+		if variable, ok := context.GlobalBlock.SymTable.Get(name); ok {
+			return variable, true
+		}
+	}
+	return context.GlobalSymTable.Get(name)
 }
@@ -0,0 +1,71 @@
+package c6
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestControlIfElseIfChain(t *testing.T) {
+	css, err := evaluateScss(`
+	@mixin theme($theme) {
+		@if $theme == light { color: white; }
+		@else if $theme == dark { color: black; }
+		@else { color: gray; }
+	}
+	.a { @include theme(light); }
+	.b { @include theme(dark); }
+	.c { @include theme(blue); }
+	.d { @if null { x: y; } @if 0 and not false { z: 1; } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { color: white; }\n\n.b { color: black; }\n\n"+
+		".c { color: gray; }\n\n.d { z: 1; }\n", css)
+}
+
+func TestControlForRanges(t *testing.T) {
+	css, err := evaluateScss(`
+	.a {
+		@for $i from 1 through 3 { through: $i; }
+		@for $i from 1 to 3 { to: $i; }
+		@for $i from 3 through 1 { down: $i; }
+		@for $i from 1px to 3px { unit: $i; }
+	}`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { through: 1; through: 2; through: 3; to: 1; to: 2; "+
+		"down: 3; down: 2; down: 1; unit: 1px; unit: 2px; }\n", css)
+}
+
+func TestControlForGeneratesRuleSets(t *testing.T) {
+	css, err := evaluateScss(`
+	@for $i from 1 through 2 { .col { width: 50% * $i; } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".col { width: 50%; }\n\n.col { width: 100%; }\n", css)
+}
+
+func TestControlWhileAssignsOuterVariable(t *testing.T) {
+	css, err := evaluateScss(`
+	$i: 6;
+	$total: 0;
+	@while $i > 0 {
+		.item { width: 10px * $i; }
+		$total: $total + $i;
+		$i: $i - 2;
+		$local: 1;
+	}
+	.sum { total: $total; }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".item { width: 60px; }\n\n.item { width: 40px; }\n\n"+
+		".item { width: 20px; }\n\n.sum { total: 12; }\n", css)
+}
+
+func TestControlForRangeErrors(t *testing.T) {
+	var cases = map[string]string{
+		"$a: foo;\n.a {\n  @for $i from $a through 3 { x: $i; }\n}":                                ":3: The start of @for range must be a number, got $a",
+		"$b: \"3\";\n@function f() {\n  @for $i from 1 to $b { }\n  @return 1;\n}\n.a { x: f(); }": ":3: The end of @for range must be a number, got $b",
+		".a {\n  @for $i from 1 through 2.5 { x: $i; }\n}":                                         ":2: 2.5 is not an int",
+		".a {\n  @for $i from 0.5px to 3 { x: $i; }\n}":                                            ":2: 0.5px is not an int",
+	}
+	for code, message := range cases {
+		_, err := evaluateScss(code)
+		if assert.NotNil(t, err, code) {
+			assert.Contains(t, err.Error(), message, code)
+		}
+	}
+}
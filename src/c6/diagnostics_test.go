@@ -0,0 +1,133 @@
+package c6
+
+import "bytes"
+import "testing"
+import "c6/ast"
+import "c6/compiler"
+import "c6/logger"
+import "c6/runtime"
+import "github.com/stretchr/testify/assert"
+
+func evaluateScssWithDiagnostics(code string) (string, []*logger.Diagnostic, error) {
+	var parser = NewParser(NewContext())
+	stmts, err := parser.Parse(code, ScssFileType)
+	if err != nil {
+		return "", nil, err
+	}
+	var collector = logger.NewCollector()
+	var interpreter = runtime.NewInterpreter()
+	interpreter.ParseSelectors = ParseSelectorGroup
+	interpreter.Diagnostics = collector
+	stmts, err = interpreter.EvaluateStatements(stmts)
+	if err != nil {
+		return "", collector.Diagnostics, err
+	}
+	var buf bytes.Buffer
+	err = compiler.NewCompiler(&buf, compiler.CompactStyle).CompileStatements(stmts)
+	return buf.String(), collector.Diagnostics, err
+}
+
+func TestParserDiagnosticStatements(t *testing.T) {
+	var stmts = RunParserTest(`@debug 10px + 2px; @warn "deprecated"; @error "failed";`)
+	assert.Equal(t, 3, len(stmts))
+
+	debug, ok := stmts[0].(*ast.DebugStatement)
+	if assert.True(t, ok) {
+		assert.Equal(t, "12px", debug.Value.String())
+	}
+	_, ok = stmts[1].(*ast.WarnStatement)
+	assert.True(t, ok)
+	_, ok = stmts[2].(*ast.ErrorStatement)
+	assert.True(t, ok)
+}
+
+func TestDebugAndWarn(t *testing.T) {
+	css, diagnostics, err := evaluateScssWithDiagnostics(`$width: 10px;
+@debug $width * 2;
+.a {
+  @warn "The width is deprecated";
+  width: $width;
+}`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { width: 10px; }\n", css)
+	if assert.Len(t, diagnostics, 2) {
+		assert.Equal(t, logger.LevelDebug, diagnostics[0].Level)
+		assert.Equal(t, 2, diagnostics[0].Line)
+		assert.Equal(t, "20px", diagnostics[0].Message)
+		assert.Equal(t, logger.LevelWarn, diagnostics[1].Level)
+		assert.Equal(t, 4, diagnostics[1].Line)
+		assert.Equal(t, "The width is deprecated", diagnostics[1].Message)
+	}
+}
+
+func TestWarnInFunction(t *testing.T) {
+	css, diagnostics, err := evaluateScssWithDiagnostics(`
+@function half($n) {
+  @warn "half() is deprecated";
+  @return $n / 2;
+}
+.a { width: half(10px); }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { width: 5px; }\n", css)
+	if assert.Len(t, diagnostics, 1) {
+		assert.Equal(t, "half() is deprecated", diagnostics[0].Message)
+		assert.Equal(t, 3, diagnostics[0].Line)
+	}
+}
+
+func TestErrorInMixin(t *testing.T) {
+	_, _, err := evaluateScssWithDiagnostics(`
+@mixin size($size) {
+  @if $size != small and $size != large {
+    @error "Unknown size";
+  }
+  width: 10px;
+}
+.a { @include size(medium); }`)
+	if assert.NotNil(t, err) {
+		runtimeErr, ok := err.(*runtime.RuntimeError)
+		if assert.True(t, ok) {
+			assert.Equal(t, 4, runtimeErr.Line)
+			userErr, ok := runtimeErr.Err.(*runtime.UserError)
+			if assert.True(t, ok) {
+				assert.Equal(t, "Unknown size", userErr.Message)
+			}
+		}
+	}
+}
+
+func TestErrorInFunction(t *testing.T) {
+	_, _, err := evaluateScssWithDiagnostics(`
+@function f($n) {
+  @if $n < 0 { @error "Expecting a positive number"; }
+  @return $n;
+}
+.a { width: f(-1); }`)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), ":3: Expecting a positive number")
+	}
+}
+
+func TestDiagnosticsWithInterpolation(t *testing.T) {
+	css, diagnostics, err := evaluateScssWithDiagnostics(`
+$name: "icon";
+.a {
+  @warn "#{$name} is deprecated, use '#{$name}-2'";
+  content: "a#{$name}";
+}`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { content: \"aicon\"; }\n", css)
+	if assert.Len(t, diagnostics, 1) {
+		assert.Equal(t, "icon is deprecated, use 'icon-2'", diagnostics[0].Message)
+	}
+
+	_, _, err = evaluateScssWithDiagnostics(`
+@function f($n) {
+  @if $n < 0 { @error "negative: #{$n}"; }
+  @return $n;
+}
+.a { width: f(-1px); }`)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), ":3: negative: -1px")
+	}
+}
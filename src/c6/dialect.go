@@ -0,0 +1,31 @@
+package c6
+
+/*
+DialectFlags turns on the language features on top of SCSS, the lexer and
+the parser of SCSS are shared by the dialects.
+*/
+type DialectFlags uint
+
+const (
+	// @switch, @case and @default
+	AllowSwitchStatement DialectFlags = 1 << iota
+
+	// @import-once imports the file only if it's not imported yet
+	AllowImportOnce
+
+	// "foo" + $bar is an error, the strings are concatenated by
+	// interpolation: "foo#{$bar}"
+	DisallowImplicitConcat
+)
+
+const ScssDialect DialectFlags = 0
+
+/*
+ECSS is the dialect of the .ecss files, the grammar is described in
+grammar.ebnf.
+*/
+const EcssDialect = AllowSwitchStatement | AllowImportOnce | DisallowImplicitConcat
+
+func (flags DialectFlags) Has(flag DialectFlags) bool {
+	return flags&flag != 0
+}
@@ -0,0 +1,116 @@
+package c6
+
+import "bytes"
+import "c6/ast"
+import "c6/compiler"
+import "c6/runtime"
+import "os"
+import "path/filepath"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func RunEcssParserTest(code string) ([]ast.Statement, error) {
+	var parser = NewParser(NewContext())
+	parser.File = "main.ecss"
+	return parser.Parse(code, EcssFileType)
+}
+
+func TestEcssSwitchStatement(t *testing.T) {
+	stmts, err := RunEcssParserTest(`
+	@switch $type {
+		// the primary button
+		@case primary { .btn { color: blue; } }
+		@case danger, warning { .btn { color: red; } }
+		@default { .btn { color: black; } }
+	}`)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(stmts))
+
+	stm, ok := stmts[0].(*ast.IfStatement)
+	assert.True(t, ok)
+	condition, ok := stm.Condition.(*ast.BinaryExpression)
+	assert.True(t, ok)
+	assert.Equal(t, ast.T_EQUAL, condition.Op.Type)
+	assert.Equal(t, 1, len(stm.Block.Statements))
+
+	assert.Equal(t, 1, len(stm.ElseIfs))
+	condition, ok = stm.ElseIfs[0].Condition.(*ast.BinaryExpression)
+	assert.True(t, ok)
+	assert.Equal(t, ast.T_LOGICAL_OR, condition.Op.Type)
+
+	assert.NotNil(t, stm.ElseBlock)
+	assert.Equal(t, 1, len(stm.ElseBlock.Statements))
+}
+
+func TestEcssSwitchStatementWithoutCase(t *testing.T) {
+	_, err := RunEcssParserTest(`@switch $type { @default { } }`)
+	assert.NotNil(t, err)
+}
+
+func TestScssDoesNotSupportSwitchStatement(t *testing.T) {
+	_, err := NewParser(NewContext()).Parse(`@switch $type { @case a { } }`, ScssFileType)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "The at-rule '@switch' is only supported in ECSS")
+
+	_, err = NewParser(NewContext()).Parse(`@import-once "foo";`, ScssFileType)
+	assert.NotNil(t, err)
+}
+
+func TestEcssImplicitStringConcat(t *testing.T) {
+	_, err := RunEcssParserTest(`div { content: "foo" + "bar"; }`)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Implicit string concatenation is not allowed")
+
+	_, err = RunEcssParserTest(`div { width: 10px + 2px; }`)
+	assert.Nil(t, err)
+
+	_, err = NewParser(NewContext()).Parse(`div { content: "foo" + "bar"; }`, ScssFileType)
+	assert.Nil(t, err)
+}
+
+func TestEcssImportOnce(t *testing.T) {
+	var dir = writeImportFiles(t, map[string]string{
+		"main.ecss":     `@import-once "vars"; @import "buttons"; @import-once "vars";`,
+		"_vars.scss":    `v { color: red; }`,
+		"_buttons.ecss": `@import-once "vars"; b { color: blue; }`,
+	})
+	defer os.RemoveAll(dir)
+
+	var parser = NewParser(NewContext())
+	stmts, err := parser.ParseFile(filepath.Join(dir, "main.ecss"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"v", "b"}, rulesetSelectors(stmts))
+	assert.True(t, parser.HasImportOnce)
+
+	// the files using @import-once are not cached
+	var fileAstMap = NewFileAstMap()
+	fileAst, err := fileAstMap.ParseFile(NewContext(), filepath.Join(dir, "main.ecss"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"v", "b"}, rulesetSelectors(fileAst.Statements))
+	_, ok := fileAstMap.Get(filepath.Join(dir, "main.ecss"))
+	assert.False(t, ok)
+}
+
+func TestStringConcatInDialects(t *testing.T) {
+	var code = `$name: "icon"; div { content: "foo-" + $name; }`
+
+	css, err := evaluateScss(code)
+	assert.Nil(t, err)
+	assert.Equal(t, "div { content: \"foo-icon\"; }\n", css)
+
+	_, err = RunEcssParserTest(code)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "Implicit string concatenation is not allowed")
+	}
+
+	// the interpolation is the ECSS way to concatenate the strings
+	stmts, err := RunEcssParserTest(`$name: "icon"; div { content: "foo-#{$name}"; }`)
+	assert.Nil(t, err)
+	var interpreter = runtime.NewInterpreter()
+	interpreter.ParseSelectors = ParseSelectorGroup
+	stmts, err = interpreter.EvaluateStatements(stmts)
+	assert.Nil(t, err)
+	var buf bytes.Buffer
+	assert.Nil(t, compiler.NewCompiler(&buf, compiler.CompactStyle).CompileStatements(stmts))
+	assert.Equal(t, "div { content: \"foo-icon\"; }\n", buf.String())
+}
@@ -0,0 +1,83 @@
+package c6
+
+import "c6/ast"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestParserEachStatement(t *testing.T) {
+	var stmts = RunParserTest(`@each $key, $value in (primary: #333, size: 1px 2px) { .a { color: $value; } }`)
+	assert.Equal(t, 1, len(stmts))
+
+	each, ok := stmts[0].(*ast.EachStatement)
+	assert.True(t, ok)
+	assert.Equal(t, 2, len(each.Variables))
+	assert.Equal(t, "$key", each.Variables[0].Name)
+	assert.Equal(t, "$value", each.Variables[1].Name)
+
+	mapValue, ok := each.List.(*ast.Map)
+	assert.True(t, ok)
+	assert.Equal(t, 2, mapValue.Len())
+	assert.Equal(t, "1px 2px", mapValue.Values[1].String())
+	assert.Equal(t, 1, len(each.Block.Statements))
+}
+
+func TestParserEachStatementWithProperties(t *testing.T) {
+	var stmts = RunParserTest(`.a { @each $side in top, left { margin: 0; } }`)
+	ruleset, ok := stmts[0].(*ast.RuleSet)
+	assert.True(t, ok)
+
+	each, ok := ruleset.Block.Statements[0].(*ast.EachStatement)
+	assert.True(t, ok)
+	assert.IsType(t, &ast.List{}, each.List)
+	assert.IsType(t, &ast.Property{}, each.Block.Statements[0])
+}
+
+func TestEachOverList(t *testing.T) {
+	css, err := evaluateScss(`
+	$sizes: 1px 2px;
+	@each $color in red, blue { .a { color: $color; } }
+	.b { @each $size in $sizes { margin: $size * 2; } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { color: red; }\n\n.a { color: blue; }\n\n"+
+		".b { margin: 2px; margin: 4px; }\n", css)
+}
+
+func TestEachOverMap(t *testing.T) {
+	css, err := evaluateScss(`
+	$breakpoints: (small: 576px, medium: 768px);
+	.a { @each $name, $width in $breakpoints { content: $name; width: $width; } }
+	.b { @each $pair in (x: 1, y: 2) { content: $pair; } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { content: small; width: 576px; content: medium; width: 768px; }\n\n"+
+		".b { content: x 1; content: y 2; }\n", css)
+}
+
+func TestEachWithDestructuring(t *testing.T) {
+	css, err := evaluateScss(`
+	$icons: home "\f101" 12px, search "\f102" 14px;
+	.icon { @each $name, $glyph, $size in $icons { .i { content: $glyph; font-size: $size; } } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".icon .i { content: \"\\f101\"; font-size: 12px; }\n"+
+		".icon .i { content: \"\\f102\"; font-size: 14px; }\n", css)
+}
+
+func TestEachWithDestructuringPadsNull(t *testing.T) {
+	css, err := evaluateScss(`
+	$icons: home "\f101" 12px, search "\f102";
+	@each $name, $glyph, $size in $icons { .icon-#{$name} { content: $glyph; font-size: $size; } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".icon-home { content: \"\\f101\"; font-size: 12px; }\n\n"+
+		".icon-search { content: \"\\f102\"; }\n", css)
+}
+
+func TestEachInsideFunction(t *testing.T) {
+	css, err := evaluateScss(`
+	@function sum($numbers) {
+		$total: 0;
+		@each $n in $numbers { $total: $total + $n; }
+		@return $total;
+	}
+	.a { width: sum(1px 2px 3px); }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { width: 6px; }\n", css)
+}
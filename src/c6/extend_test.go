@@ -0,0 +1,107 @@
+package c6
+
+import "c6/ast"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestParserExtendStatement(t *testing.T) {
+	var stmts = RunParserTest(`.error { @extend a.message:hover !optional; }`)
+	ruleset, ok := stmts[0].(*ast.RuleSet)
+	assert.True(t, ok)
+
+	extend, ok := ruleset.Block.Statements[0].(*ast.ExtendStatement)
+	assert.True(t, ok)
+	assert.Equal(t, 3, len(extend.Selectors))
+	assert.Equal(t, "a.message:hover", extend.Selectors.String())
+	assert.True(t, extend.Optional)
+}
+
+func TestParserPlaceholderSelector(t *testing.T) {
+	var stmts = RunParserTest(`%hidden { clip: auto; }`)
+	ruleset, ok := stmts[0].(*ast.RuleSet)
+	assert.True(t, ok)
+	assert.IsType(t, &ast.PlaceholderSelector{}, ruleset.Selectors[0][0])
+	assert.True(t, ruleset.Selectors[0].HasPlaceholder())
+}
+
+func TestExtendClassSelector(t *testing.T) {
+	css, err := evaluateScss(`
+	.message { border: 1px solid; }
+	.message:hover { color: blue; }
+	.error { @extend .message; color: red; }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".message, .error { border: 1px solid; }\n\n"+
+		".message:hover, .error:hover { color: blue; }\n\n"+
+		".error { color: red; }\n", css)
+}
+
+func TestExtendPlaceholderSelector(t *testing.T) {
+	css, err := evaluateScss(`
+	%visually-hidden { clip: rect(0 0 0 0); }
+	%unused { color: red; }
+	.sr-only { @extend %visually-hidden; }
+	.skip-link { @extend %visually-hidden; }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".sr-only, .skip-link { clip: rect(0 0 0 0); }\n", css)
+}
+
+func TestExtendCompoundAndComplexSelectors(t *testing.T) {
+	css, err := evaluateScss(`
+	.nav a.link:hover { color: red; }
+	.menu > .item { @extend .link; }
+	span.button { @extend .link; }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".nav a.link:hover, .nav .menu > a.item:hover { color: red; }\n", css)
+}
+
+func TestExtendChainedAndNestedRuleSets(t *testing.T) {
+	css, err := evaluateScss(`
+	.box { .title { margin: 0; } }
+	.card { @extend .box; }
+	.panel { @extend .card; }
+	.sidebar { .widget { @extend .title; } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".box .title, .card .title, .panel .title, "+
+		".box .sidebar .widget, .sidebar .box .widget, .card .sidebar .widget, .sidebar .card .widget, "+
+		".panel .sidebar .widget, .sidebar .panel .widget { margin: 0; }\n", css)
+}
+
+func TestExtendWeavesAncestors(t *testing.T) {
+	css, err := evaluateScss(`
+	.c .x { y: 1; }
+	.d { .x { z: 1; } }
+	.e > .x { w: 1; }
+	.a .b { @extend .x; }
+	.h > .i > .j { @extend .x; }
+	.f > .g { @extend .x; }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".c .x, .c .a .b, .a .c .b, .c .h > .i > .j, .c .f > .g { y: 1; }\n\n"+
+		".d .x, .d .a .b, .a .d .b, .d .h > .i > .j, .d .f > .g { z: 1; }\n\n"+
+		".e > .x, .a .e > .b, .h > .e.i > .j, .e.f > .g { w: 1; }\n", css)
+}
+
+func TestExtendMixinWithPlaceholder(t *testing.T) {
+	css, err := evaluateScss(`
+	%block { display: block; }
+	@mixin block { @extend %block; }
+	.a { @include block; color: red; }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { display: block; }\n\n.a { color: red; }\n", css)
+}
+
+func TestExtendErrors(t *testing.T) {
+	var cases = map[string]string{
+		`.a { @extend .missing; }`: "The target selector '.missing' of @extend was not found",
+		`@extend .a; .a { b: c; }`: "@extend may only be used within rulesets",
+		`.a { @extend .b .c; }`:    "Can't extend the complex selector",
+	}
+	for code, message := range cases {
+		_, err := evaluateScss(code)
+		if assert.NotNil(t, err, code) {
+			assert.Contains(t, err.Error(), message)
+		}
+	}
+
+	_, err := evaluateScss(`.a { @extend .missing !optional; }`)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,107 @@
+package c6
+
+import "c6/ast"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestParserFunctionStatement(t *testing.T) {
+	var stmts = RunParserTest(`@function space($n, $base: 4px) { $size: $n * $base; @return $size; }`)
+	assert.Equal(t, 1, len(stmts))
+
+	fn, ok := stmts[0].(*ast.FunctionStatement)
+	assert.True(t, ok)
+	assert.Equal(t, "space", fn.Name)
+	assert.Equal(t, 2, len(fn.Parameters))
+	assert.Equal(t, "4px", fn.Parameters[1].Default.String())
+	assert.Equal(t, 2, len(fn.Block.Statements))
+
+	ret, ok := fn.Block.Statements[1].(*ast.ReturnStatement)
+	assert.True(t, ok)
+	assert.Equal(t, "$size", ret.Value.String())
+}
+
+func TestFunctionWithDefaultAndKeywordArguments(t *testing.T) {
+	css, err := evaluateScss(`
+	$base: 4px;
+	@function space($n, $gutter: 0px) {
+		$size: $n * $base;
+		@return $size + $gutter;
+	}
+	.a { margin: space(2); padding: space($gutter: 1px, $n: 3) space(1); }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { margin: 8px; padding: 13px 4px; }\n", css)
+}
+
+func TestFunctionWithControlStatements(t *testing.T) {
+	css, err := evaluateScss(`
+	@function sum($from, $to) {
+		$total: 0;
+		@for $i from $from through $to { $total: $total + $i; }
+		@return $total;
+	}
+	@function halve($n) {
+		$count: 0;
+		@while $n > 1 { $n: $n / 2; $count: $count + 1; }
+		@return $count;
+	}
+	@function factorial($n) {
+		@if $n <= 1 { @return 1; }
+		@return $n * factorial($n - 1);
+	}
+	.a { z-index: sum(1, 4); order: halve(16); line-height: factorial(4); }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { z-index: 10; order: 4; line-height: 24; }\n", css)
+}
+
+func TestFunctionLocalScope(t *testing.T) {
+	css, err := evaluateScss(`
+	$size: 10px;
+	@function scale($n) { $size: $n * 2; @return $size; }
+	.a { width: scale(3px); height: $size; }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { width: 6px; height: 10px; }\n", css)
+}
+
+func TestFunctionLeftAssociativeArithmetic(t *testing.T) {
+	css, err := evaluateScss(`
+	@function rem($px) { @return $px / 16px * 1rem; }
+	@function mul-div($a) { @return $a / 2 * 3; }
+	@function div-div($a) { @return $a / 10 / 2; }
+	@function grouped($px) { @return (32px / $px) * 1rem; }
+	.a { width: rem(32px); height: mul-div(12); order: div-div(100); margin: grouped(16px); }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { width: 2rem; height: 18; order: 5; margin: 2rem; }\n", css)
+}
+
+func TestSlashSeparatedNumbers(t *testing.T) {
+	css, err := evaluateScss(`.a { font: 12px/1.5; b: 100/10/2; c: (100/10/2); d: (32px / 16px) * 1rem; }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { font: 12px/1.5; b: 100/10/2; c: 5; d: 2rem; }\n", css)
+}
+
+func TestFunctionCallOfCSSFunction(t *testing.T) {
+	css, err := evaluateScss(`
+	$alpha: 0.5;
+	.a { color: rgba(0, 0, 0, $alpha); width: calc(100% - 10px); }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { color: rgba(0, 0, 0, 0.5); width: calc(100% - 10px); }\n", css)
+}
+
+func TestFunctionErrors(t *testing.T) {
+	var cases = map[string]string{
+		`@function f($a) { $b: $a; } .a { width: f(1); }`:                    "Function 'f' finished without @return",
+		`@function f($a) { @return $a; } .a { width: f(); }`:                 "Missing argument $a of function 'f'",
+		`@function f($a) { @return $a; } .a { width: f($b: 1); }`:            "No argument named $b for function 'f'",
+		`@function f($a) { @return f($a); } .a { width: f(1); }`:             "Too many nested calls of function 'f'",
+		`@function f() { .b { color: red; } @return 1; } .a { width: f(); }`: "Function 'f' can only contain",
+		`.a { @return 1; width: 1px; }`:                                      "This at-rule is not allowed here",
+		`@return 1;`:                                                         "This at-rule is not allowed here",
+		`@mixin m() { @return 1; } .a { @include m(); }`:                     "This at-rule is not allowed here",
+	}
+	for code, message := range cases {
+		_, err := evaluateScss(code)
+		if assert.NotNil(t, err, code) {
+			assert.Contains(t, err.Error(), message)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package c6
+
+import "c6/ast"
+import "fmt"
+import "os"
+import "path/filepath"
+import "strings"
+
+/*
+IsCssImport returns true if the @import statement is a plain CSS import,
+which is kept in the output instead of importing the file:
+
+	@import url(foo.css);
+	@import "http://fonts.googleapis.com/css?family=Droid+Sans";
+	@import "foo.css";
+	@import "foo" screen;
+*/
+func IsCssImport(stm *ast.ImportStatement) bool {
+	if len(stm.MediaQueryList) > 0 {
+		return true
+	}
+	switch url := stm.Url.(type) {
+	case ast.Url:
+		return true
+	case ast.RelativeUrl:
+		var path = string(url)
+		return strings.HasPrefix(path, "http://") ||
+			strings.HasPrefix(path, "https://") ||
+			strings.HasPrefix(path, "//") ||
+			strings.HasSuffix(path, ".css")
+	}
+	return true
+}
+
+// the extensions of the files could be imported or compiled
+var SourceExtensions = []string{".scss", ".sass", ".ecss"}
+
+func IsSourceFile(path string) bool {
+	var ext = filepath.Ext(path)
+	for _, sourceExt := range SourceExtensions {
+		if ext == sourceExt {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ImportCandidates returns the file names to try for the import path in the
+directory, the partial file name goes first, then the index file of the
+directory. The extensions are tried in the order of SourceExtensions:
+
+	"foo/bar" => "foo/_bar.scss", "foo/_bar.sass", "foo/_bar.ecss", "foo/bar.scss", ...
+	             "foo/bar/_index.scss", ..., "foo/bar/index.scss", ...
+*/
+func ImportCandidates(dir string, importPath string) []string {
+	var candidates = []string{}
+	for _, group := range importCandidateGroups(dir, importPath) {
+		candidates = append(candidates, group...)
+	}
+	return candidates
+}
+
+/*
+importCandidateGroups returns the candidates of the import path grouped by
+the file and the index file of the directory, more than one existing file
+in a group makes the import ambiguous.
+*/
+func importCandidateGroups(dir string, importPath string) [][]string {
+	var base = filepath.Join(dir, importPath)
+	var partial = filepath.Join(filepath.Dir(base), "_"+filepath.Base(base))
+	if IsSourceFile(importPath) {
+		return [][]string{{partial, base}}
+	}
+	var groups = [][]string{}
+	for _, names := range [][]string{
+		{partial, base},
+		{filepath.Join(base, "_index"), filepath.Join(base, "index")},
+	} {
+		var group = []string{}
+		for _, name := range names {
+			for _, ext := range SourceExtensions {
+				group = append(group, name+ext)
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+/*
+findImportCandidate returns the existing candidate of the import path in the
+directory, it panics if more than one file could be imported, e.g. both
+"_foo.scss" and "foo.scss" exist.
+*/
+func findImportCandidate(dir string, importPath string, exists func(string) bool) (string, bool) {
+	for _, group := range importCandidateGroups(dir, importPath) {
+		var found = []string{}
+		for _, candidate := range group {
+			if exists(candidate) {
+				found = append(found, candidate)
+			}
+		}
+		if len(found) > 1 {
+			panic(fmt.Errorf("It's not clear which file to import for '@import \"%s\"', found: %s",
+				importPath, strings.Join(found, ", ")))
+		}
+		if len(found) == 1 {
+			return found[0], true
+		}
+	}
+	return "", false
+}
+
+/*
+ResolveImportPath finds the file of the import path. The directory of the
+importing file is searched first, then the load paths. It panics if the
+import path is ambiguous in a directory.
+*/
+func ResolveImportPath(importingFile string, importPath string, loadPaths []string) (string, bool) {
+	var dirs = []string{filepath.Dir(importingFile)}
+	dirs = append(dirs, loadPaths...)
+	var exists = func(candidate string) bool {
+		info, err := os.Stat(candidate)
+		return err == nil && !info.IsDir()
+	}
+	for _, dir := range dirs {
+		if path, found := findImportCandidate(dir, importPath, exists); found {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+/*
+importers returns the importers to resolve the imports of the current file,
+the importer which loaded the current file goes first, then the importers of
+the context, the load paths of the context are searched at last.
+*/
+func (parser *Parser) importers() []Importer {
+	var fileSystemImporter = NewFileSystemImporter(parser.Context.LoadPaths)
+	var importers = []Importer{}
+	if parser.Importer != nil {
+		importers = append(importers, parser.Importer)
+	} else {
+		importers = append(importers, fileSystemImporter)
+	}
+	importers = append(importers, parser.Context.Importers...)
+	return append(importers, fileSystemImporter)
+}
+
+/*
+ImportFile resolves the url of the import statement by the importers and
+parses the imported file into the Statements of the import statement. The
+files on the disk are parsed via the FileAstMap of the context if it's set.
+*/
+func (parser *Parser) ImportFile(stm *ast.ImportStatement) {
+	var url, ok = stm.Url.(ast.RelativeUrl)
+	if !ok {
+		return
+	}
+	var importer Importer
+	var path string
+	for _, candidate := range parser.importers() {
+		if canonical, found := candidate.Canonicalize(string(url), parser.File); found {
+			importer, path = candidate, canonical
+			break
+		}
+	}
+	if importer == nil {
+		panic(fmt.Errorf("File to import not found or unreadable: %s", url))
+	}
+
+	var context = parser.Context
+	if stm.Once {
+		parser.HasImportOnce = true
+		if context.ImportedFiles[path] {
+			stm.Path = path
+			stm.Statements = []ast.Statement{}
+			return
+		}
+	}
+	// the import chain of the loop error starts at the entry file
+	if len(context.ImportStack) == 0 && parser.File != "" {
+		context.ImportStack = []string{filepath.Clean(parser.File)}
+		defer func() {
+			context.ImportStack = nil
+		}()
+	}
+	if path == filepath.Clean(parser.File) {
+		panic(fmt.Errorf("An @import loop has been found: %s imports itself", path))
+	}
+	for _, importing := range context.ImportStack {
+		if importing == path {
+			panic(fmt.Errorf("An @import loop has been found: %s", strings.Join(append(context.ImportStack, path), " imports ")))
+		}
+	}
+	context.ImportStack = append(context.ImportStack, path)
+	defer func() {
+		context.ImportStack = context.ImportStack[:len(context.ImportStack)-1]
+	}()
+
+	var stmts []ast.Statement
+	var imports []string
+	if _, onDisk := importer.(*FileSystemImporter); onDisk && context.FileAstMap != nil {
+		fileAst, err := context.FileAstMap.ParseFile(context, path)
+		if err != nil {
+			panic(err)
+		}
+		stmts = fileAst.Statements
+		imports = fileAst.Imports
+		parser.HasImportOnce = parser.HasImportOnce || fileAst.HasImportOnce
+	} else {
+		code, err := importer.Load(path)
+		if err != nil {
+			panic(fmt.Errorf("File to import not found or unreadable: %s: %s", url, err))
+		}
+		var importParser = NewParser(context)
+		importParser.File = path
+		importParser.Importer = importer
+		if stmts, err = importParser.Parse(code, getFileTypeByExtension(filepath.Ext(path))); err != nil {
+			panic(err)
+		}
+		imports = importParser.Imports
+		parser.HasImportOnce = parser.HasImportOnce || importParser.HasImportOnce
+	}
+
+	stm.Path = path
+	stm.Statements = append([]ast.Statement{}, stmts...)
+	parser.Imports = append(parser.Imports, path)
+	parser.Imports = append(parser.Imports, imports...)
+	context.ImportedFiles[path] = true
+	for _, imported := range imports {
+		context.ImportedFiles[imported] = true
+	}
+}
@@ -0,0 +1,223 @@
+package c6
+
+import "c6/ast"
+import "c6/compiler"
+import "bytes"
+import "io/ioutil"
+import "os"
+import "path/filepath"
+import "strings"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestIsCssImport(t *testing.T) {
+	var stm = ast.NewImportStatement()
+	stm.Url = ast.Url("foo")
+	assert.True(t, IsCssImport(stm))
+	stm.Url = ast.RelativeUrl("foo.css")
+	assert.True(t, IsCssImport(stm))
+	stm.Url = ast.RelativeUrl("http://fonts.googleapis.com/css?family=Droid+Sans")
+	assert.True(t, IsCssImport(stm))
+	stm.Url = ast.RelativeUrl("foo")
+	assert.False(t, IsCssImport(stm))
+	stm.Url = ast.RelativeUrl("foo.scss")
+	assert.False(t, IsCssImport(stm))
+	stm.MediaQueryList = []*ast.MediaQuery{ast.NewMediaQuery(nil, nil)}
+	assert.True(t, IsCssImport(stm))
+}
+
+func TestImportCandidates(t *testing.T) {
+	assert.Equal(t, []string{
+		"dir/foo/_bar.scss", "dir/foo/_bar.sass", "dir/foo/_bar.ecss",
+		"dir/foo/bar.scss", "dir/foo/bar.sass", "dir/foo/bar.ecss",
+		"dir/foo/bar/_index.scss", "dir/foo/bar/_index.sass", "dir/foo/bar/_index.ecss",
+		"dir/foo/bar/index.scss", "dir/foo/bar/index.sass", "dir/foo/bar/index.ecss",
+	}, ImportCandidates("dir", "foo/bar"))
+	assert.Equal(t, []string{"dir/_bar.scss", "dir/bar.scss"}, ImportCandidates("dir", "bar.scss"))
+	assert.Equal(t, []string{"dir/_bar.sass", "dir/bar.sass"}, ImportCandidates("dir", "bar.sass"))
+}
+
+func TestResolveImportPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	os.MkdirAll(filepath.Join(dir, "src"), 0755)
+	os.MkdirAll(filepath.Join(dir, "lib"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "src", "_partial.scss"), []byte(""), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "src", "plain.scss"), []byte(""), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "lib", "_partial.scss"), []byte(""), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "lib", "_vendor.scss"), []byte(""), 0644)
+
+	var main = filepath.Join(dir, "src", "main.scss")
+	var loadPaths = []string{filepath.Join(dir, "lib")}
+
+	path, ok := ResolveImportPath(main, "partial", loadPaths)
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "src", "_partial.scss"), path)
+
+	path, ok = ResolveImportPath(main, "plain", loadPaths)
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "src", "plain.scss"), path)
+
+	path, ok = ResolveImportPath(main, "vendor", loadPaths)
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "lib", "_vendor.scss"), path)
+
+	_, ok = ResolveImportPath(main, "missing", loadPaths)
+	assert.False(t, ok)
+}
+
+func writeImportFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "c6")
+	assert.Nil(t, err)
+	for name, content := range files {
+		var path = filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestParserImportFile(t *testing.T) {
+	var dir = writeImportFiles(t, map[string]string{
+		"src/main.scss":          `@import "partial", "theme", "vendor"; a { color: red; }`,
+		"src/_partial.scss":      `b { color: blue; }`,
+		"src/theme/_index.scss":  `@import "colors"; i { color: green; }`,
+		"src/theme/_colors.scss": `c { color: white; }`,
+		"lib/_vendor.scss":       `v { float: left; }`,
+	})
+	defer os.RemoveAll(dir)
+
+	var context = NewContext()
+	context.LoadPaths = []string{filepath.Join(dir, "lib")}
+	var parser = NewParser(context)
+	stmts, err := parser.ParseFile(filepath.Join(dir, "src", "main.scss"))
+	assert.Nil(t, err)
+
+	var selectors = []string{}
+	for _, stm := range stmts {
+		ruleset, ok := stm.(*ast.RuleSet)
+		assert.True(t, ok)
+		selectors = append(selectors, ruleset.Selectors[0].String())
+	}
+	assert.Equal(t, []string{"b", "c", "i", "v", "a"}, selectors)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "src", "_partial.scss"),
+		filepath.Join(dir, "src", "theme", "_index.scss"),
+		filepath.Join(dir, "src", "theme", "_colors.scss"),
+		filepath.Join(dir, "lib", "_vendor.scss"),
+	}, parser.Imports)
+}
+
+func TestParserImportSassFile(t *testing.T) {
+	var dir = writeImportFiles(t, map[string]string{
+		"main.scss":    `@import "legacy"; a { color: red; }`,
+		"_legacy.sass": "b\n  color: blue\n",
+	})
+	defer os.RemoveAll(dir)
+
+	stmts, err := NewParser(NewContext()).ParseFile(filepath.Join(dir, "main.scss"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(stmts))
+	assert.Equal(t, "b", stmts[0].(*ast.RuleSet).Selectors[0].String())
+}
+
+func TestParserImportFileKeepsCssImport(t *testing.T) {
+	var dir = writeImportFiles(t, map[string]string{
+		"main.scss": `@import url(foo); @import "foo.css"; @import "http://foo.com/bar"; @import "print" print;`,
+	})
+	defer os.RemoveAll(dir)
+
+	stmts, err := NewParser(NewContext()).ParseFile(filepath.Join(dir, "main.scss"))
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(stmts))
+	for _, stm := range stmts {
+		importStm, ok := stm.(*ast.ImportStatement)
+		assert.True(t, ok)
+		assert.Nil(t, importStm.Statements)
+	}
+}
+
+func TestParserImportFileInRuleSet(t *testing.T) {
+	var dir = writeImportFiles(t, map[string]string{
+		"main.scss":    `div { @import "nested"; }`,
+		"_nested.scss": `span { float: left; } em { color: red; }`,
+	})
+	defer os.RemoveAll(dir)
+
+	stmts, err := NewParser(NewContext()).ParseFile(filepath.Join(dir, "main.scss"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(stmts))
+	var ruleset = stmts[0].(*ast.RuleSet)
+	assert.Equal(t, 2, len(ruleset.Block.SubRuleSets))
+}
+
+func TestParserImportFileNotFound(t *testing.T) {
+	var dir = writeImportFiles(t, map[string]string{
+		"main.scss": "a { color: red; }\n@import \"missing\";",
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := NewParser(NewContext()).ParseFile(filepath.Join(dir, "main.scss"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "main.scss:2: File to import not found or unreadable: missing")
+}
+
+func TestParserImportFileLoop(t *testing.T) {
+	var dir = writeImportFiles(t, map[string]string{
+		"main.scss": `@import "a";`,
+		"_a.scss":   `@import "b";`,
+		"_b.scss":   `@import "a";`,
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := NewParser(NewContext()).ParseFile(filepath.Join(dir, "main.scss"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "An @import loop has been found: "+strings.Join([]string{
+		filepath.Join(dir, "main.scss"), filepath.Join(dir, "_a.scss"),
+		filepath.Join(dir, "_b.scss"), filepath.Join(dir, "_a.scss"),
+	}, " imports "))
+}
+
+func TestParserImportFileLoopToEntryFile(t *testing.T) {
+	var dir = writeImportFiles(t, map[string]string{
+		"main.scss": `@import "a";`,
+		"_a.scss":   `@import "main";`,
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := NewParser(NewContext()).ParseFile(filepath.Join(dir, "main.scss"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "An @import loop has been found: "+
+		filepath.Join(dir, "main.scss")+" imports "+filepath.Join(dir, "_a.scss")+" imports "+filepath.Join(dir, "main.scss"))
+}
+
+func TestParserImportFileAmbiguous(t *testing.T) {
+	var dir = writeImportFiles(t, map[string]string{
+		"main.scss": "a { color: red; }\n@import \"dup\";",
+		"_dup.scss": `b { color: blue; }`,
+		"dup.scss":  `c { color: white; }`,
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := NewParser(NewContext()).ParseFile(filepath.Join(dir, "main.scss"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "main.scss:2: It's not clear which file to import for '@import \"dup\"', found: "+
+		filepath.Join(dir, "_dup.scss")+", "+filepath.Join(dir, "dup.scss"))
+}
+
+func TestParserImportFileHoistsCssImports(t *testing.T) {
+	var dir = writeImportFiles(t, map[string]string{
+		"main.scss":   "@charset \"UTF-8\";\na { color: red; }\n@import \"theme\";",
+		"_theme.scss": `b { color: blue; } @import "foo.css"; @import url(bar.css);`,
+	})
+	defer os.RemoveAll(dir)
+
+	stmts, err := NewParser(NewContext()).ParseFile(filepath.Join(dir, "main.scss"))
+	assert.Nil(t, err)
+	var buf bytes.Buffer
+	assert.Nil(t, compiler.NewCompiler(&buf, compiler.CompactStyle).CompileStatements(stmts))
+	assert.Equal(t, "@charset \"UTF-8\";\n@import \"foo.css\";\n@import url(bar.css);\n"+
+		"a { color: red; }\n\nb { color: blue; }\n", buf.String())
+}
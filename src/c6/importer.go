@@ -0,0 +1,100 @@
+package c6
+
+import "io/fs"
+import "io/ioutil"
+import "path"
+import "path/filepath"
+
+/*
+Importer loads the files of the @import rules, the host program could serve
+the imports from a virtual file system, e.g. embed.FS or the files generated
+at runtime.
+
+Canonicalize resolves the import url of the importing file to the canonical
+url of the file, it returns false if the importer can't find the file. The
+canonical url identifies the file, it's used as the file name of the error
+messages and the source map.
+
+Load returns the content of the file of the canonical url, the syntax is
+decided by the extension of the canonical url.
+*/
+type Importer interface {
+	Canonicalize(url string, from string) (string, bool)
+	Load(canonical string) (string, error)
+}
+
+/*
+FileSystemImporter imports the files from the disk, the directory of the
+importing file is searched first, then the load paths.
+*/
+type FileSystemImporter struct {
+	LoadPaths []string
+}
+
+func NewFileSystemImporter(loadPaths []string) *FileSystemImporter {
+	return &FileSystemImporter{LoadPaths: loadPaths}
+}
+
+func (self *FileSystemImporter) Canonicalize(url string, from string) (string, bool) {
+	if resolved, found := ResolveImportPath(from, url, self.LoadPaths); found {
+		return filepath.Clean(resolved), true
+	}
+	return "", false
+}
+
+func (self *FileSystemImporter) Load(canonical string) (string, error) {
+	data, err := ioutil.ReadFile(canonical)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+/*
+FSImporter imports the files from the fs.FS, the canonical urls are the
+slash-separated paths in the fs.FS. The directory of the importing file is
+searched first if the importing file is in the fs.FS, then the load paths,
+the root directory is searched when there is no load path.
+*/
+type FSImporter struct {
+	FS        fs.FS
+	LoadPaths []string
+}
+
+func NewFSImporter(fsys fs.FS, loadPaths ...string) *FSImporter {
+	return &FSImporter{FS: fsys, LoadPaths: loadPaths}
+}
+
+func (self *FSImporter) Canonicalize(url string, from string) (string, bool) {
+	var dirs = []string{path.Dir(filepath.ToSlash(from))}
+	if len(self.LoadPaths) == 0 {
+		dirs = append(dirs, ".")
+	} else {
+		dirs = append(dirs, self.LoadPaths...)
+	}
+	var exists = func(candidate string) bool {
+		candidate = filepath.ToSlash(candidate)
+		if !fs.ValidPath(candidate) {
+			return false
+		}
+		info, err := fs.Stat(self.FS, candidate)
+		return err == nil && !info.IsDir()
+	}
+	for _, dir := range dirs {
+		if !fs.ValidPath(dir) {
+			continue
+		}
+		if candidate, found := findImportCandidate(dir, url, exists); found {
+			return filepath.ToSlash(candidate), true
+		}
+	}
+	return "", false
+}
+
+func (self *FSImporter) Load(canonical string) (string, error) {
+	data, err := fs.ReadFile(self.FS, canonical)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
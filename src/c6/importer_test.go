@@ -0,0 +1,126 @@
+package c6
+
+import "c6/ast"
+import "fmt"
+import "io/ioutil"
+import "os"
+import "path/filepath"
+import "testing"
+import "testing/fstest"
+import "github.com/stretchr/testify/assert"
+
+// tenantImporter serves the generated variables of the tenant
+type tenantImporter struct {
+	Colors map[string]string
+}
+
+func (self *tenantImporter) Canonicalize(url string, from string) (string, bool) {
+	if _, ok := self.Colors[url]; ok {
+		return "tenant:" + url + ".scss", true
+	}
+	return "", false
+}
+
+func (self *tenantImporter) Load(canonical string) (string, error) {
+	var url = canonical[len("tenant:") : len(canonical)-len(".scss")]
+	return fmt.Sprintf(".brand { color: %s; }", self.Colors[url]), nil
+}
+
+func rulesetSelectors(stmts []ast.Statement) []string {
+	var selectors = []string{}
+	for _, stm := range stmts {
+		if ruleset, ok := stm.(*ast.RuleSet); ok {
+			selectors = append(selectors, ruleset.Selectors[0].String())
+		}
+	}
+	return selectors
+}
+
+func TestFSImporter(t *testing.T) {
+	var fsys = fstest.MapFS{
+		"theme/_index.scss":  {Data: []byte(`@import "colors"; i { color: red; }`)},
+		"theme/_colors.scss": {Data: []byte(`c { color: blue; }`)},
+		"_base.scss":         {Data: []byte(`b { color: white; }`)},
+	}
+	var importer = NewFSImporter(fsys)
+
+	canonical, ok := importer.Canonicalize("theme", "main.scss")
+	assert.True(t, ok)
+	assert.Equal(t, "theme/_index.scss", canonical)
+
+	canonical, ok = importer.Canonicalize("base", "/home/foo/main.scss")
+	assert.True(t, ok)
+	assert.Equal(t, "_base.scss", canonical)
+
+	canonical, ok = importer.Canonicalize("../base", "theme/_index.scss")
+	assert.True(t, ok)
+	assert.Equal(t, "_base.scss", canonical)
+
+	// the paths outside of the fs.FS
+	_, ok = importer.Canonicalize("../base", "main.scss")
+	assert.False(t, ok)
+
+	code, err := importer.Load("_base.scss")
+	assert.Nil(t, err)
+	assert.Equal(t, `b { color: white; }`, code)
+
+	var context = NewContext()
+	context.Importers = []Importer{importer}
+	var parser = NewParser(context)
+	stmts, err := parser.Parse(`@import "theme", "base"; a { color: red; }`, ScssFileType)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"c", "i", "b", "a"}, rulesetSelectors(stmts))
+	assert.Equal(t, []string{"theme/_index.scss", "theme/_colors.scss", "_base.scss"}, parser.Imports)
+}
+
+func TestFSImporterWithLoadPaths(t *testing.T) {
+	var fsys = fstest.MapFS{
+		"vendor/_grid.scss": {Data: []byte(`g { float: left; }`)},
+	}
+	canonical, ok := NewFSImporter(fsys, "vendor").Canonicalize("grid", "main.scss")
+	assert.True(t, ok)
+	assert.Equal(t, "vendor/_grid.scss", canonical)
+
+	_, ok = NewFSImporter(fsys).Canonicalize("grid", "main.scss")
+	assert.False(t, ok)
+}
+
+func TestCustomImporter(t *testing.T) {
+	var context = NewContext()
+	context.Importers = []Importer{&tenantImporter{Colors: map[string]string{"tenant/vars": "red"}}}
+	var parser = NewParser(context)
+	stmts, err := parser.Parse(`@import "tenant/vars";`, ScssFileType)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{".brand"}, rulesetSelectors(stmts))
+	assert.Equal(t, []string{"tenant:tenant/vars.scss"}, parser.Imports)
+
+	_, err = NewParser(context).Parse(`@import "tenant/missing";`, ScssFileType)
+	assert.NotNil(t, err)
+}
+
+func TestImporterErrorFile(t *testing.T) {
+	var fsys = fstest.MapFS{
+		"_broken.scss": {Data: []byte("a { color: red; }\nb { color: red;")},
+	}
+	var context = NewContext()
+	context.Importers = []Importer{NewFSImporter(fsys)}
+	_, err := NewParser(context).Parse(`@import "broken";`, ScssFileType)
+	assert.NotNil(t, err)
+	assert.Equal(t, "_broken.scss", err.(*ParseError).File)
+}
+
+func TestFileSystemImporterGoesFirst(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "main.scss"), []byte(`@import "base";`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "_base.scss"), []byte(`disk { color: red; }`), 0644)
+
+	var context = NewContext()
+	context.Importers = []Importer{NewFSImporter(fstest.MapFS{
+		"_base.scss": {Data: []byte(`virtual { color: red; }`)},
+	})}
+	stmts, err := NewParser(context).ParseFile(filepath.Join(dir, "main.scss"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"disk"}, rulesetSelectors(stmts))
+}
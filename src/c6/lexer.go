@@ -9,6 +9,7 @@ import "unicode/utf8"
 import "strings"
 import "fmt"
 import "unicode"
+import "sort"
 import "c6/ast"
 
 const TOKEN_CHANNEL_BUFFER = 1024
@@ -50,6 +51,42 @@ type Lexer struct {
 	Output chan *ast.Token
 
 	Tokens []ast.Token
+
+	// the offsets of the line beginnings, built on the first lookup.
+	lineOffsets []int
+
+	// line => the width inserted before the content of the line when the
+	// input is converted from the indented syntax.
+	columnShifts map[int]int
+
+	// the language features of the dialect
+	Dialect DialectFlags
+}
+
+/*
+position returns the line and the column of the offset, both of them start
+from 0.
+*/
+func (l *Lexer) position(offset int) (int, int) {
+	if l.lineOffsets == nil {
+		l.lineOffsets = []int{0}
+		for i := 0; i < len(l.Input); i++ {
+			if l.Input[i] == '\n' {
+				l.lineOffsets = append(l.lineOffsets, i+1)
+			}
+		}
+	}
+	if offset > len(l.Input) {
+		offset = len(l.Input)
+	}
+	var line = sort.SearchInts(l.lineOffsets, offset+1) - 1
+	var column = utf8.RuneCountInString(l.Input[l.lineOffsets[line]:offset])
+	if shift, ok := l.columnShifts[line]; ok {
+		if column -= shift; column < 0 {
+			column = 0
+		}
+	}
+	return line, column
 }
 
 func (l *Lexer) lastToken() *ast.Token {
@@ -256,11 +293,14 @@ func (l *Lexer) emitToken(token *ast.Token) {
 }
 
 func (l *Lexer) createTokenWith0Offset(tokenType ast.TokenType) *ast.Token {
+	var line, column = l.position(l.Start)
 	var token = ast.Token{
-		Type: tokenType,
-		Str:  "",
-		Pos:  l.Start,
-		Line: l.Line,
+		Type:   tokenType,
+		Str:    "",
+		Pos:    l.Start,
+		Line:   line,
+		Column: column,
+		File:   l.File,
 	}
 	return &token
 }
@@ -271,11 +311,14 @@ func (l *Lexer) createToken(tokenType ast.TokenType) *ast.Token {
 			panic(fmt.Sprintf("out of range at '%s': start:%d, offset:%d, length: %d", l.Input[l.Start:], l.Start, l.Offset, len(l.Input)))
 		}
 	*/
+	var line, column = l.position(l.Start)
 	var token = ast.Token{
-		Type: tokenType,
-		Str:  l.Input[l.Start:l.Offset],
-		Pos:  l.Start,
-		Line: l.Line,
+		Type:   tokenType,
+		Str:    l.Input[l.Start:l.Offset],
+		Pos:    l.Start,
+		Line:   line,
+		Column: column,
+		File:   l.File,
 	}
 	return &token
 }
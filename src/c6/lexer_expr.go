@@ -34,6 +34,27 @@ func lexFunctionParams(l *Lexer) stateFn {
 	return nil
 }
 
+/*
+The parameter of url() can be an unquoted string like `url(../images/foo.png)`,
+which is not an expression.
+*/
+func lexUrlParams(l *Lexer) stateFn {
+	l.expect("(")
+	l.emit(ast.T_PAREN_START)
+	l.ignoreSpaces()
+
+	var r = l.peek()
+	if r == '"' || r == '\'' {
+		lexString(l)
+	} else if r != ')' {
+		lexUnquoteStringStopAt(l, ')')
+	}
+	l.ignoreSpaces()
+	l.expect(")")
+	l.emit(ast.T_PAREN_END)
+	return nil
+}
+
 func lexIdentifier(l *Lexer) stateFn {
 	var r = l.next()
 	if !unicode.IsLetter(r) && r != '-' {
@@ -54,7 +75,10 @@ func lexIdentifier(l *Lexer) stateFn {
 	}
 	l.backup()
 
-	if l.peek() == '(' {
+	if l.peek() == '(' && l.current() == "url" {
+		l.emit(ast.T_FUNCTION_NAME)
+		lexUrlParams(l)
+	} else if l.peek() == '(' {
 		l.emit(ast.T_FUNCTION_NAME)
 		lexFunctionParams(l)
 	} else {
@@ -96,6 +120,11 @@ func lexExpression(l *Lexer) stateFn {
 
 		lexIdentifier(l)
 
+	} else if r == '.' && r2 == '.' && l.peekBy(3) == '.' {
+
+		l.match("...")
+		l.emit(ast.T_ELLIPSIS)
+
 	} else if r == '.' && l.peekBy(2) == '.' {
 
 		l.next()
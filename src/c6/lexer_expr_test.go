@@ -104,6 +104,10 @@ func TestLexerExpressionMinus4neg3(t *testing.T) {
 	AssertLexerTokenSequenceFromState(t, `$foo-4--3`, lexExpression, []ast.TokenType{ast.T_VARIABLE, ast.T_MINUS, ast.T_INTEGER, ast.T_MINUS, ast.T_MINUS, ast.T_INTEGER})
 }
 
+func TestLexerExpressionUnitMinusUnit(t *testing.T) {
+	AssertLexerTokenSequenceFromState(t, `3px-1px`, lexExpression, []ast.TokenType{ast.T_INTEGER, ast.T_UNIT_PX, ast.T_MINUS, ast.T_INTEGER, ast.T_UNIT_PX})
+}
+
 func TestLexerExpressionMinus3WithoutSpace(t *testing.T) {
 	AssertLexerTokenSequenceFromState(t, `$foo-3`, lexExpression, []ast.TokenType{ast.T_VARIABLE, ast.T_MINUS, ast.T_INTEGER})
 }
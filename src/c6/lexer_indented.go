@@ -0,0 +1,228 @@
+package c6
+
+import "fmt"
+import "strings"
+
+/*
+The indented syntax (.sass) is lexed by converting the source into SCSS line
+by line, then the SCSS lexer produces the tokens for the parser:
+
+	=rounded($radius)            @mixin rounded($radius) {
+	  border-radius: $radius       border-radius: $radius;
+	.box                         }.box {
+	  +rounded(3px)                @include rounded(3px);
+	  color: red                   color: red;
+	                             }
+
+The lines are kept, so the tokens are at the same lines as the source. The
+closing braces and the expanded shorthands are inserted at the beginning of
+the lines, the inserted widths are recorded to adjust the columns of the
+tokens. The silent "//" comments never go to the output, they are dropped
+and their lines are left blank.
+*/
+type indentedConverter struct {
+	lines []string
+
+	// the output lines
+	output []string
+
+	// the widths of the enclosing indentations
+	indents []int
+
+	// line => the width inserted before the content of the line
+	columnShifts map[int]int
+
+	// the indentation width and the kind ("//" or "/*") of the current
+	// comment, the deeper indented lines belong to the comment.
+	commentIndent int
+	comment       string
+
+	// the output line of the open "/*" comment
+	commentLine int
+}
+
+/*
+ConvertIndentedSyntax converts the indented syntax into SCSS, the widths
+inserted before the content of the lines are returned by the line index.
+*/
+func ConvertIndentedSyntax(code string) (string, map[int]int, error) {
+	var converter = &indentedConverter{
+		lines:        strings.Split(code, "\n"),
+		indents:      []int{0},
+		columnShifts: map[int]int{},
+		commentLine:  -1,
+	}
+	if err := converter.convert(); err != nil {
+		return "", nil, err
+	}
+	return strings.Join(converter.output, "\n"), converter.columnShifts, nil
+}
+
+func splitIndentation(line string) (string, string) {
+	var content = strings.TrimLeft(line, " \t")
+	return line[:len(line)-len(content)], strings.TrimRight(content, " \t\r")
+}
+
+func (self *indentedConverter) convert() error {
+	for idx, line := range self.lines {
+		var indent, content = splitIndentation(line)
+		if content == "" {
+			self.output = append(self.output, line)
+			continue
+		}
+		if strings.Contains(indent, " ") && strings.Contains(indent, "\t") {
+			return &ParseError{Line: idx + 1, Err: fmt.Errorf("Indentation can't use both tabs and spaces")}
+		}
+		var width = len(indent)
+
+		if self.comment != "" {
+			if width > self.commentIndent {
+				self.appendComment(indent, content)
+				continue
+			}
+			self.closeComment()
+		}
+
+		// close the blocks of the deeper indentations
+		var prefix = ""
+		for width < self.indents[len(self.indents)-1] {
+			self.indents = self.indents[:len(self.indents)-1]
+			prefix += "}"
+		}
+		if width != self.indents[len(self.indents)-1] {
+			return &ParseError{Line: idx + 1, Err: fmt.Errorf("Inconsistent indentation")}
+		}
+
+		if strings.HasPrefix(content, "//") || strings.HasPrefix(content, "/*") {
+			self.openComment(width, content)
+			if self.comment == "//" {
+				self.output = append(self.output, prefix)
+				continue
+			}
+			self.columnShifts[idx] = len(prefix)
+			self.output = append(self.output, prefix+indent+content)
+			continue
+		}
+
+		var code = stripTrailingComment(content)
+		var expanded = expandShorthand(code)
+		var shift = len(prefix) + len(expanded) - len(code)
+		code = expanded
+
+		if nextWidth, ok := self.nextIndentation(idx); ok && nextWidth > width {
+			code += " {"
+			self.indents = append(self.indents, nextWidth)
+		} else if !strings.HasSuffix(code, ",") && !strings.HasSuffix(code, ";") {
+			code += ";"
+		}
+		if shift != 0 {
+			self.columnShifts[idx] = shift
+		}
+		self.output = append(self.output, prefix+indent+code)
+	}
+
+	if self.comment != "" {
+		self.closeComment()
+	}
+	if len(self.indents) > 1 {
+		self.output = append(self.output, strings.Repeat("}", len(self.indents)-1))
+	}
+	return nil
+}
+
+// nextIndentation returns the indentation width of the next non-blank line.
+func (self *indentedConverter) nextIndentation(idx int) (int, bool) {
+	for _, line := range self.lines[idx+1:] {
+		if indent, content := splitIndentation(line); content != "" {
+			return len(indent), true
+		}
+	}
+	return 0, false
+}
+
+func (self *indentedConverter) openComment(width int, content string) {
+	self.commentIndent = width
+	if strings.HasPrefix(content, "//") {
+		self.comment = "//"
+	} else if !strings.Contains(content, "*/") {
+		self.comment = "/*"
+		self.commentLine = len(self.output)
+	}
+}
+
+func (self *indentedConverter) appendComment(indent string, content string) {
+	if self.comment == "//" {
+		self.output = append(self.output, "")
+		return
+	}
+	self.commentLine = len(self.output)
+	self.output = append(self.output, indent+content)
+	if strings.Contains(content, "*/") {
+		self.comment = ""
+	}
+}
+
+func (self *indentedConverter) closeComment() {
+	if self.comment == "/*" && self.commentLine >= 0 {
+		self.output[self.commentLine] += " */"
+	}
+	self.comment = ""
+	self.commentLine = -1
+}
+
+/*
+expandShorthand expands the "=mixin" and "+include" shorthands, and quotes
+the unquoted urls of @import:
+
+	=button($color)   => @mixin button($color)
+	+button(red)      => @include button(red)
+	@import foo, bar  => @import "foo", "bar"
+*/
+func expandShorthand(code string) string {
+	if strings.HasPrefix(code, "=") {
+		return "@mixin " + strings.TrimLeft(code[1:], " \t")
+	}
+	if strings.HasPrefix(code, "+") && len(code) > 1 && isIndentedIdentifierStart(code[1]) {
+		return "@include " + code[1:]
+	}
+	if strings.HasPrefix(code, "@import ") && !strings.ContainsAny(code, "\"'(") {
+		var urls = []string{}
+		for _, url := range strings.Split(strings.TrimPrefix(code, "@import "), ",") {
+			urls = append(urls, "\""+strings.TrimSpace(url)+"\"")
+		}
+		return "@import " + strings.Join(urls, ", ")
+	}
+	return code
+}
+
+func isIndentedIdentifierStart(c byte) bool {
+	return c == '_' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+/*
+stripTrailingComment strips the "//" comment at the end of the line, the
+"//" in the strings and urls (e.g. "http://") are not comments.
+*/
+func stripTrailingComment(content string) string {
+	var quote byte = 0
+	for i := 0; i < len(content)-1; i++ {
+		var c = content[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'':
+			quote = c
+		case '/':
+			if content[i+1] == '/' && i > 0 && (content[i-1] == ' ' || content[i-1] == '\t') {
+				return strings.TrimRight(content[:i], " \t")
+			}
+		}
+	}
+	return content
+}
@@ -0,0 +1,126 @@
+package c6
+
+import "c6/ast"
+import "c6/compiler"
+import "c6/runtime"
+import "bytes"
+import "os"
+import "path/filepath"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func AssertIndentedSyntax(t *testing.T, code string, expected string) {
+	converted, _, err := ConvertIndentedSyntax(code)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, converted)
+}
+
+func TestIndentedSyntaxRuleSet(t *testing.T) {
+	AssertIndentedSyntax(t, "div\n  color: red\n  a\n    float: left\n\nspan\n  color: blue\n",
+		"div {\n  color: red;\n  a {\n    float: left;\n\n}}span {\n  color: blue;\n\n}")
+}
+
+func TestIndentedSyntaxTabs(t *testing.T) {
+	AssertIndentedSyntax(t, "div\n\tcolor: red", "div {\n\tcolor: red;\n}")
+}
+
+func TestIndentedSyntaxSelectorContinuation(t *testing.T) {
+	AssertIndentedSyntax(t, "a,\nb\n  color: red", "a,\nb {\n  color: red;\n}")
+}
+
+func TestIndentedSyntaxMixinShorthand(t *testing.T) {
+	AssertIndentedSyntax(t, "=rounded($r)\n  border-radius: $r\n.box\n  +rounded(3px)",
+		"@mixin rounded($r) {\n  border-radius: $r;\n}.box {\n  @include rounded(3px);\n}")
+}
+
+func TestIndentedSyntaxImport(t *testing.T) {
+	AssertIndentedSyntax(t, "@import foo, bar/baz", `@import "foo", "bar/baz";`)
+	AssertIndentedSyntax(t, `@import url(foo.css)`, `@import url(foo.css);`)
+}
+
+func TestIndentedSyntaxElse(t *testing.T) {
+	AssertIndentedSyntax(t, "@if $a\n  color: red\n@else\n  color: blue",
+		"@if $a {\n  color: red;\n}@else {\n  color: blue;\n}")
+}
+
+func TestIndentedSyntaxComments(t *testing.T) {
+	AssertIndentedSyntax(t, "// silent\n  still silent\ndiv\n  color: red // trailing\n  background: url(http://foo.com/a.png)",
+		"\n\ndiv {\n  color: red;\n  background: url(http://foo.com/a.png);\n}")
+	AssertIndentedSyntax(t, "/* loud\n   comment\ndiv\n  color: red",
+		"/* loud\n   comment */\ndiv {\n  color: red;\n}")
+}
+
+func TestSassCommentsInBlocks(t *testing.T) {
+	var code = ".a\n  // whole line\n  color: red // trailing\n  .b\n    // deeper\n      still silent\n    c: 1 // x\n  /* loud */\n  d: 2\n"
+	stmts, err := NewParser(NewContext()).Parse(code, SassFileType)
+	if assert.Nil(t, err) {
+		var interpreter = runtime.NewInterpreter()
+		interpreter.ParseSelectors = ParseSelectorGroup
+		stmts, err = interpreter.EvaluateStatements(stmts)
+		assert.Nil(t, err)
+		var buf bytes.Buffer
+		assert.Nil(t, compiler.NewCompiler(&buf, compiler.CompactStyle).CompileStatements(stmts))
+		assert.Equal(t, ".a { color: red; /* loud */ d: 2; }\n.a .b { c: 1; }\n", buf.String())
+	}
+}
+
+func TestIndentedSyntaxInconsistentIndentation(t *testing.T) {
+	_, _, err := ConvertIndentedSyntax("div\n    color: red\n  float: left")
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, err.(*ParseError).Line)
+
+	_, _, err = ConvertIndentedSyntax("div\n \tcolor: red")
+	assert.NotNil(t, err)
+}
+
+func TestParserParseSass(t *testing.T) {
+	stmts, err := NewParser(NewContext()).Parse("div\n  color: red\n  span\n    float: left\n", SassFileType)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(stmts))
+	var ruleset = stmts[0].(*ast.RuleSet)
+	assert.Equal(t, 1, len(ruleset.Block.Statements))
+	assert.Equal(t, 1, len(ruleset.Block.SubRuleSets))
+}
+
+func TestParserParseSassError(t *testing.T) {
+	var parser = NewParser(NewContext())
+	parser.File = "main.sass"
+	_, err := parser.Parse("div\n    color: red\n  float: left\n", SassFileType)
+	assert.NotNil(t, err)
+	assert.Equal(t, "main.sass:3: Inconsistent indentation", err.Error())
+}
+
+func TestParserSassTokenPosition(t *testing.T) {
+	stmts, err := NewParser(NewContext()).Parse("div\n  color: red\nspan\n  color: blue\n", SassFileType)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(stmts))
+	// "}span" is converted from "span", the column is kept
+	var ruleset = stmts[1].(*ast.RuleSet)
+	assert.Equal(t, 2, ruleset.Token.Line)
+	assert.Equal(t, 0, ruleset.Token.Column)
+	var property = ruleset.Block.Statements[0].(*ast.Property)
+	assert.Equal(t, 3, property.Name.Token.Line)
+	assert.Equal(t, 2, property.Name.Token.Column)
+}
+
+func TestSassFileErrorLines(t *testing.T) {
+	var cases = map[string]string{
+		"$x: 1\n.a\n  color: red\n  .b\n    width: $nope\n":     "main.sass:5: Undefined variable $nope",
+		"=m\n  x: 1\n\n.a\n  +m\n  +m(1)\n":                     "main.sass:6: Only 0 arguments are allowed for mixin 'm'",
+		".a\n  color: red\n\n// comment\n.b\n  +missing(1px)\n": "main.sass:6: Undefined mixin 'missing'",
+		".a\n  color: red\n  width: (1px\n":                     "main.sass:3: Expecting T_PAREN_END",
+	}
+	for code, message := range cases {
+		var dir = writeImportFiles(t, map[string]string{"main.sass": code})
+		stmts, err := NewParser(NewContext()).ParseFile(filepath.Join(dir, "main.sass"))
+		if err == nil {
+			var interpreter = runtime.NewInterpreter()
+			interpreter.ParseSelectors = ParseSelectorGroup
+			_, err = interpreter.EvaluateStatements(stmts)
+		}
+		os.RemoveAll(dir)
+		if assert.NotNil(t, err, code) {
+			assert.Contains(t, err.Error(), filepath.Join(dir, message), code)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package c6
 
+import "strings"
 import "unicode"
+import "unicode/utf8"
 import _ "fmt"
 import "c6/ast"
 
@@ -10,7 +12,7 @@ func isInterpolationStartToken(r rune, r2 rune) bool {
 
 // does not test ' '
 func isSelectorOperatorToken(r rune) bool {
-	return r == '>' || r == '+' || r == ','
+	return r == '>' || r == '+' || r == '~' || r == ','
 }
 
 func isSelector(t ast.TokenType) bool {
@@ -19,7 +21,11 @@ func isSelector(t ast.TokenType) bool {
 		t == ast.T_TYPE_SELECTOR ||
 		t == ast.T_UNIVERSAL_SELECTOR ||
 		t == ast.T_PARENT_SELECTOR || // SASS parent selector
-		t == ast.T_PSEUDO_SELECTOR // :hover, :visited , ...
+		t == ast.T_PLACEHOLDER_SELECTOR || // SASS placeholder selector
+		t == ast.T_PSEUDO_SELECTOR || // :hover, :visited , ...
+		t == ast.T_INTERPOLATION_SELECTOR ||
+		t == ast.T_KEYFRAME_SELECTOR || // 50%, ...
+		t == ast.T_BRACKET_RIGHT // the end of attribute selector
 }
 
 /**
@@ -31,15 +37,17 @@ func isSelectorStopToken(r rune) bool {
 		r == '.' ||
 		r == '[' ||
 		r == '#' ||
+		r == '%' ||
 		r == '&' ||
 		r == '>' ||
 		r == '*' ||
 		r == '+' ||
+		r == '~' ||
 		r == ','
 }
 
 func isDescendantCombinatorSeparator(r rune) bool {
-	return r == ' '
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
 }
 
 func lexAttributeSelector(l *Lexer) stateFn {
@@ -98,7 +106,7 @@ func lexAttributeSelector(l *Lexer) stateFn {
 		if r == ']' {
 			l.next()
 			l.emit(ast.T_BRACKET_RIGHT)
-			return lexStatement
+			return lexSelectors
 		}
 
 	}
@@ -110,13 +118,30 @@ func lexClassSelector(l *Lexer) stateFn {
 	l.accept(".")
 
 	var r = l.next()
-	if !unicode.IsLetter(r) {
+
+	// the class name starts with an interpolation, e.g. ".#{$name}-icon"
+	if isInterpolationStartToken(r, l.peek()) {
+		for {
+			if isInterpolationStartToken(r, l.peek()) {
+				l.backup()
+				lexInterpolation(l, false)
+			} else if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+				break
+			}
+			r = l.next()
+		}
+		l.backup()
+		l.emit(ast.T_INTERPOLATION_SELECTOR)
+		return lexSelectors
+	}
+
+	if !unicode.IsLetter(r) && r != '-' && r != '_' {
 		l.error("Expecting letter for class selector. got '%s'", r)
 		return nil
 	}
 
-	// skip valid class name characters
-	for unicode.IsLetter(r) || r == '-' || r == '_' {
+	// skip valid class name characters, e.g. ".col-2"
+	for unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
 		r = l.next()
 	}
 	l.backup()
@@ -124,6 +149,27 @@ func lexClassSelector(l *Lexer) stateFn {
 	return lexSelectors
 }
 
+/*
+The placeholder selector is only used by @extend, the rulesets of the
+placeholder selectors are not generated.
+*/
+func lexPlaceholderSelector(l *Lexer) stateFn {
+	l.accept("%")
+
+	var r = l.next()
+	if !unicode.IsLetter(r) {
+		l.error("Expecting letter for placeholder selector. got '%s'", r)
+		return nil
+	}
+
+	for unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+		r = l.next()
+	}
+	l.backup()
+	l.emit(ast.T_PLACEHOLDER_SELECTOR)
+	return lexSelectors
+}
+
 func lexPseudoSelector(l *Lexer) stateFn {
 	var foundInterpolation = false
 
@@ -170,6 +216,44 @@ func lexPseudoSelector(l *Lexer) stateFn {
 	return lexSelectors
 }
 
+/*
+keyframeSelectorLength returns the length of the selector of the keyframe
+block at the start of the input, e.g. "50%", "12.5%", "from" or "to", 0 if
+the input doesn't start with a keyframe selector.
+*/
+func keyframeSelectorLength(input string) int {
+	for _, keyword := range []string{"from", "to"} {
+		if len(input) >= len(keyword) && strings.EqualFold(input[:len(keyword)], keyword) {
+			if len(input) == len(keyword) {
+				return len(keyword)
+			}
+			var r, _ = utf8.DecodeRuneInString(input[len(keyword):])
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+				return len(keyword)
+			}
+			return 0
+		}
+	}
+	var i = 0
+	for i < len(input) && (unicode.IsDigit(rune(input[i])) || input[i] == '.') {
+		i++
+	}
+	if i > 0 && i < len(input) && input[i] == '%' {
+		return i + 1
+	}
+	return 0
+}
+
+/*
+lexKeyframeSelector lexes the selector of the keyframe block inside
+@keyframes, e.g. "50%", "12.5%", "from" or "to".
+*/
+func lexKeyframeSelector(l *Lexer) stateFn {
+	l.Offset += keyframeSelectorLength(l.Input[l.Offset:])
+	l.emit(ast.T_KEYFRAME_SELECTOR)
+	return lexSelectors
+}
+
 func lexUniversalSelector(l *Lexer) stateFn {
 	var r = l.next()
 	if r != '*' {
@@ -208,8 +292,8 @@ func lexSelectors(l *Lexer) stateFn {
 	if tok := l.lastToken(); tok != nil && isSelector(tok.Type) {
 		var foundSpace = false
 		var r = l.next()
-		for r == ' ' || r == '/' {
-			if r == ' ' {
+		for isDescendantCombinatorSeparator(r) || r == '/' {
+			if r != '/' {
 				foundSpace = true
 			}
 			lexComment(l, false)
@@ -219,7 +303,7 @@ func lexSelectors(l *Lexer) stateFn {
 		if r == EOF {
 			return nil
 		}
-		if foundSpace && r != ',' && r != '{' && !isSelectorOperatorToken(r) {
+		if foundSpace && r != ',' && r != '{' && r != ';' && r != '!' && !isSelectorOperatorToken(r) {
 			l.emit(ast.T_DESCENDANT_COMBINATOR)
 		} else {
 			l.ignore()
@@ -231,8 +315,10 @@ func lexSelectors(l *Lexer) stateFn {
 	// re-peek again
 	r = l.peek()
 
-	// lex the first selector
-	if unicode.IsLetter(r) {
+	// lex the first selector, the digits not followed by '%' are invalid
+	if keyframeSelectorLength(l.Input[l.Offset:]) > 0 {
+		return lexKeyframeSelector
+	} else if unicode.IsLetter(r) {
 		return lexTypeSelector
 	} else if r == '[' {
 
@@ -242,6 +328,10 @@ func lexSelectors(l *Lexer) stateFn {
 
 		return lexClassSelector
 
+	} else if r == '%' {
+
+		return lexPlaceholderSelector
+
 	} else if r == ':' {
 
 		return lexPseudoSelector
@@ -249,6 +339,13 @@ func lexSelectors(l *Lexer) stateFn {
 	} else if r == '&' {
 
 		l.expect("&")
+
+		// the suffix of the parent selector, e.g. "&-title"
+		r = l.next()
+		for unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			r = l.next()
+		}
+		l.backup()
 		l.emit(ast.T_PARENT_SELECTOR)
 		return lexSelectors
 
@@ -309,10 +406,14 @@ func lexSelectors(l *Lexer) stateFn {
 		l.next()
 		l.emit(ast.T_ADJACENT_SIBLING_COMBINATOR)
 		return lexSelectors
-	} else if r == ' ' {
+	} else if r == '~' {
+		l.next()
+		l.emit(ast.T_GENERAL_SIBLING_COMBINATOR)
+		return lexSelectors
+	} else if isDescendantCombinatorSeparator(r) {
 
 		l.next()
-		for r == ' ' {
+		for isDescendantCombinatorSeparator(r) {
 			r = l.next()
 		}
 		l.backup()
@@ -320,10 +421,19 @@ func lexSelectors(l *Lexer) stateFn {
 
 		return lexSelectors
 
-	} else if r == '{' {
+	} else if r == '{' || r == ';' {
 
+		// the block of the ruleset or the end of @extend
 		return lexStatement
 
+	} else if r == '!' {
+
+		// the flag of @extend, e.g. "!optional"
+		if l.matchKeywordMap(ast.FlagTokenMap) == 0 {
+			l.error("Unexpected flag '%s' for lexing selector.", r)
+		}
+		return lexSelectors
+
 	} else {
 		l.error("Unexpected token '%s' for lexing selector.", r)
 	}
@@ -343,7 +453,8 @@ func lexTypeSelector(l *Lexer) stateFn {
 			l.backup()
 			lexInterpolation(l, false)
 			foundInterpolation = true
-		} else if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+		} else if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			// the custom elements contain hyphens, e.g. "my-element"
 			break
 		}
 		r = l.next()
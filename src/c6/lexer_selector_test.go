@@ -399,6 +399,14 @@ func TestLexerSelectorInterpolationInTheMiddleOfClassSelector(t *testing.T) {
 	l.close()
 }
 
+func TestLexerSelectorInterpolationAtTheStartOfClassSelector(t *testing.T) {
+	l := NewLexerWithString(`.#{ abc }-2 .col-2 {  }`)
+	assert.NotNil(t, l)
+	l.run()
+	AssertTokenSequence(t, l, []ast.TokenType{ast.T_INTERPOLATION_SELECTOR, ast.T_DESCENDANT_COMBINATOR, ast.T_CLASS_SELECTOR, ast.T_BRACE_START, ast.T_BRACE_END})
+	l.close()
+}
+
 func TestLexerSelectorInterpolationWithSuffix(t *testing.T) {
 	l := NewLexerWithString(`#{ abc }foo#{ bar } {  }`)
 	assert.NotNil(t, l)
@@ -430,3 +438,53 @@ func TestLexerSelectorInterpolationInTheMiddleOfPseudoSelector2(t *testing.T) {
 	AssertTokenSequence(t, l, []ast.TokenType{ast.T_INTERPOLATION_SELECTOR, ast.T_BRACE_START, ast.T_BRACE_END})
 	l.close()
 }
+
+func TestLexerPlaceholderSelector(t *testing.T) {
+	l := NewLexerWithString(`%visually-hidden .foo {  }`)
+	assert.NotNil(t, l)
+	l.run()
+	AssertTokenSequence(t, l, []ast.TokenType{
+		ast.T_PLACEHOLDER_SELECTOR, ast.T_DESCENDANT_COMBINATOR, ast.T_CLASS_SELECTOR,
+		ast.T_BRACE_START, ast.T_BRACE_END})
+	l.close()
+}
+
+func TestLexerExtendStatement(t *testing.T) {
+	l := NewLexerWithString(`.foo { @extend a.link:hover !optional; @extend %hidden; }`)
+	assert.NotNil(t, l)
+	l.run()
+	AssertTokenSequence(t, l, []ast.TokenType{
+		ast.T_CLASS_SELECTOR, ast.T_BRACE_START,
+		ast.T_EXTEND, ast.T_TYPE_SELECTOR, ast.T_CLASS_SELECTOR, ast.T_PSEUDO_SELECTOR, ast.T_OPTIONAL, ast.T_SEMICOLON,
+		ast.T_EXTEND, ast.T_PLACEHOLDER_SELECTOR, ast.T_SEMICOLON,
+		ast.T_BRACE_END})
+	l.close()
+}
+
+func TestLexerParentSelectorSuffixAndSiblingCombinator(t *testing.T) {
+	AssertLexerTokenSequence(t, `.a { &-title ~ .b { } }`, []ast.TokenType{
+		ast.T_CLASS_SELECTOR, ast.T_BRACE_START,
+		ast.T_PARENT_SELECTOR, ast.T_GENERAL_SIBLING_COMBINATOR, ast.T_CLASS_SELECTOR,
+		ast.T_BRACE_START, ast.T_BRACE_END,
+		ast.T_BRACE_END})
+}
+
+func TestLexerMultiLineSelectorGroup(t *testing.T) {
+	l := NewLexerWithString("h1,\nh2,\r\n\th3 {  }")
+	assert.NotNil(t, l)
+	l.run()
+	AssertTokenSequence(t, l, []ast.TokenType{
+		ast.T_TYPE_SELECTOR, ast.T_COMMA, ast.T_TYPE_SELECTOR, ast.T_COMMA, ast.T_TYPE_SELECTOR,
+		ast.T_BRACE_START, ast.T_BRACE_END})
+	l.close()
+}
+
+func TestLexerDescendantSelectorAcrossLines(t *testing.T) {
+	l := NewLexerWithString(".a\n.b\t.c {  }")
+	assert.NotNil(t, l)
+	l.run()
+	AssertTokenSequence(t, l, []ast.TokenType{
+		ast.T_CLASS_SELECTOR, ast.T_DESCENDANT_COMBINATOR, ast.T_CLASS_SELECTOR, ast.T_DESCENDANT_COMBINATOR, ast.T_CLASS_SELECTOR,
+		ast.T_BRACE_START, ast.T_BRACE_END})
+	l.close()
+}
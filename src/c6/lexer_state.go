@@ -6,6 +6,7 @@ import (
 	"c6/ast"
 	"errors"
 	"fmt"
+	"strings"
 )
 
 type stateFn func(*Lexer) stateFn
@@ -26,7 +27,7 @@ func lexCommentLine(l *Lexer, emit bool) stateFn {
 
 	var r = l.next()
 	for r != '\n' && r != EOF {
-		l.next()
+		r = l.next()
 	}
 	l.backup()
 	if emit {
@@ -191,6 +192,10 @@ func lexAtRule(l *Lexer) stateFn {
 	var tokType = l.matchKeywordList(ast.KeywordList)
 	if tokType > 0 {
 		switch tokType {
+		case ast.T_IMPORT_ONCE:
+			l.expectDialect(AllowImportOnce)
+			fallthrough
+
 		case ast.T_IMPORT:
 			l.ignoreSpaces()
 			lexUrl(l)
@@ -205,6 +210,7 @@ func lexAtRule(l *Lexer) stateFn {
 
 		case ast.T_CHARSET:
 			l.ignoreSpaces()
+			lexString(l)
 			return lexStatement
 
 		case ast.T_IF:
@@ -230,16 +236,62 @@ func lexAtRule(l *Lexer) stateFn {
 			}
 			return lexStatement
 
+		case ast.T_EACH:
+			for fn := lexExpression(l); fn != nil; fn = lexExpression(l) {
+			}
+			return lexStatement
+
 		case ast.T_MIXIN:
 			for fn := lexExpression(l); fn != nil; fn = lexExpression(l) {
 			}
 			return lexStatement
 
+		case ast.T_SWITCH, ast.T_CASE:
+			l.expectDialect(AllowSwitchStatement)
+			for fn := lexExpression(l); fn != nil; fn = lexExpression(l) {
+			}
+			return lexStatement
+
+		case ast.T_CASE_DEFAULT:
+			l.expectDialect(AllowSwitchStatement)
+			return lexStatement
+
 		case ast.T_INCLUDE:
-			panic("@include is not supported yet")
+			for fn := lexExpression(l); fn != nil; fn = lexExpression(l) {
+			}
+			return lexStatement
 
-		case ast.T_FUNCTION:
-			panic("@function is not supported yet")
+		case ast.T_CONTENT:
+			return lexStatement
+
+		case ast.T_EXTEND:
+			l.ignoreSpaces()
+			return lexSelectors
+
+		case ast.T_FUNCTION, ast.T_RETURN, ast.T_DEBUG, ast.T_WARN, ast.T_ERROR:
+			for fn := lexExpression(l); fn != nil; fn = lexExpression(l) {
+			}
+			return lexStatement
+
+		case ast.T_SUPPORTS:
+			for fn := lexExpression(l); fn != nil; fn = lexExpression(l) {
+			}
+			return lexStatement
+
+		case ast.T_KEYFRAMES, ast.T_PAGE, ast.T_NAMESPACE:
+			lexAtRulePrelude(l)
+			return lexStatement
+
+		case ast.T_FONT_FACE:
+			return lexStatement
+
+		case ast.T_AT_ROOT:
+			lexAtRootQuery(l)
+			l.ignoreSpaces()
+			if l.peek() == '{' {
+				return lexStatement
+			}
+			return lexSelectors
 
 		default:
 			var r = l.next()
@@ -250,9 +302,112 @@ func lexAtRule(l *Lexer) stateFn {
 			panic(fmt.Errorf("Unsupported at-rule directive '%s' %s", l.current(), tokType))
 		}
 	}
+	return lexUnknownAtRule(l)
+}
+
+/*
+lexUnknownAtRule lexes the at-rules which are not in the keyword list. The
+vendor-prefixed @keyframes, e.g. "@-webkit-keyframes", is lexed as
+@keyframes, the other at-rules are passed through with their preludes.
+*/
+func lexUnknownAtRule(l *Lexer) stateFn {
+	if !l.accept("@") {
+		return nil
+	}
+	var r = l.next()
+	for unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+		r = l.next()
+	}
+	l.backup()
+	if l.length() == 1 {
+		l.error("Expecting the name of the at-rule after '@'. Got '%s'", r)
+	}
+
+	var name = l.Input[l.Start:l.Offset]
+	if strings.HasPrefix(name, "@-") && strings.HasSuffix(name, "-keyframes") {
+		l.emit(ast.T_KEYFRAMES)
+	} else {
+		l.emit(ast.T_AT_RULE)
+	}
+	lexAtRulePrelude(l)
+	return lexStatement
+}
+
+/*
+lexAtRulePrelude lexes the text between the at-rule name and the block or
+the semicolon into an unquoted string, e.g. the name of @keyframes or the
+selector of @page. The interpolations are kept in the text.
+*/
+func lexAtRulePrelude(l *Lexer) stateFn {
+	l.ignoreSpaces()
+	var r = l.next()
+	for r != '{' && r != ';' && r != '}' && r != EOF {
+		if isInterpolationStartToken(r, l.peek()) {
+			for r != '}' && r != EOF {
+				r = l.next()
+			}
+		} else if r == '"' || r == '\'' {
+			var quote = r
+			r = l.next()
+			for r != quote && r != EOF {
+				if r == '\\' {
+					l.next()
+				}
+				r = l.next()
+			}
+		}
+		r = l.next()
+	}
+	l.backup()
+
+	var prelude = strings.TrimRight(l.Input[l.Start:l.Offset], " \t\r\n")
+	if prelude == "" {
+		l.ignore()
+		return nil
+	}
+	var token = l.createToken(ast.T_UNQUOTE_STRING)
+	token.Str = prelude
+	token.ContainsInterpolation = strings.Contains(prelude, "#{")
+	l.emitToken(token)
 	return nil
 }
 
+/*
+lexAtRootQuery lexes the query of @at-root if there is one:
+
+	@at-root (without: media rule) { ... }
+*/
+func lexAtRootQuery(l *Lexer) {
+	l.ignoreSpaces()
+	if !l.acceptAndEmit("(", ast.T_PAREN_START) {
+		return
+	}
+	l.ignoreSpaces()
+	lexIdentifier(l)
+	lexColon(l)
+	for {
+		l.ignoreSpaces()
+		if l.acceptAndEmit(")", ast.T_PAREN_END) {
+			return
+		}
+		var r = l.peek()
+		if !unicode.IsLetter(r) && r != '-' {
+			l.error("Expecting the names of the at-rules in the @at-root query. Got '%s'", r)
+		}
+		lexIdentifier(l)
+	}
+}
+
+/*
+expectDialect raises an error if the at-rule just emitted is not supported by
+the dialect of the lexer.
+*/
+func (l *Lexer) expectDialect(flag DialectFlags) {
+	if !l.Dialect.Has(flag) {
+		panic(fmt.Errorf("The at-rule '%s' is only supported in ECSS", l.lastToken().Str))
+	}
+}
+
 func lexSpaces(l *Lexer) stateFn {
 	for {
 		var t = l.next()
@@ -266,7 +421,7 @@ func lexSpaces(l *Lexer) stateFn {
 
 func lexUnquoteStringStopAt(l *Lexer, stop rune) stateFn {
 	var r = l.next()
-	for r != stop {
+	for r != stop && r != EOF {
 		r = l.next()
 	}
 	l.backup()
@@ -390,6 +545,17 @@ CSS time unit
 @see https://developer.mozilla.org/zh-TW/docs/Web/CSS/time
 */
 func lexNumberUnit(l *Lexer) stateFn {
+	// the unit followed by the subtraction, e.g. "3px-1px"
+	for str, tokType := range ast.UnitTokenMap {
+		l.remember()
+		if l.match(str) {
+			if l.peek() == '-' && (unicode.IsDigit(l.peekBy(2)) || l.peekBy(2) == '$') {
+				l.emit(tokType)
+				break
+			}
+			l.rollback()
+		}
+	}
 	l.matchKeywordMap(ast.UnitTokenMap)
 	if l.peek() == ';' {
 		return lexStatement
@@ -519,6 +685,11 @@ func lexStatement(l *Lexer) stateFn {
 				for r != '}' {
 					r = l.next()
 				}
+			} else if r == ':' && unicode.IsSpace(l.peek()) {
+				// the space after the colon is not allowed in the pseudo
+				// selector, it's the nested properties, e.g. "font: { ... }"
+				isSelector = false
+				break
 			} else if r == '{' {
 				isSelector = true
 				break
@@ -543,8 +714,13 @@ func lexStatement(l *Lexer) stateFn {
 			return lexProperty
 		}
 
-	} else if r == '[' || r == '*' || r == '>' || r == '&' || r == '#' || r == '.' || r == '+' || r == ':' {
+	} else if r == '[' || r == '*' || r == '>' || r == '&' || r == '#' || r == '.' || r == '+' || r == '~' || r == ':' || r == '%' {
+
+		return lexSelectors
+
+	} else if unicode.IsDigit(r) {
 
+		// the percentage selector inside @keyframes
 		return lexSelectors
 
 	} else if r == EOF {
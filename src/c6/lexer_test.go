@@ -787,6 +787,55 @@ func TestLexerMediaQueryConditionSimpleMaxWidth(t *testing.T) {
 	})
 }
 
+func TestLexerSupports(t *testing.T) {
+	AssertLexerTokenSequence(t, `@supports (display: flex) and (not (display: grid)) { }`,
+		[]ast.TokenType{ast.T_SUPPORTS,
+			ast.T_PAREN_START, ast.T_IDENT, ast.T_COLON, ast.T_IDENT, ast.T_PAREN_END, ast.T_LOGICAL_AND,
+			ast.T_PAREN_START, ast.T_LOGICAL_NOT, ast.T_PAREN_START, ast.T_IDENT, ast.T_COLON, ast.T_IDENT, ast.T_PAREN_END, ast.T_PAREN_END,
+			ast.T_BRACE_START,
+			ast.T_BRACE_END,
+		})
+}
+
+func TestLexerKeyframes(t *testing.T) {
+	AssertLexerTokenSequence(t, `@-webkit-keyframes spin { from { x: 1 } 0%, 12.5% { x: 2 } }`,
+		[]ast.TokenType{ast.T_KEYFRAMES, ast.T_UNQUOTE_STRING, ast.T_BRACE_START,
+			ast.T_KEYFRAME_SELECTOR, ast.T_BRACE_START, ast.T_PROPERTY_NAME_TOKEN, ast.T_COLON, ast.T_INTEGER, ast.T_BRACE_END,
+			ast.T_KEYFRAME_SELECTOR, ast.T_COMMA, ast.T_KEYFRAME_SELECTOR, ast.T_BRACE_START,
+			ast.T_PROPERTY_NAME_TOKEN, ast.T_COLON, ast.T_INTEGER, ast.T_BRACE_END,
+			ast.T_BRACE_END,
+		})
+}
+
+func TestLexerKeyframeSelectorOnlyBeforePercent(t *testing.T) {
+	AssertLexerTokenSequence(t, `@keyframes a { TO { x: 1 } 50.5% { x: 2 } } top, from-x, to_y { }`,
+		[]ast.TokenType{ast.T_KEYFRAMES, ast.T_UNQUOTE_STRING, ast.T_BRACE_START,
+			ast.T_KEYFRAME_SELECTOR, ast.T_BRACE_START, ast.T_PROPERTY_NAME_TOKEN, ast.T_COLON, ast.T_INTEGER, ast.T_BRACE_END,
+			ast.T_KEYFRAME_SELECTOR, ast.T_BRACE_START, ast.T_PROPERTY_NAME_TOKEN, ast.T_COLON, ast.T_INTEGER, ast.T_BRACE_END,
+			ast.T_BRACE_END,
+			ast.T_TYPE_SELECTOR, ast.T_COMMA, ast.T_TYPE_SELECTOR, ast.T_COMMA, ast.T_TYPE_SELECTOR, ast.T_BRACE_START, ast.T_BRACE_END,
+		})
+}
+
+func TestLexerAtRoot(t *testing.T) {
+	AssertLexerTokenSequence(t, `.a { @at-root .b & { } @at-root (without: media rule) { } }`,
+		[]ast.TokenType{ast.T_CLASS_SELECTOR, ast.T_BRACE_START,
+			ast.T_AT_ROOT, ast.T_CLASS_SELECTOR, ast.T_DESCENDANT_COMBINATOR, ast.T_PARENT_SELECTOR, ast.T_BRACE_START, ast.T_BRACE_END,
+			ast.T_AT_ROOT, ast.T_PAREN_START, ast.T_IDENT, ast.T_COLON, ast.T_IDENT, ast.T_IDENT, ast.T_PAREN_END,
+			ast.T_BRACE_START, ast.T_BRACE_END,
+			ast.T_BRACE_END,
+		})
+}
+
+func TestLexerUnknownAtRule(t *testing.T) {
+	AssertLexerTokenSequence(t, `@custom-selector :--heading h1, h2; @viewport { width: device-width; }`,
+		[]ast.TokenType{ast.T_AT_RULE, ast.T_UNQUOTE_STRING, ast.T_SEMICOLON,
+			ast.T_AT_RULE, ast.T_BRACE_START,
+			ast.T_PROPERTY_NAME_TOKEN, ast.T_COLON, ast.T_IDENT, ast.T_SEMICOLON,
+			ast.T_BRACE_END,
+		})
+}
+
 func TestLexerMixinSimple(t *testing.T) {
 	code := `
 	@mixin large-text {
@@ -800,6 +849,40 @@ func TestLexerMixinSimple(t *testing.T) {
 	})
 }
 
+func TestLexerMixinWithParameters(t *testing.T) {
+	code := `@mixin button($color, $size: 12px, $args...) { @content; }`
+	AssertLexerTokenSequence(t, code, []ast.TokenType{
+		ast.T_MIXIN, ast.T_FUNCTION_NAME, ast.T_PAREN_START,
+		ast.T_VARIABLE, ast.T_COMMA,
+		ast.T_VARIABLE, ast.T_COLON, ast.T_INTEGER, ast.T_UNIT_PX, ast.T_COMMA,
+		ast.T_VARIABLE, ast.T_ELLIPSIS,
+		ast.T_PAREN_END, ast.T_BRACE_START,
+		ast.T_CONTENT, ast.T_SEMICOLON,
+		ast.T_BRACE_END,
+	})
+}
+
+func TestLexerIncludeWithArguments(t *testing.T) {
+	code := `.foo { @include button(red, $size: 14px); }`
+	AssertLexerTokenSequence(t, code, []ast.TokenType{
+		ast.T_CLASS_SELECTOR, ast.T_BRACE_START,
+		ast.T_INCLUDE, ast.T_FUNCTION_NAME, ast.T_PAREN_START,
+		ast.T_IDENT, ast.T_COMMA,
+		ast.T_VARIABLE, ast.T_COLON, ast.T_INTEGER, ast.T_UNIT_PX,
+		ast.T_PAREN_END, ast.T_SEMICOLON,
+		ast.T_BRACE_END,
+	})
+}
+
+func TestLexerIncludeWithContentBlock(t *testing.T) {
+	code := `@include hover { color: red; }`
+	AssertLexerTokenSequence(t, code, []ast.TokenType{
+		ast.T_INCLUDE, ast.T_IDENT, ast.T_BRACE_START,
+		ast.T_PROPERTY_NAME_TOKEN, ast.T_COLON, ast.T_IDENT, ast.T_SEMICOLON,
+		ast.T_BRACE_END,
+	})
+}
+
 func BenchmarkLexerSimple(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		// Fib(10)
@@ -812,3 +895,58 @@ func BenchmarkLexerSimple(b *testing.B) {
 		l.close()
 	}
 }
+
+func TestLexerTokenPosition(t *testing.T) {
+	var lexer = NewLexerWithString("/* multi\n   line */\n.foo {\n  color: red;\n}")
+	lexer.File = "main.scss"
+	lexer.run()
+	var tokens = []*ast.Token{}
+	for tok := <-lexer.Output; tok != nil; tok = <-lexer.Output {
+		tokens = append(tokens, tok)
+	}
+	assert.Equal(t, ".foo", tokens[1].Str)
+	assert.Equal(t, 2, tokens[1].Line)
+	assert.Equal(t, 0, tokens[1].Column)
+	assert.Equal(t, "color", tokens[3].Str)
+	assert.Equal(t, 3, tokens[3].Line)
+	assert.Equal(t, 2, tokens[3].Column)
+	assert.Equal(t, "main.scss", tokens[3].File)
+}
+
+func TestLexerFunctionWithReturn(t *testing.T) {
+	code := `@function double($n: 1px) { @return $n * 2; }`
+	AssertLexerTokenSequence(t, code, []ast.TokenType{
+		ast.T_FUNCTION, ast.T_FUNCTION_NAME, ast.T_PAREN_START,
+		ast.T_VARIABLE, ast.T_COLON, ast.T_INTEGER, ast.T_UNIT_PX,
+		ast.T_PAREN_END, ast.T_BRACE_START,
+		ast.T_RETURN, ast.T_VARIABLE, ast.T_MUL, ast.T_INTEGER, ast.T_SEMICOLON,
+		ast.T_BRACE_END,
+	})
+}
+
+func TestLexerEachStatementWithMap(t *testing.T) {
+	code := `@each $key, $value in (primary: #333, size: 12px) { }`
+	AssertLexerTokenSequence(t, code, []ast.TokenType{
+		ast.T_EACH, ast.T_VARIABLE, ast.T_COMMA, ast.T_VARIABLE, ast.T_FOR_IN,
+		ast.T_PAREN_START,
+		ast.T_IDENT, ast.T_COLON, ast.T_HEX_COLOR, ast.T_COMMA,
+		ast.T_IDENT, ast.T_COLON, ast.T_INTEGER, ast.T_UNIT_PX,
+		ast.T_PAREN_END,
+		ast.T_BRACE_START, ast.T_BRACE_END,
+	})
+}
+
+func TestLexerNestedProperties(t *testing.T) {
+	code := `.a { font: { family: serif; } margin: 0 { left: 4px; } a:hover { } }`
+	AssertLexerTokenSequence(t, code, []ast.TokenType{
+		ast.T_CLASS_SELECTOR, ast.T_BRACE_START,
+		ast.T_PROPERTY_NAME_TOKEN, ast.T_COLON, ast.T_BRACE_START,
+		ast.T_PROPERTY_NAME_TOKEN, ast.T_COLON, ast.T_IDENT, ast.T_SEMICOLON,
+		ast.T_BRACE_END,
+		ast.T_PROPERTY_NAME_TOKEN, ast.T_COLON, ast.T_INTEGER, ast.T_BRACE_START,
+		ast.T_PROPERTY_NAME_TOKEN, ast.T_COLON, ast.T_INTEGER, ast.T_UNIT_PX, ast.T_SEMICOLON,
+		ast.T_BRACE_END,
+		ast.T_TYPE_SELECTOR, ast.T_PSEUDO_SELECTOR, ast.T_BRACE_START, ast.T_BRACE_END,
+		ast.T_BRACE_END,
+	})
+}
@@ -0,0 +1,96 @@
+package logger
+
+import "fmt"
+import "io"
+import "os"
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelWarn
+)
+
+func (level Level) String() string {
+	switch level {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelWarn:
+		return "WARNING"
+	}
+	return fmt.Sprintf("Level(%d)", int(level))
+}
+
+/*
+Diagnostic is the message reported by @debug or @warn, the position is the
+directive reporting the message.
+*/
+type Diagnostic struct {
+	Level Level
+	File  string
+
+	// the line number starts from 1, 0 means the line is unknown.
+	Line int
+
+	Message string
+}
+
+func (d *Diagnostic) String() string {
+	if d.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s: %s", d.File, d.Line, d.Level, d.Message)
+	}
+	return fmt.Sprintf("%s: %s", d.Level, d.Message)
+}
+
+/*
+Sink receives the diagnostics of the compilation.
+*/
+type Sink interface {
+	Report(diagnostic *Diagnostic)
+}
+
+/*
+SinkFunc adapts the function to the Sink interface.
+*/
+type SinkFunc func(diagnostic *Diagnostic)
+
+func (fn SinkFunc) Report(diagnostic *Diagnostic) {
+	fn(diagnostic)
+}
+
+/*
+WriterSink writes the diagnostics to the writer, one line per diagnostic.
+*/
+type WriterSink struct {
+	Writer io.Writer
+}
+
+func NewWriterSink(writer io.Writer) *WriterSink {
+	return &WriterSink{writer}
+}
+
+func (self *WriterSink) Report(diagnostic *Diagnostic) {
+	fmt.Fprintln(self.Writer, diagnostic.String())
+}
+
+/*
+Collector keeps the diagnostics in memory, e.g. for the tests or the tools
+showing the diagnostics in their own way.
+*/
+type Collector struct {
+	Diagnostics []*Diagnostic
+}
+
+func NewCollector() *Collector {
+	return &Collector{Diagnostics: []*Diagnostic{}}
+}
+
+func (self *Collector) Report(diagnostic *Diagnostic) {
+	self.Diagnostics = append(self.Diagnostics, diagnostic)
+}
+
+/*
+DefaultSink is used when no sink is configured, it writes to the standard
+error.
+*/
+var DefaultSink Sink = NewWriterSink(os.Stderr)
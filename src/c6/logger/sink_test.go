@@ -0,0 +1,29 @@
+package logger
+
+import "bytes"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	var sink = NewWriterSink(&buf)
+	sink.Report(&Diagnostic{Level: LevelDebug, File: "a.scss", Line: 3, Message: "10px"})
+	sink.Report(&Diagnostic{Level: LevelWarn, Message: "deprecated"})
+	assert.Equal(t, "a.scss:3: DEBUG: 10px\nWARNING: deprecated\n", buf.String())
+}
+
+func TestSinkFunc(t *testing.T) {
+	var messages = []string{}
+	var sink Sink = SinkFunc(func(diagnostic *Diagnostic) {
+		messages = append(messages, diagnostic.Message)
+	})
+	sink.Report(&Diagnostic{Level: LevelWarn, Message: "foo"})
+	assert.Equal(t, []string{"foo"}, messages)
+}
+
+func TestCollector(t *testing.T) {
+	var collector = NewCollector()
+	collector.Report(&Diagnostic{Level: LevelDebug, Message: "foo"})
+	assert.Len(t, collector.Diagnostics, 1)
+	assert.Equal(t, LevelDebug, collector.Diagnostics[0].Level)
+}
@@ -0,0 +1,107 @@
+package c6
+
+import "testing"
+import "c6/ast"
+import "github.com/stretchr/testify/assert"
+
+func TestParserMapArgument(t *testing.T) {
+	var stmts = RunParserTest(`$a: map-merge($b, (key: 1px, other: 2px));`)
+	var assignment = stmts[0].(*ast.VariableAssignment)
+	fcall, ok := assignment.Expression.(*ast.FunctionCall)
+	if assert.True(t, ok) {
+		assert.Equal(t, 2, len(fcall.Arguments))
+		_, ok = fcall.Arguments[1].Value.(*ast.Map)
+		assert.True(t, ok)
+	}
+}
+
+func TestMapFunctions(t *testing.T) {
+	var theme = `$theme: (colors: (primary: #333, secondary: blue), sizes: (small: 12px, large: 16px));`
+	var cases = map[string]string{
+		`map-get($theme, sizes, large)`:                                                    "16px",
+		`map-get($map: map-get($theme, sizes), $key: small)`:                               "12px",
+		`map-keys($theme)`:                                                                 "colors, sizes",
+		`map-values(map-get($theme, sizes))`:                                               "12px, 16px",
+		`map-has-key($theme, sizes)`:                                                       "true",
+		`map-has-key($theme, sizes, medium)`:                                               "false",
+		`map-keys(map-merge((a: 1, b: 2), (b: 3, c: 4)))`:                                  "a, b, c",
+		`map-values(map-merge((a: 1, b: 2), (b: 3, c: 4)))`:                                "1, 3, 4",
+		`map-values(map-get(map-merge($theme, colors, (primary: red)), colors))`:           "red, blue",
+		`map-get(map-merge((a: 1), b, c, (d: 2)), b, c, d)`:                                "2",
+		`map-values(map-get(map-deep-merge($theme, (colors: (tertiary: green))), colors))`: "#333, blue, green",
+		`map-keys(map-remove((a: 1, b: 2, c: 3), a, c, d))`:                                "b",
+	}
+	for expr, expected := range cases {
+		css, err := evaluateScss(theme + ` .a { value: ` + expr + `; }`)
+		if assert.Nil(t, err, expr) {
+			assert.Equal(t, ".a { value: "+expected+"; }\n", css, expr)
+		}
+	}
+}
+
+func TestMapAssignmentFlags(t *testing.T) {
+	css, err := evaluateScss(`
+$breakpoints: (sm: 576px) !default;
+$breakpoints: (sm: 1px, md: 768px) !default;
+$colors: null;
+@mixin theme { $colors: (primary: red) !global; }
+@include theme;
+.a { b: map-get($breakpoints, sm); c: map-get($colors, primary); }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { b: 576px; c: red; }\n", css)
+}
+
+func TestMapGetMissingKey(t *testing.T) {
+	css, err := evaluateScss(`
+$theme: (sizes: (small: 12px));
+.a {
+  @if map-get($theme, nope) == null { a: 1; }
+  @if map-get($theme, sizes, small, nope) == null { b: 2; }
+}`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { a: 1; b: 2; }\n", css)
+}
+
+func TestNullPropertyIsOmitted(t *testing.T) {
+	css, err := evaluateScss(`
+.a { a: 1; b: map-get((x: 1), y); c: null; d: map-keys(()); font: null { family: serif; } }
+.b { b: null; }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { a: 1; font-family: serif; }\n", css)
+}
+
+func TestMapIsNotCssValue(t *testing.T) {
+	var cases = map[string]string{
+		"$m: (a: (c: 2), b: 3);\n.a { d: map-values($m); }": ":2: (c: 2) isn't a valid CSS value.",
+		"$e: (c: 2);\n.a { d: 1px $e; }":                    ":2: (c: 2) isn't a valid CSS value.",
+	}
+	for code, message := range cases {
+		_, err := evaluateScss(code)
+		if assert.NotNil(t, err, code) {
+			assert.Contains(t, err.Error(), message, code)
+		}
+	}
+}
+
+func TestMapFunctionsWithEach(t *testing.T) {
+	css, err := evaluateScss(`
+$sizes: (small: 12px, large: 16px);
+$sizes: map-merge($sizes, (medium: 14px));
+@each $name, $size in $sizes { .text-#{$name} { font-size: $size; } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".text-small { font-size: 12px; }\n\n.text-large { font-size: 16px; }\n\n.text-medium { font-size: 14px; }\n", css)
+}
+
+func TestMapFunctionErrors(t *testing.T) {
+	var cases = map[string]string{
+		`.a { width: map-get(10px, a); }`:          "$map: 10px is not a map for function 'map-get'",
+		`.a { width: map-get((a: 1)); }`:           "Missing argument $key of function 'map-get'",
+		`.a { width: map-keys((a: 1), $foo: 1); }`: "No argument named $foo for function 'map-keys'",
+	}
+	for code, message := range cases {
+		_, err := evaluateScss(code)
+		if assert.NotNil(t, err, code) {
+			assert.Contains(t, err.Error(), message)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package c6
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestEvaluateNestedMediaQueries(t *testing.T) {
+	out, err := evaluateScss(`$w: 10px; .a { @media screen { width: $w; @media (min-width: 600px) { width: $w * 2; } } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, "@media screen { .a { width: 10px; } }\n\n@media screen and (min-width: 600px) { .a { width: 20px; } }\n", out)
+}
+
+func TestEvaluateMediaQueryWithVariables(t *testing.T) {
+	out, err := evaluateScss(`
+	$bp: 600px;
+	$max: 1200px;
+	.a { @media screen and (min-width: $bp) { x: 1; @media (max-width: $max - 1px) { y: 2; } } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, "@media screen and (min-width: 600px) { .a { x: 1; } }\n\n"+
+		"@media screen and (min-width: 600px) and (max-width: 1199px) { .a { y: 2; } }\n", out)
+}
+
+func TestEvaluateMediaQueryWithInterpolation(t *testing.T) {
+	out, err := evaluateScss(`
+	$query: "(min-width: 600px)";
+	$type: print;
+	$w: 10px;
+	@media #{$query} { .a { x: 1; } }
+	.b { @media screen and #{$query} { y: 2; @media (max-width: 900px) { z: 3; } } }
+	@media #{$type}, (max-width: $w) { .c { w: 4; } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, "@media (min-width: 600px) { .a { x: 1; } }\n\n"+
+		"@media screen and (min-width: 600px) { .b { y: 2; } }\n\n"+
+		"@media screen and (min-width: 600px) and (max-width: 900px) { .b { z: 3; } }\n\n"+
+		"@media print, (max-width: 10px) { .c { w: 4; } }\n", out)
+
+	_, err = evaluateScss(`$query: "{"; @media #{$query} { .a { x: 1; } }`)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "Invalid media query '{'")
+	}
+}
+
+func TestExtendInNestedMediaQuery(t *testing.T) {
+	out, err := evaluateScss(`.e { @extend %p; } .f { @media screen { %p { x: 1; } } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, "@media screen { .f .e { x: 1; } }\n", out)
+}
+
+func TestExtendOuterSelectorFromWithinMedia(t *testing.T) {
+	_, err := evaluateScss(`
+.b { c: 1; }
+@media print {
+	.a { @extend .b; x: 1; }
+}`)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), ":4: You may not @extend the selector '.b' outside @media from within @media")
+	}
+
+	_, err = evaluateScss(`
+.b { c: 1; }
+.a {
+	@media print { @extend .b; }
+}`)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), ":4: You may not @extend the selector '.b' outside @media from within @media")
+	}
+}
+
+func TestExtendSelectorWithinTheSameMedia(t *testing.T) {
+	out, err := evaluateScss(`
+@media print {
+	.b { c: 2; }
+	.a { @extend .b; x: 1; }
+}
+@media print {
+	.c { @extend .b; }
+}`)
+	assert.Nil(t, err)
+	assert.Equal(t, "@media print { .b, .a, .c { c: 2; } .a { x: 1; } }\n", out)
+}
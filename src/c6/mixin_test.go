@@ -0,0 +1,164 @@
+package c6
+
+import "bytes"
+import "c6/ast"
+import "c6/compiler"
+import "c6/runtime"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+/*
+evaluateScss parses and evaluates the code, and compiles the result in the
+compact style.
+*/
+func evaluateScss(code string) (string, error) {
+	return evaluateScssWithStyle(code, compiler.CompactStyle)
+}
+
+func evaluateScssWithStyle(code string, style compiler.OutputStyle) (string, error) {
+	var parser = NewParser(NewContext())
+	stmts, err := parser.Parse(code, ScssFileType)
+	if err != nil {
+		return "", err
+	}
+	var interpreter = runtime.NewInterpreter()
+	interpreter.ParseSelectors = ParseSelectorGroup
+	interpreter.ParseMediaQueries = ParseMediaQueryList
+	stmts, err = interpreter.EvaluateStatements(stmts)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	err = compiler.NewCompiler(&buf, style).CompileStatements(stmts)
+	return buf.String(), err
+}
+
+func TestParserMixinStatement(t *testing.T) {
+	var stmts = RunParserTest(`@mixin button($color, $size: 12px, $args...) { color: $color; }`)
+	assert.Equal(t, 1, len(stmts))
+
+	mixin, ok := stmts[0].(*ast.MixinStatement)
+	assert.True(t, ok)
+	assert.Equal(t, "button", mixin.Name)
+	assert.Equal(t, 3, len(mixin.Parameters))
+	assert.Equal(t, "$color", mixin.Parameters[0].Variable.Name)
+	assert.Nil(t, mixin.Parameters[0].Default)
+	assert.Equal(t, "12px", mixin.Parameters[1].Default.String())
+	assert.True(t, mixin.Parameters[2].Rest)
+	assert.Equal(t, 1, len(mixin.Block.Statements))
+}
+
+func TestParserIncludeStatement(t *testing.T) {
+	var stmts = RunParserTest(`.foo { @include button(red, $size: 1px + 2px, $list...) { margin: 0; } }`)
+	ruleset, ok := stmts[0].(*ast.RuleSet)
+	assert.True(t, ok)
+
+	include, ok := ruleset.Block.Statements[0].(*ast.IncludeStatement)
+	assert.True(t, ok)
+	assert.Equal(t, "button", include.Name)
+	assert.Equal(t, 3, len(include.Arguments))
+	assert.Equal(t, "", include.Arguments[0].Name)
+	assert.Equal(t, "$size", include.Arguments[1].Name)
+	assert.Equal(t, "3px", include.Arguments[1].Value.String())
+	assert.True(t, include.Arguments[2].Rest)
+	assert.Equal(t, 1, len(include.ContentBlock.Statements))
+}
+
+func TestParserFunctionCallWithExpressionArguments(t *testing.T) {
+	var stmts = RunParserTest(`.foo { width: calc(100% - 10px); height: foo(1px + 2px, 3px 4px); }`)
+	ruleset, ok := stmts[0].(*ast.RuleSet)
+	assert.True(t, ok)
+	assert.Equal(t, 2, len(ruleset.Block.Statements))
+}
+
+func TestMixinWithDefaultAndKeywordArguments(t *testing.T) {
+	css, err := evaluateScss(`
+	@mixin button($color, $size: 12px, $padding: $size / 2) {
+		color: $color;
+		font-size: $size;
+		padding: $padding;
+	}
+	.a { @include button(red); }
+	.b { @include button($size: 20px, $color: blue); }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { color: red; font-size: 12px; padding: 6px; }\n\n"+
+		".b { color: blue; font-size: 20px; padding: 10px; }\n", css)
+}
+
+func TestMixinWithRestArguments(t *testing.T) {
+	css, err := evaluateScss(`
+	@mixin shadows($offset, $shadows...) {
+		margin: -$offset;
+		box-shadow: $shadows;
+	}
+	$list: 0 1px red, 0 2px blue;
+	.a { @include shadows(2px, 0 1px red, 0 2px blue); }
+	.b { @include shadows(2px, $list...); }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { margin: -2px; box-shadow: 0 1px red, 0 2px blue; }\n\n"+
+		".b { margin: -2px; box-shadow: 0 1px red, 0 2px blue; }\n", css)
+}
+
+func TestMixinWithEmptyRestArguments(t *testing.T) {
+	css, err := evaluateScss(`
+	@mixin shadows($shadows...) { box-shadow: $shadows; color: red; }
+	.a { @include shadows; }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { color: red; }\n", css)
+}
+
+func TestMixinWithMapSpread(t *testing.T) {
+	css, err := evaluateScss(`
+	@mixin button($color, $size: 12px, $padding: 0) { color: $color; font-size: $size; padding: $padding; }
+	@function sum($a, $b) { @return $a + $b; }
+	$opts: (size: 20px, color: blue);
+	.a { @include button($opts...); }
+	.b { @include button(red, (padding: 1px)...); width: sum((b: 2px, a: 1px)...); }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { color: blue; font-size: 20px; padding: 0; }\n\n"+
+		".b { color: red; font-size: 12px; padding: 1px; width: 3px; }\n", css)
+}
+
+func TestMixinWithContentBlock(t *testing.T) {
+	css, err := evaluateScss(`
+	@mixin hover { &:hover { @content; } }
+	@mixin wrap { .inner { @content; } }
+	$color: red;
+	.a { @include wrap { color: $color; } }
+	@include wrap { @include wrap { margin: 0; } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a .inner { color: red; }\n\n"+
+		".inner .inner { margin: 0; }\n", css)
+}
+
+func TestMixinNestedIncludeAndRuleSets(t *testing.T) {
+	css, err := evaluateScss(`
+	@mixin text($size) { font-size: $size; }
+	@mixin box($size) {
+		@include text($size);
+		.title { @include text($size * 2); }
+	}
+	.card { @include box(10px); }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".card { font-size: 10px; }\n"+
+		".card .title { font-size: 20px; }\n", css)
+}
+
+func TestMixinArgumentErrors(t *testing.T) {
+	var cases = map[string]string{
+		`.a { @include missing; }`:                                     "Undefined mixin 'missing'",
+		`@mixin m($a) { color: $a; } .a { @include m; }`:               "Missing argument $a of mixin 'm'",
+		`@mixin m($a) { color: $a; } .a { @include m(1, 2); }`:         "Only 1 arguments are allowed for mixin 'm', but 2 were passed",
+		`@mixin m($a) { color: $a; } .a { @include m($b: 1); }`:        "No argument named $b for mixin 'm'",
+		`@mixin m { @include m; } .a { @include m; }`:                  "Too many nested calls of mixin 'm'",
+		`@mixin m($a) { color: $a; } .a { @include m(1, (a: 2)...); }`: "Argument $a of mixin 'm' is passed twice",
+		`@mixin m($a) { color: $a; } .a { @include m((b: 2)...); }`:    "No argument named $b for mixin 'm'",
+		`@mixin m($a) { color: $a; } .a { @include m((1: 2)...); }`:    "The keys of the map spread (1: 2)... must be the argument names",
+	}
+	for code, message := range cases {
+		_, err := evaluateScss(code)
+		if assert.NotNil(t, err, code) {
+			assert.Contains(t, err.Error(), message)
+		}
+	}
+}
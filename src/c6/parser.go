@@ -8,6 +8,7 @@ import "fmt"
 import "c6/ast"
 import "path/filepath"
 import "io/ioutil"
+import "strings"
 
 const (
 	UnknownFileType = iota
@@ -31,8 +32,28 @@ func (e ParserError) Error() string {
 	return fmt.Sprintf("Expecting '%s', but the actual token we got was '%s'.", e.ExpectingToken, e.ActualToken)
 }
 
+/*
+ParseError is returned by ParseFile and Parse, it wraps the error raised by
+the lexer or the parser with the position in the source file.
+*/
+type ParseError struct {
+	File string
+
+	// the line number starts from 1, 0 means the line is unknown.
+	Line int
+
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.File, e.Err)
+}
+
 func getFileTypeByExtension(extension string) uint {
-	switch extension {
+	switch strings.TrimPrefix(extension, ".") {
 	case "scss":
 		return ScssFileType
 	case "sass":
@@ -47,6 +68,28 @@ type Parser struct {
 	Context *Context
 	Input   chan *ast.Token
 
+	// the path of the current file
+	File string
+
+	// the files imported by the current file directly or indirectly
+	Imports []string
+
+	// the importer which loaded the current file, it's nil for the files
+	// on the disk
+	Importer Importer
+
+	// the language features on top of SCSS
+	Dialect DialectFlags
+
+	// the current file or the imported files use @import-once, the
+	// statements depend on the files imported before.
+	HasImportOnce bool
+
+	lexer *Lexer
+
+	// the depth of the @function bodies, @return is only allowed in them
+	functionDepth int
+
 	// integer for counting token
 	Pos         int
 	RollbackPos int
@@ -54,24 +97,110 @@ type Parser struct {
 }
 
 func NewParser(context *Context) *Parser {
-	return &Parser{context, nil, 0, 0, []*ast.Token{}}
+	return &Parser{Context: context, File: "{anonymous}", Tokens: []*ast.Token{}}
 }
 
-func (parser *Parser) ParseFile(path string) error {
-	ext := filepath.Ext(path)
-	filetype := getFileTypeByExtension(ext)
+/*
+ParseFile parses the file by the syntax of the file extension, the files
+without a known extension are parsed as SCSS.
+*/
+func (parser *Parser) ParseFile(path string) ([]ast.Statement, error) {
 	data, err := ioutil.ReadFile(path)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	parser.File = path
+	return parser.Parse(string(data), getFileTypeByExtension(filepath.Ext(path)))
+}
+
+/*
+Parse parses the code by the syntax of the file type, the panics raised by
+the lexer and the parser are returned as a ParseError.
+*/
+func (parser *Parser) Parse(code string, fileType uint) (stmts []ast.Statement, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = parser.newParseError(r)
+		}
+	}()
+	switch fileType {
+	case SassFileType:
+		return parser.ParseSass(code), nil
+	case EcssFileType:
+		parser.Dialect = EcssDialect
 	}
+	return parser.ParseScss(code), nil
+}
 
-	var code string = string(data)
-	switch filetype {
-	case ScssFileType:
-		parser.ParseScss(code)
-		break
+/*
+ParseSelectorGroup parses the text of the selectors, e.g. "h1, .nav > li",
+it's used by the interpreter to parse the selectors after the interpolations
+are evaluated.
+*/
+func ParseSelectorGroup(code string) (ast.SelectorGroup, error) {
+	var parser = NewParser(NewContext())
+	stmts, err := parser.Parse(code+" {}", ScssFileType)
+	if err != nil {
+		// the position of the generated code is meaningless, keep the message only
+		if parseErr, ok := err.(*ParseError); ok && parseErr.Err != nil {
+			err = parseErr.Err
+		}
+		return nil, fmt.Errorf("Invalid selector '%s': %s", code, err)
 	}
-	return nil
+	if len(stmts) != 1 {
+		return nil, fmt.Errorf("Invalid selector '%s'", code)
+	}
+	ruleset, ok := stmts[0].(*ast.RuleSet)
+	if !ok || ruleset.SelectorTemplate != nil || len(ruleset.Selectors) == 0 {
+		return nil, fmt.Errorf("Invalid selector '%s'", code)
+	}
+	return ruleset.Selectors, nil
+}
+
+/*
+ParseMediaQueryList parses the media queries of @media, it's used to parse
+the interpolated media queries after the evaluation.
+*/
+func ParseMediaQueryList(code string) ([]*ast.MediaQuery, error) {
+	var parser = NewParser(NewContext())
+	stmts, err := parser.Parse("@media "+code+" {}", ScssFileType)
+	if err != nil {
+		if parseErr, ok := err.(*ParseError); ok && parseErr.Err != nil {
+			err = parseErr.Err
+		}
+		return nil, fmt.Errorf("Invalid media query '%s': %s", code, err)
+	}
+	if len(stmts) != 1 {
+		return nil, fmt.Errorf("Invalid media query '%s'", code)
+	}
+	media, ok := stmts[0].(*ast.MediaQueryStatement)
+	if !ok || media.QueryTemplate != nil || len(media.MediaQueryList) == 0 {
+		return nil, fmt.Errorf("Invalid media query '%s'", code)
+	}
+	return media.MediaQueryList, nil
+}
+
+func (parser *Parser) newParseError(r interface{}) *ParseError {
+	// the error raised from the imported file
+	if parseErr, ok := r.(*ParseError); ok {
+		return parseErr
+	}
+	var parseErr = &ParseError{File: parser.File}
+	if err, ok := r.(error); ok {
+		parseErr.Err = err
+	} else {
+		parseErr.Err = fmt.Errorf("%v", r)
+	}
+
+	// the line of the last token we've read, or the line of the lexer if
+	// the lexer failed before the parsing.
+	if parser.Pos > 0 && parser.Pos <= len(parser.Tokens) && parser.Tokens[parser.Pos-1] != nil {
+		parseErr.Line = parser.Tokens[parser.Pos-1].Line + 1
+	} else if parser.lexer != nil {
+		var line, _ = parser.lexer.position(parser.lexer.Offset)
+		parseErr.Line = line + 1
+	}
+	return parseErr
 }
 
 func (self *Parser) backup() {
@@ -92,7 +221,7 @@ func (self *Parser) rollback() {
 
 func (self *Parser) accept(tokenType ast.TokenType) *ast.Token {
 	var tok = self.next()
-	if tok.Type == tokenType {
+	if tok != nil && tok.Type == tokenType {
 		return tok
 	}
 	self.backup()
@@ -101,6 +230,9 @@ func (self *Parser) accept(tokenType ast.TokenType) *ast.Token {
 
 func (self *Parser) expect(tokenType ast.TokenType) *ast.Token {
 	var tok = self.next()
+	if tok == nil {
+		panic(fmt.Errorf("Expecting %s, Got end of file", tokenType))
+	}
 	if tok.Type != tokenType {
 		self.backup()
 		panic(fmt.Errorf("Expecting %s, Got %s", tokenType, tok))
@@ -180,6 +312,71 @@ func (self *Parser) peek() *ast.Token {
 	return token
 }
 
+/*
+isListItemSign returns true if the '-' token at the current position is the
+sign of the next item of the space-separated list instead of the subtraction,
+e.g. "0 -1px" and "$a -$b". Like Sass, the sign follows a space and sticks to
+the number or the variable.
+*/
+func (self *Parser) isListItemSign() bool {
+	if self.Pos == 0 || self.Pos >= len(self.Tokens) {
+		return false
+	}
+	var prevTok = self.Tokens[self.Pos-1]
+	var signTok = self.Tokens[self.Pos]
+	if prevTok == nil || signTok == nil || signTok.Type != ast.T_MINUS || prevTok.Pos+len(prevTok.Str) >= signTok.Pos {
+		return false
+	}
+
+	var pos = self.Pos
+	self.next()
+	var tok = self.peek()
+	self.restore(pos)
+	if tok == nil || tok.Pos != signTok.Pos+1 {
+		return false
+	}
+	return tok.Type == ast.T_INTEGER || tok.Type == ast.T_FLOAT || tok.Type == ast.T_VARIABLE
+}
+
+/*
+isParenthesizedOperand returns true if the parentheses at the current
+position are followed by an arithmetic operator, e.g. "(32px / 16px) * 1rem",
+the parentheses are then the factor of the expression instead of a list.
+*/
+func (self *Parser) isParenthesizedOperand() bool {
+	var pos = self.Pos
+	defer self.restore(pos)
+
+	// peek keeps the end of the input in the tokens, the unclosed
+	// parentheses are reported by the parser of the list
+	var depth = 0
+	for {
+		var tok = self.peek()
+		if tok == nil {
+			return false
+		}
+		self.advance()
+		if tok.Type == ast.T_PAREN_START {
+			depth++
+		} else if tok.Type == ast.T_PAREN_END {
+			if depth--; depth == 0 {
+				break
+			}
+		}
+	}
+	var tok = self.peek()
+	if tok == nil {
+		return false
+	}
+	switch tok.Type {
+	case ast.T_MUL, ast.T_DIV, ast.T_PLUS:
+		return true
+	case ast.T_MINUS:
+		return !self.isListItemSign()
+	}
+	return false
+}
+
 func (self *Parser) eof() bool {
 	var tok = self.next()
 	self.backup()
@@ -187,7 +384,33 @@ func (self *Parser) eof() bool {
 }
 
 func (parser *Parser) ParseScss(code string) []ast.Statement {
-	l := NewLexerWithString(code)
+	return parser.parseWithLexer(NewLexerWithString(code))
+}
+
+/*
+ParseSass parses the indented syntax, the lexer reads the code converted to
+SCSS.
+*/
+func (parser *Parser) ParseSass(code string) []ast.Statement {
+	converted, columnShifts, err := ConvertIndentedSyntax(code)
+	if err != nil {
+		if parseErr, ok := err.(*ParseError); ok {
+			parseErr.File = parser.File
+		}
+		panic(err)
+	}
+	l := NewLexerWithString(converted)
+	l.columnShifts = columnShifts
+	return parser.parseWithLexer(l)
+}
+
+func (parser *Parser) parseWithLexer(l *Lexer) []ast.Statement {
+	l.File = parser.File
+	l.Dialect = parser.Dialect
+	// the lexer runs to the end before the parser starts, the output channel
+	// has to hold all the tokens of the input.
+	l.Output = make(ast.TokenChannel, len(l.Input)+TOKEN_CHANNEL_BUFFER)
+	parser.lexer = l
 	l.run()
 	parser.Input = l.getOutput()
 	return parser.ParseStatements()
@@ -6,14 +6,42 @@ package c6
 
 import "fmt"
 import "strconv"
+import "strings"
 import "c6/ast"
 import "c6/runtime"
 
+/*
+ParseBlock parses the block of the control statements, the mixins and the
+functions, the properties are allowed since the block may be inside a
+ruleset.
+*/
 func (parser *Parser) ParseBlock() *ast.Block {
 	debug("ParseBlock")
 	parser.expect(ast.T_BRACE_START)
 	var block = ast.NewBlock()
-	block.Statements = parser.ParseStatements()
+
+	var tok = parser.peek()
+	for tok != nil && tok.Type != ast.T_BRACE_END {
+		// the line comments never go to the output, ParseProperty doesn't
+		// expect them in front of the property
+		if tok.Type == ast.T_COMMENT_LINE {
+			parser.next()
+			tok = parser.peek()
+			continue
+		}
+		if property := parser.ParseProperty(); property != nil {
+			block.AppendStatement(property)
+		} else if stm := parser.ParseStatement(); stm != nil {
+			if importStm, ok := stm.(*ast.ImportStatement); ok {
+				block.AppendStatements(importStm.Expand())
+			} else {
+				block.AppendStatement(stm)
+			}
+		} else {
+			panic(fmt.Errorf("Parse failed at token %s", tok))
+		}
+		tok = parser.peek()
+	}
 	parser.expect(ast.T_BRACE_END)
 	return block
 }
@@ -23,7 +51,11 @@ func (parser *Parser) ParseStatements() []ast.Statement {
 	// stop at t_brace end
 	for !parser.eof() {
 		if stmt := parser.ParseStatement(); stmt != nil {
-			stmts = append(stmts, stmt)
+			if importStm, ok := stmt.(*ast.ImportStatement); ok {
+				stmts = append(stmts, importStm.Expand()...)
+			} else {
+				stmts = append(stmts, stmt)
+			}
 		} else {
 			break
 		}
@@ -34,11 +66,22 @@ func (parser *Parser) ParseStatements() []ast.Statement {
 func (parser *Parser) ParseStatement() ast.Statement {
 	var token = parser.peek()
 
+	// line comments never go to the output
+	for token != nil && token.Type == ast.T_COMMENT_LINE {
+		parser.next()
+		token = parser.peek()
+	}
+
 	if token == nil {
 		return nil
 	}
 
-	if token.Type == ast.T_IMPORT {
+	if token.Type == ast.T_COMMENT_BLOCK {
+
+		parser.next()
+		return ast.NewCommentStatementWithToken(token)
+
+	} else if token.Type == ast.T_IMPORT || token.Type == ast.T_IMPORT_ONCE {
 
 		return parser.ParseImportStatement()
 
@@ -50,6 +93,34 @@ func (parser *Parser) ParseStatement() ast.Statement {
 
 		return parser.ParseMediaQueryStatement()
 
+	} else if token.Type == ast.T_SUPPORTS {
+
+		return parser.ParseSupportsStatement()
+
+	} else if token.Type == ast.T_KEYFRAMES {
+
+		return parser.ParseKeyframesStatement()
+
+	} else if token.Type == ast.T_FONT_FACE {
+
+		return parser.ParseFontFaceStatement()
+
+	} else if token.Type == ast.T_PAGE {
+
+		return parser.ParsePageStatement()
+
+	} else if token.Type == ast.T_NAMESPACE {
+
+		return parser.ParseNamespaceStatement()
+
+	} else if token.Type == ast.T_AT_RULE {
+
+		return parser.ParseAtRuleStatement()
+
+	} else if token.Type == ast.T_AT_ROOT {
+
+		return parser.ParseAtRootStatement()
+
 	} else if token.Type == ast.T_VARIABLE {
 
 		return parser.ParseVariableAssignment()
@@ -62,6 +133,46 @@ func (parser *Parser) ParseStatement() ast.Statement {
 
 		return parser.ParseForStatement()
 
+	} else if token.Type == ast.T_WHILE {
+
+		return parser.ParseWhileStatement()
+
+	} else if token.Type == ast.T_EACH {
+
+		return parser.ParseEachStatement()
+
+	} else if token.Type == ast.T_SWITCH {
+
+		return parser.ParseSwitchStatement()
+
+	} else if token.Type == ast.T_MIXIN {
+
+		return parser.ParseMixinStatement()
+
+	} else if token.Type == ast.T_INCLUDE {
+
+		return parser.ParseIncludeStatement()
+
+	} else if token.Type == ast.T_CONTENT {
+
+		return parser.ParseContentStatement()
+
+	} else if token.Type == ast.T_EXTEND {
+
+		return parser.ParseExtendStatement()
+
+	} else if token.Type == ast.T_FUNCTION {
+
+		return parser.ParseFunctionStatement()
+
+	} else if token.Type == ast.T_RETURN {
+
+		return parser.ParseReturnStatement()
+
+	} else if token.Type == ast.T_DEBUG || token.Type == ast.T_WARN || token.Type == ast.T_ERROR {
+
+		return parser.ParseDiagnosticStatement()
+
 	} else if token.IsSelector() {
 
 		return parser.ParseRuleSet()
@@ -109,6 +220,71 @@ func (parser *Parser) ParseIfStatement() ast.Statement {
 	return stm
 }
 
+/*
+ParseSwitchStatement parses the ECSS @switch statement into an if statement
+chain, the subject is compared with the values of each @case:
+
+	@switch $type {                         @if $type == primary {
+		@case primary { color: blue; }          color: blue;
+		@case danger, warning { color: red; }   } @else if $type == danger or $type == warning {
+		@default { color: black; }              color: red;
+	}                                           } @else { color: black; }
+*/
+func (parser *Parser) ParseSwitchStatement() ast.Statement {
+	var switchTok = parser.expect(ast.T_SWITCH)
+	var subject = parser.ParseExpression(false)
+	if subject == nil {
+		panic(fmt.Errorf("Expecting expression after @switch at line %d", switchTok.Line+1))
+	}
+	parser.expect(ast.T_BRACE_START)
+
+	var stm *ast.IfStatement
+	parser.skipComments()
+	for tok := parser.accept(ast.T_CASE); tok != nil; tok = parser.accept(ast.T_CASE) {
+		var condition ast.Expression
+		for {
+			var value = parser.ParseExpression(false)
+			if value == nil {
+				panic(fmt.Errorf("Expecting value after @case at line %d", tok.Line+1))
+			}
+			var equal = ast.NewBinaryExpression(ast.NewOp(ast.T_EQUAL), subject, value, false)
+			if condition == nil {
+				condition = equal
+			} else {
+				condition = ast.NewBinaryExpression(ast.NewOp(ast.T_LOGICAL_OR), condition, equal, false)
+			}
+			if parser.accept(ast.T_COMMA) == nil {
+				break
+			}
+		}
+
+		var caseStm = ast.NewIfStatement(condition, parser.ParseBlock())
+		if stm == nil {
+			stm = caseStm
+		} else {
+			stm.ElseIfs = append(stm.ElseIfs, caseStm)
+		}
+		parser.skipComments()
+	}
+	if stm == nil {
+		panic(fmt.Errorf("Expecting @case in @switch at line %d", switchTok.Line+1))
+	}
+
+	if parser.accept(ast.T_CASE_DEFAULT) != nil {
+		stm.ElseBlock = parser.ParseBlock()
+		parser.skipComments()
+	}
+	parser.expect(ast.T_BRACE_END)
+	return stm
+}
+
+// skipComments skips the comments between the clauses of a statement.
+func (parser *Parser) skipComments() {
+	for tok := parser.peek(); tok != nil && (tok.Type == ast.T_COMMENT_LINE || tok.Type == ast.T_COMMENT_BLOCK); tok = parser.peek() {
+		parser.next()
+	}
+}
+
 /*
 The operator precedence is described here
 
@@ -116,13 +292,6 @@ The operator precedence is described here
 */
 func (parser *Parser) ParseCondition() ast.Expression {
 	debug("ParseCondition")
-
-	// Boolean 'Not'
-	var tok = parser.peek()
-	if tok.Type == ast.T_LOGICAL_NOT {
-		var logicexpr = parser.ParseLogicExpression()
-		return ast.NewUnaryExpression(ast.NewOpWithToken(tok), logicexpr)
-	}
 	return parser.ParseLogicExpression()
 }
 
@@ -159,6 +328,13 @@ func (parser *Parser) ParseComparisonExpression() ast.Expression {
 
 	var expr ast.Expression
 	var tok = parser.peek()
+
+	// Boolean 'Not' binds tighter than 'and' and 'or'
+	if tok.Type == ast.T_LOGICAL_NOT {
+		parser.next()
+		return ast.NewUnaryExpression(ast.NewOpWithToken(tok), parser.ParseComparisonExpression())
+	}
+
 	if tok.Type == ast.T_PAREN_START {
 		parser.accept(ast.T_PAREN_START)
 		expr = parser.ParseLogicExpression()
@@ -183,73 +359,232 @@ func (parser *Parser) ParseRuleSet() ast.Statement {
 	var parentRuleSet = parser.Context.TopRuleSet()
 
 	var ruleset = ast.NewRuleSet()
+	ruleset.Token = tok
 	parser.Context.PushRuleSet(ruleset)
 
-	for tok.IsSelector() || tok.Type == ast.T_COMMA {
+	// the comma separates the complex selectors of the selector group, the
+	// text of the selectors is kept for the interpolations.
+	var selectors = ast.SelectorList{}
+	var text = ""
+	for tok.IsSelector() || tok.Type == ast.T_COMMA || tok.Type == ast.T_LITERAL_CONCAT {
 
-		switch tok.Type {
+		if tok.Type == ast.T_COMMA {
+			if len(selectors) == 0 && !strings.Contains(text, "#{") {
+				panic(fmt.Errorf("Expecting selector before ','. Got %s", tok))
+			}
+			ruleset.AppendSelectorList(selectors)
+			selectors = ast.SelectorList{}
+			text += ", "
+		} else if tok.Type == ast.T_INTERPOLATION_SELECTOR {
+			text += tok.Str
+		} else if tok.Type != ast.T_LITERAL_CONCAT {
+			var sel = parser.ParseSelector(tok, parentRuleSet)
+			selectors = append(selectors, sel)
+			text += sel.String()
+		}
+		tok = parser.next()
+	}
+	parser.backup()
+	if len(selectors) > 0 {
+		ruleset.AppendSelectorList(selectors)
+	}
+
+	// the selectors with interpolations are parsed after the evaluation
+	if strings.Contains(text, "#{") {
+		ruleset.Selectors = nil
+		ruleset.SelectorTemplate = parser.ParseTextTemplate(text, ruleset.Token)
+	}
+
+	// parse declaration block
+	ruleset.Block = parser.ParseDeclarationBlock()
+
+	// pop the ruleset from stack
+	parser.Context.PopRuleSet()
+	return ruleset
+}
+
+/*
+ParseTextTemplate parses the interpolations in the text of the selectors or
+the prelude of the at-rules, the text is split into the strings and the
+interpolations:
+
+	.icon-#{$name} > li    // ".icon-" #{$name} " > li"
+*/
+func (parser *Parser) ParseTextTemplate(text string, tok *ast.Token) ast.Expression {
+	var parts = []ast.Expression{}
+	for len(text) > 0 {
+		var start = strings.Index(text, "#{")
+		if start < 0 {
+			parts = append(parts, ast.NewString(0, text, tok))
+			break
+		}
+		if start > 0 {
+			parts = append(parts, ast.NewString(0, text[:start], tok))
+		}
+
+		// find the end brace of the interpolation
+		var depth = 0
+		var end = start + 2
+		for ; end < len(text); end++ {
+			if text[end] == '{' {
+				depth++
+			} else if text[end] == '}' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+		}
+		if end >= len(text) {
+			panic(fmt.Errorf("Expecting '}' for the interpolation in '%s'", text))
+		}
+		var expr = parser.parseExpressionText(text[start+2:end], tok)
+		parts = append(parts, ast.NewInterpolation(expr, tok, tok))
+		text = text[end+1:]
+	}
+
+	var template = parts[0]
+	for _, part := range parts[1:] {
+		template = ast.NewLiteralConcat(template, part)
+	}
+	return template
+}
+
+/*
+parseExpressionText parses the expression inside the interpolation of the
+text template, the tokens are put at the line of the token of the text.
+*/
+func (parser *Parser) parseExpressionText(code string, tok *ast.Token) ast.Expression {
+	var l = NewLexerWithString(code)
+	l.File = parser.File
+	l.Output = make(ast.TokenChannel, len(l.Input)+TOKEN_CHANNEL_BUFFER)
+	l.runFrom(lexExpression)
+	close(l.Output)
+
+	var exprParser = NewParser(parser.Context)
+	exprParser.File = parser.File
+	for exprTok := range l.Output {
+		if exprTok != nil {
+			exprTok.Line += tok.Line
+			exprParser.Tokens = append(exprParser.Tokens, exprTok)
+		}
+	}
+	// the expression ends at the end of the interpolation like ParseInterpolation
+	exprParser.Tokens = append(exprParser.Tokens, &ast.Token{Type: ast.T_INTERPOLATION_END, Str: "}", Line: tok.Line, File: tok.File})
+	exprParser.Input = l.Output
 
-		case ast.T_TYPE_SELECTOR:
+	var expr = exprParser.ParseExpression(true)
+	if expr == nil || exprParser.accept(ast.T_INTERPOLATION_END) == nil {
+		panic(fmt.Errorf("Invalid interpolation '#{%s}'", code))
+	}
+	return expr
+}
 
-			sel := ast.NewTypeSelectorWithToken(tok)
-			ruleset.AppendSelector(sel)
+/*
+ParseSelector creates the selector or the combinator of the selector token.
+*/
+func (parser *Parser) ParseSelector(tok *ast.Token, parentRuleSet *ast.RuleSet) ast.Selector {
+	switch tok.Type {
 
-		case ast.T_UNIVERSAL_SELECTOR:
+	case ast.T_TYPE_SELECTOR:
 
-			sel := ast.NewUniversalSelectorWithToken(tok)
-			ruleset.AppendSelector(sel)
+		return ast.NewTypeSelectorWithToken(tok)
 
-		case ast.T_ID_SELECTOR:
+	case ast.T_KEYFRAME_SELECTOR:
 
-			sel := ast.NewIdSelectorWithToken(tok)
-			ruleset.AppendSelector(sel)
+		// the percentage selectors of @keyframes are like "from" and "to"
+		return ast.NewTypeSelectorWithToken(tok)
 
-		case ast.T_CLASS_SELECTOR:
+	case ast.T_UNIVERSAL_SELECTOR:
 
-			sel := ast.NewClassSelectorWithToken(tok)
-			ruleset.AppendSelector(sel)
+		return ast.NewUniversalSelectorWithToken(tok)
 
-		case ast.T_PARENT_SELECTOR:
+	case ast.T_ID_SELECTOR:
 
-			sel := ast.NewParentSelectorWithToken(parentRuleSet, tok)
-			ruleset.AppendSelector(sel)
+		return ast.NewIdSelectorWithToken(tok)
 
-		case ast.T_PSEUDO_SELECTOR:
+	case ast.T_CLASS_SELECTOR:
 
-			sel := ast.NewPseudoSelectorWithToken(tok)
-			if nextTok := parser.peek(); nextTok.Type == ast.T_LANG_CODE {
-				sel.C = nextTok.Str
-			}
-			ruleset.AppendSelector(sel)
+		return ast.NewClassSelectorWithToken(tok)
 
-		case ast.T_ADJACENT_SIBLING_COMBINATOR:
+	case ast.T_PLACEHOLDER_SELECTOR:
 
-			ruleset.AppendSelector(&ast.AdjacentCombinator{})
+		return ast.NewPlaceholderSelectorWithToken(tok)
 
-		case ast.T_CHILD_COMBINATOR:
+	case ast.T_PARENT_SELECTOR:
 
-			ruleset.AppendSelector(&ast.ChildCombinator{})
+		return ast.NewParentSelectorWithToken(parentRuleSet, tok)
 
-		case ast.T_DESCENDANT_COMBINATOR:
+	case ast.T_BRACKET_LEFT:
 
-			ruleset.AppendSelector(&ast.DescendantCombinator{})
+		return parser.ParseAttributeSelector(tok)
 
-		case ast.T_COMMA:
-			// XXX, selector group
+	case ast.T_PSEUDO_SELECTOR:
 
-		default:
-			panic(fmt.Errorf("Unexpected selector token: %+v", tok))
+		sel := ast.NewPseudoSelectorWithToken(tok)
+		if nextTok := parser.peek(); nextTok.Type == ast.T_LANG_CODE {
+			sel.C = nextTok.Str
 		}
-		tok = parser.next()
+		return sel
+
+	case ast.T_ADJACENT_SIBLING_COMBINATOR:
+
+		return &ast.AdjacentCombinator{}
+
+	case ast.T_GENERAL_SIBLING_COMBINATOR:
+
+		return ast.NewGeneralSiblingCombinatorWithToken(tok)
+
+	case ast.T_CHILD_COMBINATOR:
+
+		return &ast.ChildCombinator{}
+
+	case ast.T_DESCENDANT_COMBINATOR:
+
+		return &ast.DescendantCombinator{}
 	}
-	parser.backup()
+	panic(fmt.Errorf("Unexpected selector token: %+v", tok))
+}
 
-	// parse declaration block
-	ruleset.Block = parser.ParseDeclarationBlock()
+/*
+ParseAttributeSelector parses the attribute selector after the '[' token:
 
-	// pop the ruleset from stack
-	parser.Context.PopRuleSet()
-	return ruleset
+	[name]
+	[name="value"]
+	[name~=value]
+*/
+func (parser *Parser) ParseAttributeSelector(tok *ast.Token) *ast.AttributeSelector {
+	var name = parser.expect(ast.T_ATTRIBUTE_NAME)
+	var op = ""
+	var pattern = ""
+
+	var opTok = parser.next()
+	switch opTok.Type {
+	case ast.T_ATTR_EQUAL:
+		op = "="
+	case ast.T_ATTR_TILDE_EQUAL:
+		op = "~="
+	case ast.T_ATTR_HYPHEN_EQUAL:
+		op = "|="
+	default:
+		parser.backup()
+	}
+
+	if op != "" {
+		var valueTok = parser.next()
+		switch valueTok.Type {
+		case ast.T_QQ_STRING:
+			pattern = "\"" + valueTok.Str + "\""
+		case ast.T_Q_STRING:
+			pattern = "'" + valueTok.Str + "'"
+		case ast.T_UNQUOTE_STRING, ast.T_IDENT:
+			pattern = valueTok.Str
+		default:
+			panic(fmt.Errorf("Unexpected value of attribute selector. Got %s", valueTok))
+		}
+	}
+	parser.expect(ast.T_BRACKET_RIGHT)
+	return ast.NewAttributeSelectorWithToken(name.Str, op, pattern, tok)
 }
 
 func (parser *Parser) ParseBoolean() ast.Expression {
@@ -314,7 +649,8 @@ func (parser *Parser) ParseNumber() ast.Expression {
 		return nil
 	}
 
-	if tok2.IsUnit() {
+	// the input may end right after the number
+	if tok2 != nil && tok2.IsUnit() {
 		// consume the unit token
 		parser.next()
 		return ast.NewNumber(val, ast.NewUnitWithToken(tok2), tok)
@@ -329,24 +665,117 @@ func (parser *Parser) ParseFunctionCall() *ast.FunctionCall {
 
 	var fcall = ast.NewFunctionCall(identTok)
 
+	fcall.Arguments = parser.ParseArguments()
+	debug("ParseFunctionCall => args: %+v", fcall.Arguments)
+	return fcall
+}
+
+/*
+ParseArgumentValue parses the value of an argument, it's an expression or a
+space-separated list, the commas separate the arguments.
+*/
+func (parser *Parser) ParseArgumentValue() ast.Expression {
+	var list = ast.NewSpaceSepList()
+	for {
+		var expr = parser.ParseExpression(false)
+		if expr == nil {
+			break
+		}
+		list.Append(expr)
+	}
+	if list.Len() == 0 {
+		return nil
+	} else if list.Len() == 1 {
+		return list.Expressions[0]
+	}
+	return list
+}
+
+/*
+ParseArguments parses the arguments of the mixin call or the function call,
+the keyword arguments and the rest argument are allowed:
+
+	(red, $size: 12px, $shadows...)
+*/
+func (parser *Parser) ParseArguments() []*ast.Argument {
+	var args = []*ast.Argument{}
+	parser.expect(ast.T_PAREN_START)
+
+	var tok = parser.peek()
+	for tok.Type != ast.T_PAREN_END {
+		var arg = &ast.Argument{}
+
+		var pos = parser.Pos
+		if variable := parser.accept(ast.T_VARIABLE); variable != nil && parser.accept(ast.T_COLON) != nil {
+			arg.Name = variable.Str
+		} else {
+			parser.restore(pos)
+		}
+
+		// the map literal is an argument only if it ends the argument, e.g.
+		// map-merge($map, (key: value))
+		pos = parser.Pos
+		if mapValue := parser.ParseMap(); mapValue != nil {
+			if tok := parser.peek(); tok != nil && (tok.Type == ast.T_COMMA || tok.Type == ast.T_PAREN_END || tok.Type == ast.T_ELLIPSIS) {
+				arg.Value = mapValue
+			}
+		}
+		if arg.Value == nil {
+			parser.restore(pos)
+			arg.Value = parser.ParseArgumentValue()
+		}
+		if arg.Value == nil {
+			panic(fmt.Errorf("Expecting argument value, got %s", parser.peek()))
+		}
+		if parser.accept(ast.T_ELLIPSIS) != nil {
+			arg.Rest = true
+		}
+		args = append(args, arg)
+
+		if parser.accept(ast.T_COMMA) == nil {
+			break
+		}
+		tok = parser.peek()
+	}
+	parser.expect(ast.T_PAREN_END)
+	return args
+}
+
+/*
+ParseParameters parses the parameters of the mixin or the function
+definition, the default values and the rest parameter are allowed:
+
+	($color, $size: 12px, $shadows...)
+*/
+func (parser *Parser) ParseParameters() []*ast.Parameter {
+	var params = []*ast.Parameter{}
 	parser.expect(ast.T_PAREN_START)
 
-	var argTok = parser.peek()
-	for argTok.Type != ast.T_PAREN_END {
-		var arg = parser.ParseFactor()
-		fcall.AppendArgument(arg)
-		debug("ParseFunctionCall => arg: %+v", arg)
-
-		argTok = parser.peek()
-		if argTok.Type == ast.T_COMMA {
-			parser.next() // skip comma
-			argTok = parser.peek()
-		} else if argTok.Type == ast.T_PAREN_END {
-			parser.next() // consume ')'
+	var tok = parser.peek()
+	for tok.Type != ast.T_PAREN_END {
+		var variable = parser.ParseVariable()
+		if variable == nil {
+			panic(fmt.Errorf("Expecting parameter variable, got %s", tok))
+		}
+
+		var param = &ast.Parameter{Variable: variable}
+		if parser.accept(ast.T_COLON) != nil {
+			if param.Default = parser.ParseArgumentValue(); param.Default == nil {
+				panic(fmt.Errorf("Expecting default value of parameter %s, got %s", variable.Name, parser.peek()))
+			}
+		}
+		if parser.accept(ast.T_ELLIPSIS) != nil {
+			param.Rest = true
+		}
+		params = append(params, param)
+
+		if parser.accept(ast.T_COMMA) == nil {
 			break
 		}
+		tok = parser.peek()
 	}
-	return fcall
+	parser.expect(ast.T_PAREN_END)
+	return params
 }
 
 func (parser *Parser) ParseIdent() *ast.Ident {
@@ -365,28 +794,23 @@ func (parser *Parser) ParseFactor() ast.Expression {
 	debug("ParseFactor at %d", parser.Pos)
 	var tok = parser.peek()
 	debug("ParseFactor => peek: %s", tok)
+	if tok == nil {
+		return nil
+	}
 
 	if tok.Type == ast.T_PAREN_START {
 		parser.expect(ast.T_PAREN_START)
 		var expr = parser.ParseExpression(true)
 		parser.expect(ast.T_PAREN_END)
-		return expr
+		return groupExpression(expr)
 
 	} else if tok.Type == ast.T_INTERPOLATION_START {
 
 		return parser.ParseInterp()
 
-	} else if tok.Type == ast.T_QQ_STRING {
-
-		tok = parser.next()
-		var str = ast.NewStringWithQuote('"', tok)
-		return ast.Expression(str)
-
-	} else if tok.Type == ast.T_Q_STRING {
+	} else if tok.Type == ast.T_QQ_STRING || tok.Type == ast.T_Q_STRING {
 
-		tok = parser.next()
-		var str = ast.NewStringWithQuote('\'', tok)
-		return ast.Expression(str)
+		return parser.ParseQuotedString()
 
 	} else if tok.Type == ast.T_TRUE {
 
@@ -403,7 +827,7 @@ func (parser *Parser) ParseFactor() ast.Expression {
 		parser.next()
 		return ast.NewNullWithToken(tok)
 
-	} else if tok.Type == ast.T_IDENT {
+	} else if tok.Type == ast.T_IDENT || tok.Type == ast.T_UNQUOTE_STRING {
 
 		parser.next()
 		return ast.Expression(ast.NewStringWithToken(tok))
@@ -435,30 +859,42 @@ func (parser *Parser) ParseFactor() ast.Expression {
 	return nil
 }
 
+/*
+groupExpression marks the binary expression in the parentheses as grouped,
+the division in the parentheses is not a CSS slash: "(32px / 16px)" is 2.
+*/
+func groupExpression(expr ast.Expression) ast.Expression {
+	if bexpr, ok := expr.(*ast.BinaryExpression); ok {
+		bexpr.Grouped = true
+	}
+	return expr
+}
+
+/*
+ParseTerm parses the factors joined by '*' and '/', the operators are left
+associative: "$a / 2 * 3" is "($a / 2) * 3".
+*/
 func (parser *Parser) ParseTerm() ast.Expression {
 	debug("ParseTerm at %d", parser.Pos)
 	var pos = parser.Pos
-	var factor = parser.ParseFactor()
-	if factor == nil {
+	var term = parser.ParseFactor()
+	if term == nil {
 		parser.restore(pos)
 		return nil
 	}
 
 	// see if the next token is '*' or '/'
 	var tok = parser.peek()
-	if tok.Type == ast.T_MUL || tok.Type == ast.T_DIV {
+	for tok != nil && (tok.Type == ast.T_MUL || tok.Type == ast.T_DIV) {
 		parser.next()
-		if term := parser.ParseTerm(); term != nil {
-			if tok.Type == ast.T_MUL {
-				return ast.NewBinaryExpression(ast.NewOpWithToken(tok), factor, term, false)
-			} else if tok.Type == ast.T_DIV {
-				return ast.NewBinaryExpression(ast.NewOpWithToken(tok), factor, term, false)
-			}
-		} else {
+		var factor = parser.ParseFactor()
+		if factor == nil {
 			panic("Unexpected token after * and /")
 		}
+		term = ast.NewBinaryExpression(ast.NewOpWithToken(tok), term, factor, false)
+		tok = parser.peek()
 	}
-	return factor
+	return term
 }
 
 /**
@@ -476,13 +912,16 @@ func (parser *Parser) ParseExpression(inParenthesis bool) ast.Expression {
 	// plus or minus. This creates an unary expression that holds the later term.
 	// this is for:  +3 or -4
 	var tok = parser.peek()
+	if tok == nil {
+		return nil
+	}
 	var expr ast.Expression = nil
 	if tok.Type == ast.T_PLUS || tok.Type == ast.T_MINUS {
 		parser.next()
 		if term := parser.ParseTerm(); term != nil {
 			expr = ast.NewUnaryExpression(ast.NewOpWithToken(tok), term)
 
-			if uexpr, ok := expr.(*ast.UnaryExpression); ok {
+			if uexpr, ok := expr.(*ast.UnaryExpression); ok && runtime.IsConstantExpression(uexpr) {
 
 				// if it's evaluatable just return the evaluated value.
 				if val := runtime.EvaluateUnaryExpression(uexpr, nil); val != nil {
@@ -504,11 +943,19 @@ func (parser *Parser) ParseExpression(inParenthesis bool) ast.Expression {
 	}
 
 	var rightTok = parser.peek()
-	for rightTok.Type == ast.T_PLUS || rightTok.Type == ast.T_MINUS || rightTok.Type == ast.T_LITERAL_CONCAT {
+	for rightTok != nil && (rightTok.Type == ast.T_PLUS || rightTok.Type == ast.T_MINUS || rightTok.Type == ast.T_LITERAL_CONCAT) {
+		// "1px -2px" is the list of two numbers instead of the subtraction
+		if parser.isListItemSign() {
+			break
+		}
+
 		// accept plus or minus
 		parser.next()
 
 		if rightTerm := parser.ParseTerm(); rightTerm != nil {
+			if rightTok.Type == ast.T_PLUS && parser.Dialect.Has(DisallowImplicitConcat) && (isStringExpression(expr) || isStringExpression(rightTerm)) {
+				panic(fmt.Errorf("Implicit string concatenation is not allowed, use interpolation instead: %s + %s", expr, rightTerm))
+			}
 			// XXX: check parenthesis
 			var bexpr = ast.NewBinaryExpression(ast.NewOpWithToken(rightTok), expr, rightTerm, inParenthesis)
 
@@ -528,6 +975,20 @@ func (parser *Parser) ParseExpression(inParenthesis bool) ast.Expression {
 	return expr
 }
 
+func isStringExpression(expr ast.Expression) bool {
+	switch expr.(type) {
+	case *ast.String, *ast.Interpolation, *ast.LiteralConcat:
+		return true
+	}
+	return false
+}
+
+/*
+ParseMap parses the map literal, nil is returned if the expression in the
+parentheses is not a map:
+
+	(primary: #333, sizes: (small: 12px, large: 16px))
+*/
 func (parser *Parser) ParseMap() ast.Expression {
 	var pos = parser.Pos
 	var tok = parser.next()
@@ -537,6 +998,7 @@ func (parser *Parser) ParseMap() ast.Expression {
 		return nil
 	}
 
+	var mapValue = ast.NewMap()
 	tok = parser.peek()
 	for tok.Type != ast.T_PAREN_END {
 		var keyExpr = parser.ParseExpression(false)
@@ -550,35 +1012,56 @@ func (parser *Parser) ParseMap() ast.Expression {
 			return nil
 		}
 
-		var valueExpr = parser.ParseExpression(false)
+		var valueExpr = parser.ParseMap()
+		if valueExpr == nil {
+			valueExpr = parser.ParseArgumentValue()
+		}
 		if valueExpr == nil {
 			parser.restore(pos)
 			return nil
 		}
+		mapValue.Set(keyExpr, valueExpr)
 
 		tok = parser.peek()
-		if tok.Type == ast.T_COMMA {
+		if tok == nil {
+			parser.restore(pos)
+			return nil
+		} else if tok.Type == ast.T_COMMA {
 			parser.next()
 			tok = parser.peek()
+		} else if tok.Type != ast.T_PAREN_END {
+			parser.restore(pos)
+			return nil
 		}
 	}
-	return nil
+	parser.expect(ast.T_PAREN_END)
+	return mapValue
 }
 
-func (parser *Parser) ParseString() ast.Expression {
-	var tok = parser.peek()
+/*
+ParseQuotedString parses the quoted string, the string with the
+interpolations is parsed as a template which is evaluated at runtime:
 
-	if tok.Type == ast.T_QQ_STRING {
+	"icon-#{$name}.png"
+*/
+func (parser *Parser) ParseQuotedString() ast.Expression {
+	var tok = parser.next()
+	var quote byte = '"'
+	if tok.Type == ast.T_Q_STRING {
+		quote = '\''
+	}
+	if strings.Contains(tok.Str, "#{") {
+		return ast.NewStringTemplate(quote, parser.ParseTextTemplate(tok.Str, tok), tok)
+	}
+	return ast.NewStringWithQuote(quote, tok)
+}
 
-		tok = parser.next()
-		var str = ast.NewStringWithQuote('"', tok)
-		return ast.Expression(str)
+func (parser *Parser) ParseString() ast.Expression {
+	var tok = parser.peek()
 
-	} else if tok.Type == ast.T_Q_STRING {
+	if tok.Type == ast.T_QQ_STRING || tok.Type == ast.T_Q_STRING {
 
-		tok = parser.next()
-		var str = ast.NewStringWithQuote('\'', tok)
-		return ast.Expression(str)
+		return parser.ParseQuotedString()
 
 	} else if tok.Type == ast.T_IDENT {
 
@@ -621,7 +1104,8 @@ func (parser *Parser) ParseValue(stopTokType ast.TokenType) ast.Expression {
 	if mapValue := parser.ParseMap(); mapValue != nil {
 		var tok = parser.peek()
 
-		if stopTokType == 0 || tok.Type == stopTokType {
+		// the flags of the assignment follow the value: (a: 1) !default;
+		if stopTokType == 0 || tok != nil && (tok.Type == stopTokType || tok.IsFlagKeyword()) {
 			debug("OK List")
 			return mapValue
 		}
@@ -632,7 +1116,7 @@ func (parser *Parser) ParseValue(stopTokType ast.TokenType) ast.Expression {
 	debug("Trying List")
 	if listValue := parser.ParseList(); listValue != nil {
 		var tok = parser.peek()
-		if stopTokType == 0 || tok.Type == stopTokType {
+		if stopTokType == 0 || tok != nil && (tok.Type == stopTokType || tok.IsFlagKeyword()) {
 			debug("OK List: %+v", listValue)
 			return listValue
 		}
@@ -644,7 +1128,7 @@ func (parser *Parser) ParseValue(stopTokType ast.TokenType) ast.Expression {
 
 	if expr := parser.ParseExpression(false); expr != nil {
 		var tok = parser.peek()
-		for tok.Type == ast.T_LITERAL_CONCAT {
+		for tok != nil && tok.Type == ast.T_LITERAL_CONCAT {
 			parser.accept(ast.T_LITERAL_CONCAT)
 
 			var rightExpr = parser.ParseExpression(false)
@@ -688,15 +1172,15 @@ func (parser *Parser) ParseCommaSepList() ast.Expression {
 	var list = ast.NewCommaSepList()
 
 	var tok = parser.peek()
-	for tok.Type != ast.T_COMMA && tok.Type != ast.T_SEMICOLON && tok.Type != ast.T_BRACE_END {
+	for tok != nil && tok.Type != ast.T_COMMA && tok.Type != ast.T_SEMICOLON && tok.Type != ast.T_BRACE_END {
 
 		// when the syntax start with a '(', it could be a list or map.
-		if tok.Type == ast.T_PAREN_START {
+		if tok.Type == ast.T_PAREN_START && !parser.isParenthesizedOperand() {
 
 			parser.next()
 			if sublist := parser.ParseCommaSepList(); sublist != nil {
 				debug("Appending sublist %+v", list)
-				list.Append(sublist)
+				list.Append(groupExpression(sublist))
 			}
 			// allow empty list here
 			parser.expect(ast.T_PAREN_END)
@@ -777,7 +1261,7 @@ func (parser *Parser) ParseVariableAssignment() ast.Statement {
 
 func (parser *Parser) ParseFlags(stm *ast.VariableAssignment) {
 	var tok = parser.peek()
-	for tok.IsFlagKeyword() {
+	for tok != nil && tok.IsFlagKeyword() {
 		parser.next()
 
 		switch tok.Type {
@@ -802,16 +1286,16 @@ func (parser *Parser) ParseSpaceSepList() ast.Expression {
 
 	var tok = parser.peek()
 
-	if tok.Type == ast.T_PAREN_START {
+	if tok.Type == ast.T_PAREN_START && !parser.isParenthesizedOperand() {
 		parser.next()
 		if sublist := parser.ParseCommaSepList(); sublist != nil {
-			list.Append(sublist)
+			list.Append(groupExpression(sublist))
 		}
 		parser.expect(ast.T_PAREN_END)
 	}
 
 	tok = parser.peek()
-	for tok.Type != ast.T_SEMICOLON && tok.Type != ast.T_BRACE_END {
+	for tok != nil && tok.Type != ast.T_SEMICOLON && tok.Type != ast.T_BRACE_END {
 		var subexpr = parser.ParseExpression(true)
 		if subexpr != nil {
 			debug("Parsed Expression: %+v", subexpr)
@@ -820,7 +1304,7 @@ func (parser *Parser) ParseSpaceSepList() ast.Expression {
 			break
 		}
 		tok = parser.peek()
-		if tok.Type == ast.T_COMMA {
+		if tok == nil || tok.Type == ast.T_COMMA {
 			break
 		}
 	}
@@ -844,7 +1328,7 @@ func (parser *Parser) ParsePropertyValue(parentRuleSet *ast.RuleSet, property *a
 	var list = ast.NewSpaceSepList()
 
 	var tok = parser.peek()
-	for tok.Type != ast.T_SEMICOLON && tok.Type != ast.T_BRACE_END {
+	for tok != nil && tok.Type != ast.T_SEMICOLON && tok.Type != ast.T_BRACE_END {
 		var sublist = parser.ParseList()
 		if sublist != nil {
 			list.Append(sublist)
@@ -855,15 +1339,61 @@ func (parser *Parser) ParsePropertyValue(parentRuleSet *ast.RuleSet, property *a
 		tok = parser.peek()
 	}
 
+	if parser.accept(ast.T_IMPORTANT) != nil {
+		property.Important = true
+	}
+
+	// the semicolon of the last declaration is optional, the brace end is
+	// consumed by the declaration block and the brace start of the nested
+	// properties is consumed by ParseProperty.
 	tok = parser.peek()
-	if tok.Type == ast.T_SEMICOLON || tok.Type == ast.T_BRACE_END {
+	if tok == nil {
+		// the declaration block reports the missing brace end
+	} else if tok.Type == ast.T_SEMICOLON {
 		parser.next()
+	} else if tok.Type == ast.T_BRACE_END || tok.Type == ast.T_BRACE_START {
 	} else {
 		panic(fmt.Errorf("Unexpected end of property value. Got %s", tok))
 	}
 	return list
 }
 
+/*
+ParseProperty parses the property declaration, nil is returned if the next
+token is not a property name. The nested properties are parsed from the
+block after the colon or the value:
+
+	font: { family: serif; size: 12px; }
+	margin: 0 { left: 4px; }
+*/
+func (parser *Parser) ParseProperty() *ast.Property {
+	var tok = parser.peek()
+	var propertyName = parser.ParsePropertyName()
+	if propertyName == nil {
+		return nil
+	}
+	var property = ast.NewPropertyWithName(ast.NewPropertyNameWithExpression(propertyName, tok))
+	var valueList = parser.ParsePropertyValue(parser.Context.TopRuleSet(), property)
+	property.Values = valueList.Expressions
+
+	if tok = parser.peek(); tok != nil && tok.Type == ast.T_BRACE_START {
+		parser.next()
+		parser.skipComments()
+		tok = parser.peek()
+		for tok != nil && tok.Type != ast.T_BRACE_END {
+			if subProperty := parser.ParseProperty(); subProperty != nil {
+				property.AppendProperty(subProperty)
+			} else if parser.accept(ast.T_SEMICOLON) == nil {
+				panic(fmt.Errorf("Expecting the nested property of '%s', got %s", property.Name, tok))
+			}
+			parser.skipComments()
+			tok = parser.peek()
+		}
+		parser.expect(ast.T_BRACE_END)
+	}
+	return property
+}
+
 func (parser *Parser) ParsePropertyName() ast.Expression {
 	var ident = parser.ParsePropertyNameToken()
 	if ident == nil {
@@ -871,13 +1401,15 @@ func (parser *Parser) ParsePropertyName() ast.Expression {
 	}
 
 	var tok = parser.peek()
-	for tok.Type == ast.T_LITERAL_CONCAT {
+	for tok != nil && tok.Type == ast.T_LITERAL_CONCAT {
 		parser.next()
-		_ = parser.ParsePropertyNameToken()
+		if rightIdent := parser.ParsePropertyNameToken(); rightIdent != nil {
+			ident = ast.NewLiteralConcat(ident, rightIdent)
+		}
 		tok = parser.peek()
 	}
 	parser.expect(ast.T_COLON)
-	return ident // TODO: new literal concat ast
+	return ident
 }
 
 func (parser *Parser) ParsePropertyNameToken() ast.Expression {
@@ -907,38 +1439,69 @@ func (parser *Parser) ParseDeclaration() ast.Statement {
 }
 
 func (parser *Parser) ParseDeclarationBlock() *ast.DeclarationBlock {
-	var declBlock = ast.DeclarationBlock{}
+	var declBlock = ast.NewDeclarationBlock()
 	var parentRuleSet = parser.Context.TopRuleSet()
 
+	// attach the block before parsing the declarations, so that the variable
+	// assignments inside the block could be registered to its symbol table.
+	if parentRuleSet != nil {
+		parentRuleSet.Block = declBlock
+	}
+	parser.ParseDeclarations(declBlock)
+	return declBlock
+}
+
+/*
+ParseDeclarations parses the declarations inside the braces into the block,
+the block of the mixin and the content block of @include are not attached to
+the enclosing ruleset.
+*/
+func (parser *Parser) ParseDeclarations(declBlock *ast.DeclarationBlock) {
 	parser.expect(ast.T_BRACE_START)
 
 	var tok = parser.peek()
 	for tok != nil && tok.Type != ast.T_BRACE_END {
-		var propertyName = parser.ParsePropertyName()
+		// the line comments never go to the output, ParseProperty doesn't
+		// expect them in front of the property
+		if tok.Type == ast.T_COMMENT_LINE {
+			parser.next()
+			tok = parser.peek()
+			continue
+		}
+		if property := parser.ParseProperty(); property != nil {
 
-		if propertyName != nil {
-			var property = ast.NewProperty(tok)
-			var valueList = parser.ParsePropertyValue(parentRuleSet, property)
-			_ = valueList
-			// property.Values = valueList
 			declBlock.Append(property)
-			_ = property
 
 		} else if stm := parser.ParseStatement(); stm != nil {
 
+			var stmts = []ast.Statement{stm}
+			if importStm, ok := stm.(*ast.ImportStatement); ok {
+				stmts = importStm.Expand()
+			}
+			for _, stm := range stmts {
+				if ruleset, ok := stm.(*ast.RuleSet); ok {
+					declBlock.AppendSubRuleSet(ruleset)
+				} else {
+					declBlock.Append(stm)
+				}
+			}
+
 		} else {
 			panic(fmt.Errorf("Parse failed at token %s", tok))
 		}
 		tok = parser.peek()
 	}
-	return &declBlock
+	parser.expect(ast.T_BRACE_END)
 }
 
 func (parser *Parser) ParseCharsetStatement() ast.Statement {
-	parser.accept(ast.T_CHARSET)
+	var charsetTok = parser.accept(ast.T_CHARSET)
 	var tok = parser.next()
 	var stm = ast.NewCharsetStatementWithToken(tok)
-	parser.expect(ast.T_SEMICOLON)
+	if charsetTok != nil {
+		stm.Token = charsetTok
+	}
+	parser.accept(ast.T_SEMICOLON)
 	return stm
 }
 
@@ -949,14 +1512,67 @@ func (parser *Parser) ParseCharsetStatement() ast.Statement {
 func (parser *Parser) ParseMediaQueryStatement() ast.Statement {
 	// expect the '@media' token
 	var stm = ast.NewMediaQueryStatement()
-	parser.expect(ast.T_MEDIA)
-	if list := parser.ParseMediaQueryList(); list != nil {
+	stm.Token = parser.expect(ast.T_MEDIA)
+	if parser.hasInterpolationBefore(ast.T_BRACE_START) {
+		stm.QueryTemplate = parser.ParseQueryTemplate()
+	} else if list := parser.ParseMediaQueryList(); list != nil {
 		stm.MediaQueryList = *list
 	}
-	parser.ParseBlock()
+	stm.Block = parser.ParseBlock()
 	return stm
 }
 
+/*
+hasInterpolationBefore returns true if there is an interpolation before the
+token of the given type.
+*/
+func (parser *Parser) hasInterpolationBefore(tokType ast.TokenType) bool {
+	var pos = parser.Pos
+	defer parser.restore(pos)
+	for tok := parser.peek(); tok != nil && tok.Type != tokType; tok = parser.peek() {
+		if tok.Type == ast.T_INTERPOLATION_START {
+			return true
+		}
+		parser.advance()
+	}
+	return false
+}
+
+/*
+ParseQueryTemplate parses the media queries with the interpolations as a text
+template, the spaces between the tokens are kept:
+
+	screen and #{$query}    // "screen" " " "and" " " #{$query}
+*/
+func (parser *Parser) ParseQueryTemplate() ast.Expression {
+	var parts = []ast.Expression{}
+	var prevTok *ast.Token
+	for tok := parser.peek(); tok != nil && tok.Type != ast.T_BRACE_START; tok = parser.peek() {
+		if prevTok != nil && prevTok.Pos+len(prevTok.Str) < tok.Pos {
+			parts = append(parts, ast.NewString(0, " ", tok))
+		}
+		switch tok.Type {
+		case ast.T_INTERPOLATION_START:
+			parts = append(parts, parser.ParseInterp())
+		case ast.T_VARIABLE:
+			parts = append(parts, parser.ParseVariable())
+		default:
+			parser.next()
+			parts = append(parts, ast.NewString(0, tok.Str, tok))
+		}
+		prevTok = parser.Tokens[parser.Pos-1]
+	}
+	if len(parts) == 0 {
+		panic(fmt.Errorf("Expecting media queries after @media"))
+	}
+
+	var template = parts[0]
+	for _, part := range parts[1:] {
+		template = ast.NewLiteralConcat(template, part)
+	}
+	return template
+}
+
 func (parser *Parser) ParseMediaQueryList() *[]*ast.MediaQuery {
 	var query = parser.ParseMediaQuery()
 	if query == nil {
@@ -1029,13 +1645,13 @@ func (parser *Parser) ParseMediaType() ast.Expression {
 		parser.next()
 
 		var mediaType = parser.expect(ast.T_IDENT)
-		return ast.NewUnaryExpression(ast.NewOpWithToken(tok), mediaType)
+		return ast.NewUnaryExpression(ast.NewOpWithToken(tok), ast.NewIdentWithToken(mediaType))
 
 	} else if tok.Type == ast.T_ONLY {
 		parser.next()
 
 		var mediaType = parser.expect(ast.T_IDENT)
-		return ast.NewUnaryExpression(ast.NewOpWithToken(tok), mediaType)
+		return ast.NewUnaryExpression(ast.NewOpWithToken(tok), ast.NewIdentWithToken(mediaType))
 	}
 
 	// expecting media type token (it will be T_IDENT)
@@ -1073,6 +1689,204 @@ func (parser *Parser) ParseMediaQueryExpression() ast.Expression {
 	return feature
 }
 
+/*
+ParseSupportsStatement parses the @supports statement:
+
+	@supports not (display: flex) { ... }
+	@supports (display: flex) and ((display: grid) or (display: table)) { ... }
+*/
+func (parser *Parser) ParseSupportsStatement() ast.Statement {
+	var stm = ast.NewSupportsStatementWithToken(parser.expect(ast.T_SUPPORTS))
+	stm.Condition = parser.ParseSupportsCondition()
+	stm.Block = parser.ParseBlock()
+	return stm
+}
+
+/*
+ParseSupportsCondition parses the conditions joined by "and" and "or", or the
+condition negated by "not".
+*/
+func (parser *Parser) ParseSupportsCondition() ast.Expression {
+	if tok := parser.peek(); tok != nil && tok.Type == ast.T_LOGICAL_NOT {
+		parser.next()
+		return ast.NewUnaryExpression(ast.NewOpWithToken(tok), parser.ParseSupportsConditionInParens())
+	}
+
+	var condition = parser.ParseSupportsConditionInParens()
+	for {
+		var tok = parser.peek()
+		if tok == nil || (tok.Type != ast.T_LOGICAL_AND && tok.Type != ast.T_LOGICAL_OR) {
+			break
+		}
+		parser.next()
+		condition = ast.NewBinaryExpression(ast.NewOpWithToken(tok), condition, parser.ParseSupportsConditionInParens(), false)
+	}
+	return condition
+}
+
+/*
+ParseSupportsConditionInParens parses the condition in the parentheses, which
+is a declaration or a nested condition:
+
+	(display: flex)
+	(not (display: grid))
+*/
+func (parser *Parser) ParseSupportsConditionInParens() ast.Expression {
+	var startTok = parser.expect(ast.T_PAREN_START)
+
+	var tok = parser.peek()
+	if tok.Type == ast.T_PAREN_START || tok.Type == ast.T_LOGICAL_NOT {
+		var condition = parser.ParseSupportsCondition()
+		parser.expect(ast.T_PAREN_END)
+		return condition
+	}
+
+	// the lexer puts the literal concats around the interpolation of the
+	// property, e.g. "(#{$prop}: flex)"
+	parser.skipLiteralConcat()
+	var property = parser.ParseFactor()
+	parser.skipLiteralConcat()
+	parser.expect(ast.T_COLON)
+
+	// the value may be a space-separated list, e.g. "(transform-origin: 5% 5%)"
+	var values = ast.NewSpaceSepList()
+	for tok = parser.peek(); tok != nil && tok.Type != ast.T_PAREN_END; tok = parser.peek() {
+		var value = parser.ParseExpression(false)
+		if value == nil {
+			panic(fmt.Errorf("Unexpected token %s in @supports condition", tok))
+		}
+		values.Append(value)
+	}
+	parser.expect(ast.T_PAREN_END)
+
+	var value ast.Expression = values
+	if values.Len() == 1 {
+		value = values.Expressions[0]
+	}
+	return ast.NewSupportsDeclarationWithToken(property, value, startTok)
+}
+
+func (parser *Parser) skipLiteralConcat() {
+	if tok := parser.peek(); tok != nil && tok.Type == ast.T_LITERAL_CONCAT {
+		parser.next()
+	}
+}
+
+/*
+ParseKeyframesStatement parses the @keyframes statement, the keyframe blocks
+are parsed as the rulesets:
+
+	@keyframes fade { from { opacity: 0; } 50% { opacity: 0.5; } to { opacity: 1; } }
+*/
+func (parser *Parser) ParseKeyframesStatement() ast.Statement {
+	var stm = ast.NewKeyframesStatementWithToken(parser.expect(ast.T_KEYFRAMES))
+	var nameTok = parser.expect(ast.T_UNQUOTE_STRING)
+	stm.Name = parser.ParseTextTemplate(nameTok.Str, nameTok)
+	stm.Block = parser.ParseBlock()
+	return stm
+}
+
+func (parser *Parser) ParseFontFaceStatement() ast.Statement {
+	var stm = ast.NewFontFaceStatementWithToken(parser.expect(ast.T_FONT_FACE))
+	stm.Block = ast.NewDeclarationBlock()
+	parser.ParseDeclarations(stm.Block)
+	return stm
+}
+
+func (parser *Parser) ParsePageStatement() ast.Statement {
+	var stm = ast.NewPageStatementWithToken(parser.expect(ast.T_PAGE))
+	if tok := parser.peek(); tok != nil && tok.Type == ast.T_UNQUOTE_STRING {
+		parser.next()
+		stm.Selector = parser.ParseTextTemplate(tok.Str, tok)
+	}
+	stm.Block = ast.NewDeclarationBlock()
+	parser.ParseDeclarations(stm.Block)
+	return stm
+}
+
+func (parser *Parser) ParseNamespaceStatement() ast.Statement {
+	var stm = ast.NewNamespaceStatementWithToken(parser.expect(ast.T_NAMESPACE))
+	var tok = parser.expect(ast.T_UNQUOTE_STRING)
+	stm.Prelude = parser.ParseTextTemplate(tok.Str, tok)
+	if tok := parser.peek(); tok != nil && tok.Type == ast.T_SEMICOLON {
+		parser.next()
+	}
+	return stm
+}
+
+/*
+ParseAtRuleStatement parses the unknown at-rule, the prelude is kept as the
+text and the block is parsed like the block of @media:
+
+	@viewport { width: device-width; }
+	@custom-selector :--heading h1, h2;
+*/
+func (parser *Parser) ParseAtRuleStatement() ast.Statement {
+	var stm = ast.NewAtRuleStatementWithToken(parser.expect(ast.T_AT_RULE))
+	var tok = parser.peek()
+	if tok != nil && tok.Type == ast.T_UNQUOTE_STRING {
+		parser.next()
+		stm.Prelude = parser.ParseTextTemplate(tok.Str, tok)
+		tok = parser.peek()
+	}
+	if tok != nil && tok.Type == ast.T_BRACE_START {
+		stm.Block = parser.ParseBlock()
+	} else if tok != nil && tok.Type == ast.T_SEMICOLON {
+		parser.next()
+	}
+	return stm
+}
+
+/*
+ParseAtRootStatement parses @at-root with the optional query, the selector
+after @at-root is parsed as a ruleset inside the block:
+
+	@at-root .b { ... }
+	@at-root (without: media supports) { ... }
+
+The rulesets inside are parsed with the enclosing ruleset on the stack of the
+context, the parent selector "&" still refers to the enclosing ruleset.
+*/
+func (parser *Parser) ParseAtRootStatement() ast.Statement {
+	var stm = ast.NewAtRootStatementWithToken(parser.expect(ast.T_AT_ROOT))
+	if tok := parser.peek(); tok != nil && tok.Type == ast.T_PAREN_START {
+		stm.Query = parser.ParseAtRootQuery()
+	}
+	if tok := parser.peek(); tok != nil && tok.Type == ast.T_BRACE_START {
+		stm.Block = parser.ParseBlock()
+	} else if tok != nil && tok.IsSelector() {
+		stm.Block = ast.NewBlock()
+		stm.Block.AppendStatement(parser.ParseRuleSet())
+	} else {
+		panic(fmt.Errorf("Expecting selector or '{' after @at-root. Got %s", tok))
+	}
+	return stm
+}
+
+/*
+ParseAtRootQuery parses the query of @at-root, e.g. "(with: media rule)".
+*/
+func (parser *Parser) ParseAtRootQuery() *ast.AtRootQuery {
+	parser.expect(ast.T_PAREN_START)
+	var keyword = parser.expect(ast.T_IDENT)
+	var keywordName = strings.ToLower(keyword.Str)
+	if keywordName != "with" && keywordName != "without" {
+		panic(fmt.Errorf("Expecting 'with' or 'without' in the @at-root query. Got %s", keyword))
+	}
+	parser.expect(ast.T_COLON)
+
+	var query = ast.NewAtRootQuery(keywordName == "with")
+	for tok := parser.peek(); tok != nil && tok.Type == ast.T_IDENT; tok = parser.peek() {
+		parser.next()
+		query.Names = append(query.Names, strings.ToLower(tok.Str))
+	}
+	if len(query.Names) == 0 {
+		panic(fmt.Errorf("Expecting the names of the at-rules in the @at-root query. Got %s", parser.peek()))
+	}
+	parser.expect(ast.T_PAREN_END)
+	return query
+}
+
 func (parser *Parser) ParseWhileStatement() ast.Statement {
 	parser.expect(ast.T_WHILE)
 	var condition = parser.ParseCondition()
@@ -1080,6 +1894,35 @@ func (parser *Parser) ParseWhileStatement() ast.Statement {
 	return ast.NewWhileStatement(condition, block)
 }
 
+/*
+Parse the SASS @each statement.
+
+	@each $item in <list> {  }
+
+	@each $key, $value in <map> {  }
+*/
+func (parser *Parser) ParseEachStatement() ast.Statement {
+	var stm = ast.NewEachStatement(parser.expect(ast.T_EACH))
+
+	stm.Variables = append(stm.Variables, parser.ParseVariable())
+	for parser.accept(ast.T_COMMA) != nil {
+		stm.Variables = append(stm.Variables, parser.ParseVariable())
+	}
+	for _, variable := range stm.Variables {
+		if variable == nil {
+			panic(fmt.Errorf("Expecting the variables of @each, got %s", parser.peek()))
+		}
+	}
+
+	parser.expect(ast.T_FOR_IN)
+	stm.List = parser.ParseValue(ast.T_BRACE_START)
+	if stm.List == nil {
+		panic(fmt.Errorf("Expecting the list of @each, got %s", parser.peek()))
+	}
+	stm.Block = parser.ParseBlock()
+	return stm
+}
+
 /*
 Parse the SASS @for statement.
 
@@ -1090,11 +1933,12 @@ Parse the SASS @for statement.
 @see http://sass-lang.com/documentation/file.SASS_REFERENCE.html#_10
 */
 func (parser *Parser) ParseForStatement() ast.Statement {
-	parser.expect(ast.T_FOR)
+	var forTok = parser.expect(ast.T_FOR)
 
 	// get the variable token
 	var variable = parser.ParseVariable()
 	var stm = ast.NewForStatement(variable)
+	stm.Token = forTok
 
 	if parser.accept(ast.T_FOR_FROM) != nil {
 
@@ -1152,25 +1996,225 @@ func (parser *Parser) ParseForStatement() ast.Statement {
 	return stm
 }
 
+/*
+ParseMixinStatement parses the mixin definition, the parentheses are
+optional if the mixin has no parameters:
+
+	@mixin large-text { font-size: 32px; }
+	@mixin button($color, $size: 12px) { ... }
+*/
+func (parser *Parser) ParseMixinStatement() ast.Statement {
+	var mixinTok = parser.expect(ast.T_MIXIN)
+
+	var nameTok = parser.next()
+	if nameTok == nil || (nameTok.Type != ast.T_IDENT && nameTok.Type != ast.T_FUNCTION_NAME) {
+		panic(fmt.Errorf("Expecting mixin name after @mixin, got %s", nameTok))
+	}
+
+	var stm = ast.NewMixinStatement(nameTok.Str, mixinTok)
+	if nameTok.Type == ast.T_FUNCTION_NAME {
+		stm.Parameters = parser.ParseParameters()
+	}
+	stm.Block = ast.NewDeclarationBlock()
+	parser.ParseDeclarations(stm.Block)
+	return stm
+}
+
+/*
+ParseIncludeStatement parses the mixin call with the optional arguments and
+the optional content block:
+
+	@include large-text;
+	@include button(red, $size: 14px);
+	@include hover { color: red; }
+*/
+func (parser *Parser) ParseIncludeStatement() ast.Statement {
+	var includeTok = parser.expect(ast.T_INCLUDE)
+
+	var nameTok = parser.next()
+	if nameTok == nil || (nameTok.Type != ast.T_IDENT && nameTok.Type != ast.T_FUNCTION_NAME) {
+		panic(fmt.Errorf("Expecting mixin name after @include, got %s", nameTok))
+	}
+
+	var stm = ast.NewIncludeStatement(nameTok.Str, includeTok)
+	if nameTok.Type == ast.T_FUNCTION_NAME {
+		stm.Arguments = parser.ParseArguments()
+	}
+
+	if tok := parser.peek(); tok != nil && tok.Type == ast.T_BRACE_START {
+		stm.ContentBlock = ast.NewDeclarationBlock()
+		parser.ParseDeclarations(stm.ContentBlock)
+	} else {
+		parser.accept(ast.T_SEMICOLON)
+	}
+	return stm
+}
+
+/*
+ParseFunctionStatement parses the function definition, the function body may
+contain the variable assignments, the control statements, @debug, @warn,
+@error and @return.
+*/
+func (parser *Parser) ParseFunctionStatement() ast.Statement {
+	var functionTok = parser.expect(ast.T_FUNCTION)
+
+	var nameTok = parser.next()
+	if nameTok == nil || nameTok.Type != ast.T_FUNCTION_NAME {
+		panic(fmt.Errorf("Expecting function name and parameters after @function, got %s", nameTok))
+	}
+
+	var stm = ast.NewFunctionStatement(nameTok.Str, functionTok)
+	stm.Parameters = parser.ParseParameters()
+	parser.functionDepth++
+	stm.Block = parser.ParseBlock()
+	parser.functionDepth--
+	return stm
+}
+
+func (parser *Parser) ParseReturnStatement() ast.Statement {
+	var tok = parser.expect(ast.T_RETURN)
+	if parser.functionDepth == 0 {
+		panic(fmt.Errorf("This at-rule is not allowed here"))
+	}
+	var value = parser.ParseValue(ast.T_SEMICOLON)
+	if value == nil {
+		panic("Expecting value after @return.")
+	}
+	parser.accept(ast.T_SEMICOLON)
+	return ast.NewReturnStatement(value, tok)
+}
+
+/*
+ParseDiagnosticStatement parses the @debug, @warn and @error statements:
+
+	@debug 10em + 12em;
+	@warn "The mixin is deprecated";
+	@error "Unknown direction #{$direction}";
+*/
+func (parser *Parser) ParseDiagnosticStatement() ast.Statement {
+	var tok = parser.next()
+	var value = parser.ParseValue(ast.T_SEMICOLON)
+	if value == nil {
+		panic(fmt.Errorf("Expecting value after %s.", tok.Str))
+	}
+	parser.accept(ast.T_SEMICOLON)
+	switch tok.Type {
+	case ast.T_DEBUG:
+		return ast.NewDebugStatement(value, tok)
+	case ast.T_WARN:
+		return ast.NewWarnStatement(value, tok)
+	}
+	return ast.NewErrorStatement(value, tok)
+}
+
+func (parser *Parser) ParseContentStatement() ast.Statement {
+	var tok = parser.expect(ast.T_CONTENT)
+	parser.accept(ast.T_SEMICOLON)
+	return ast.NewContentStatementWithToken(tok)
+}
+
+/*
+ParseExtendStatement parses the @extend statement, the target must be a
+compound selector:
+
+	@extend .message;
+	@extend a.link:hover !optional;
+*/
+func (parser *Parser) ParseExtendStatement() ast.Statement {
+	var stm = ast.NewExtendStatementWithToken(parser.expect(ast.T_EXTEND))
+
+	var tok = parser.next()
+	for tok != nil && tok.IsSelector() {
+		if tok.IsSelectorCombinator() {
+			panic(fmt.Errorf("Can't extend the complex selector, expecting a compound selector, got %s", tok))
+		}
+		stm.Selectors = append(stm.Selectors, parser.ParseSelector(tok, parser.Context.TopRuleSet()))
+		tok = parser.next()
+	}
+	parser.backup()
+
+	if len(stm.Selectors) == 0 {
+		panic(fmt.Errorf("Expecting the selector of @extend, got %s", tok))
+	}
+	if parser.accept(ast.T_OPTIONAL) != nil {
+		stm.Optional = true
+	}
+	parser.accept(ast.T_SEMICOLON)
+	return stm
+}
+
 /*
 The @import syntax is described here:
 
 @see CSS2.1 http://www.w3.org/TR/CSS2/cascade.html#at-import
 
 @see https://developer.mozilla.org/en-US/docs/Web/CSS/@import
+
+The imported files are parsed into the Statements of the ImportStatement:
+
+	@import "foo", "bar";
+	@import url(foo.css) screen;
 */
 func (parser *Parser) ParseImportStatement() ast.Statement {
-	// skip the ast.T_IMPORT token
-	parser.expect(ast.T_IMPORT)
+	// skip the ast.T_IMPORT or ast.T_IMPORT_ONCE token
+	var importTok = parser.next()
+	if importTok.Type != ast.T_IMPORT && importTok.Type != ast.T_IMPORT_ONCE {
+		panic(ParserError{"@import", importTok.Str})
+	}
+
+	var stmts = []ast.Statement{}
+	for {
+		// Create the import statement node
+		var stm = ast.NewImportStatement()
+		stm.Token = importTok
+		stm.Once = importTok.Type == ast.T_IMPORT_ONCE
+		stm.Url = parser.ParseImportUrl()
+		stmts = append(stmts, stm)
+		if parser.accept(ast.T_COMMA) == nil {
+			break
+		}
+	}
 
-	// Create the import statement node
-	var stm = ast.NewImportStatement()
+	var mediaQueryList = []*ast.MediaQuery{}
+	if list := parser.ParseMediaQueryList(); list != nil {
+		mediaQueryList = *list
+	}
+
+	// must be ast.T_SEMICOLON
+	var tok = parser.next()
+	if tok == nil || tok.Type != ast.T_SEMICOLON {
+		var actual = "end of file"
+		if tok != nil {
+			actual = tok.Str
+		}
+		panic(ParserError{";", actual})
+	}
+
+	for _, anyStm := range stmts {
+		var stm = anyStm.(*ast.ImportStatement)
+		stm.MediaQueryList = mediaQueryList
+		if !IsCssImport(stm) {
+			parser.ImportFile(stm)
+		}
+	}
+
+	if len(stmts) == 1 {
+		return stmts[0]
+	}
+	var group = ast.NewImportStatement()
+	group.Token = importTok
+	group.Statements = stmts
+	return group
+}
+
+func (parser *Parser) ParseImportUrl() interface{} {
+	var tok = parser.next()
+	if tok == nil {
+		panic("Expecting url after @import, got end of file")
+	}
 
-	var tok = parser.peek()
 	// expecting url(..)
 	if tok.Type == ast.T_IDENT {
-		parser.advance()
-
 		if tok.Str != "url" {
 			panic("invalid function for @import statement.")
 		}
@@ -1180,23 +2224,15 @@ func (parser *Parser) ParseImportStatement() ast.Statement {
 		}
 
 		tok = parser.next()
-		stm.Url = ast.Url(tok.Str)
+		var url = ast.Url(tok.Str)
 
 		if tok = parser.next(); tok.Type != ast.T_PAREN_END {
 			panic("expecting parenthesis after url")
 		}
+		return url
 
 	} else if tok.IsString() {
-		parser.advance()
-		stm.Url = ast.RelativeUrl(tok.Str)
-	}
-
-	parser.ParseMediaQueryList()
-
-	// must be ast.T_SEMICOLON
-	tok = parser.next()
-	if tok.Type != ast.T_SEMICOLON {
-		panic(ParserError{";", tok.Str})
+		return ast.RelativeUrl(tok.Str)
 	}
-	return stm
+	panic(fmt.Errorf("Expecting url after @import, got %s", tok))
 }
@@ -4,6 +4,10 @@ import (
 	"c6/ast"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -292,6 +296,22 @@ func TestParserIfComparisonUnequal(t *testing.T) {
 func TestParserIfComparisonUnequalElseIf(t *testing.T) {
 	var stmts = RunParserTest(`@if (3+3) != 6 {  } @else if (3+3) == 6 {  } @else {  }`)
 	assert.Equal(t, 1, len(stmts))
+
+	ifStm, ok := stmts[0].(*ast.IfStatement)
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(ifStm.ElseIfs))
+	assert.NotNil(t, ifStm.ElseBlock)
+}
+
+func TestParserIfConditionWithLogicalNot(t *testing.T) {
+	var stmts = RunParserTest(`@if $a and not $b {  }`)
+	ifStm, ok := stmts[0].(*ast.IfStatement)
+	assert.True(t, ok)
+
+	and, ok := ifStm.Condition.(*ast.BinaryExpression)
+	assert.True(t, ok)
+	assert.Equal(t, ast.T_LOGICAL_AND, and.Op.Type)
+	assert.IsType(t, &ast.UnaryExpression{}, and.Right)
 }
 
 func TestParserForStatementSimple(t *testing.T) {
@@ -320,7 +340,8 @@ $i: 6;
 @while $i > 0 { $i: $i - 2; }
 `
 	var stmts = RunParserTest(code)
-	assert.Equal(t, 1, len(stmts))
+	assert.Equal(t, 2, len(stmts))
+	assert.IsType(t, &ast.WhileStatement{}, stmts[1])
 }
 
 func TestParserCSS3Gradient(t *testing.T) {
@@ -355,6 +376,44 @@ func TestParserPropertyListExpression(t *testing.T) {
 	}
 }
 
+func TestParserLineCommentsInBlocks(t *testing.T) {
+	css, err := evaluateScss(`
+	@mixin m { // the mixin
+		e: 1;
+	}
+	@function f() { // the function
+		@return 2;
+	}
+	.a {
+		// the first line
+		color: red; // trailing
+		/* kept */
+		@include m;
+		@if true { // inside @if
+			g: f();
+		}
+	}`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { color: red; /* kept */ e: 1; g: 2; }\n", css)
+}
+
+func TestParserSpaceSepListWithNegativeValues(t *testing.T) {
+	css, err := evaluateScss(`
+	$a: 1px;
+	$b: 2px;
+	.a {
+		a: 0 -1px;
+		b: 1px -2px 3px;
+		c: $a -$b;
+		d: 0.5em -0.5em;
+		e: 3px - 1px;
+		f: $a - $b;
+		g: 3px-1px;
+	}`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { a: 0 -1px; b: 1px -2px 3px; c: 1px -2px; d: 0.5em -0.5em; e: 2px; f: -1px; g: 2px; }\n", css)
+}
+
 func TestParserFontCssSlash(t *testing.T) {
 	// should be plain CSS, no division
 	// TODO: verify this case
@@ -466,3 +525,231 @@ func TestParserIfStatementTrueCondition(t *testing.T) {
 	_ = block
 }
 */
+
+func TestParserMultipleRuleSets(t *testing.T) {
+	var stmts = RunParserTest(`div { color: red } span { } p { float: left; }`)
+	assert.Equal(t, 3, len(stmts))
+}
+
+func TestParserRuleSetProperties(t *testing.T) {
+	var stmts = RunParserTest(`div { color: red; margin: 0 auto !important; .foo { } }`)
+	assert.Equal(t, 1, len(stmts))
+
+	ruleset, ok := stmts[0].(*ast.RuleSet)
+	assert.True(t, ok)
+	assert.Equal(t, 2, len(ruleset.Block.Statements))
+	assert.Equal(t, 1, len(ruleset.Block.SubRuleSets))
+
+	property, ok := ruleset.Block.Statements[1].(*ast.Property)
+	assert.True(t, ok)
+	assert.Equal(t, "margin", property.Name.String())
+	assert.True(t, property.Important)
+	assert.Equal(t, 1, len(property.Values))
+}
+
+func TestParserLineComment(t *testing.T) {
+	var stmts = RunParserTest("// comment\ndiv { color: red } // trailing\n")
+	assert.Equal(t, 1, len(stmts))
+}
+
+func TestParserCharsetStatement(t *testing.T) {
+	var stmts = RunParserTest(`@charset "UTF-8";`)
+	assert.Equal(t, 1, len(stmts))
+	stm, ok := stmts[0].(*ast.CharsetStatement)
+	assert.True(t, ok)
+	assert.Equal(t, "UTF-8", stm.Encoding)
+}
+
+func TestParserParseFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var path = filepath.Join(dir, "main.scss")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`div { color: red; } p { }`), 0644))
+
+	var parser = NewParser(NewContext())
+	stmts, err := parser.ParseFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(stmts))
+	assert.Equal(t, path, parser.File)
+}
+
+func TestParserParseFileNotFound(t *testing.T) {
+	var parser = NewParser(NewContext())
+	_, err := parser.ParseFile("does-not-exist.scss")
+	assert.NotNil(t, err)
+}
+
+func TestParserParseError(t *testing.T) {
+	var parser = NewParser(NewContext())
+	parser.File = "main.scss"
+	_, err := parser.Parse("div { color: red; }\n\np { color: red;", ScssFileType)
+	assert.NotNil(t, err)
+
+	parseErr, ok := err.(*ParseError)
+	assert.True(t, ok)
+	assert.Equal(t, "main.scss", parseErr.File)
+	assert.Equal(t, 3, parseErr.Line)
+	assert.Equal(t, "main.scss:3: Expecting T_BRACE_END, Got end of file", err.Error())
+}
+
+func TestParserTruncatedInput(t *testing.T) {
+	var cases = map[string]string{
+		`a { b: 1`:     "Expecting T_BRACE_END, Got end of file",
+		`a { b: 1px`:   "Expecting T_BRACE_END, Got end of file",
+		`a { b: 1 2`:   "Expecting T_BRACE_END, Got end of file",
+		`a { b: 1,`:    "Expecting T_BRACE_END, Got end of file",
+		`a { b: f(1`:   "Expecting T_PAREN_END, Got end of file",
+		`a { b: (1`:    "Expecting T_PAREN_END, Got end of file",
+		`@include a(1`: "Expecting T_PAREN_END, Got end of file",
+	}
+	for code, message := range cases {
+		_, err := NewParser(NewContext()).Parse(code, ScssFileType)
+		if assert.NotNil(t, err, code) {
+			assert.Contains(t, err.Error(), message, code)
+		}
+	}
+}
+
+func TestParserLargeInput(t *testing.T) {
+	var code = strings.Repeat("div { color: red; margin: 0 auto; }\n", 500)
+	var stmts = RunParserTest(code)
+	assert.Equal(t, 500, len(stmts))
+}
+
+func TestParserNestedProperties(t *testing.T) {
+	var stmts = RunParserTest(`.a { font: bold { family: serif; size: 12px; } }`)
+	assert.Equal(t, 1, len(stmts))
+
+	ruleset, ok := stmts[0].(*ast.RuleSet)
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(ruleset.Block.Statements))
+
+	property, ok := ruleset.Block.Statements[0].(*ast.Property)
+	assert.True(t, ok)
+	assert.Equal(t, "font", property.Name.String())
+	assert.Equal(t, 1, len(property.Values))
+	assert.Equal(t, 2, len(property.Properties))
+	assert.Equal(t, "family", property.Properties[0].Name.String())
+	assert.Equal(t, "size", property.Properties[1].Name.String())
+}
+
+func TestEvaluateNestedProperties(t *testing.T) {
+	css, err := evaluateScss(`
+	$size: 4px;
+	.a { margin: 0 { left: $size * 2; } font: { family: serif; } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { margin: 0; margin-left: 8px; font-family: serif; }\n", css)
+}
+
+func TestParserSelectorGroup(t *testing.T) {
+	var stmts = RunParserTest(`div, span > a, .b.c { }`)
+	assert.Equal(t, 1, len(stmts))
+
+	ruleset, ok := stmts[0].(*ast.RuleSet)
+	assert.True(t, ok)
+	assert.Equal(t, 3, len(ruleset.Selectors))
+	assert.Equal(t, 1, len(ruleset.Selectors[0]))
+	assert.Equal(t, 3, len(ruleset.Selectors[1]))
+	assert.IsType(t, &ast.ChildCombinator{}, ruleset.Selectors[1][1])
+	assert.Equal(t, 2, len(ruleset.Selectors[2]))
+	assert.Equal(t, "div, span > a, .b.c", ruleset.Selectors.String())
+}
+
+func TestParserAttributeSelector(t *testing.T) {
+	var stmts = RunParserTest(`a[href="x"], [data-role] .b { }`)
+	ruleset, ok := stmts[0].(*ast.RuleSet)
+	assert.True(t, ok)
+	assert.Nil(t, ruleset.SelectorTemplate)
+	assert.Equal(t, 2, len(ruleset.Selectors))
+	assert.IsType(t, &ast.AttributeSelector{}, ruleset.Selectors[0][1])
+	assert.Equal(t, "a[href=\"x\"], [data-role] .b", ruleset.Selectors.String())
+}
+
+func TestParserSelectorWithInterpolation(t *testing.T) {
+	var stmts = RunParserTest(`.icon-#{$name} > li, #{$sel} { }`)
+	ruleset, ok := stmts[0].(*ast.RuleSet)
+	assert.True(t, ok)
+	assert.Nil(t, ruleset.Selectors)
+	assert.NotNil(t, ruleset.SelectorTemplate)
+	assert.Equal(t, ".icon-$name > li, $sel", ruleset.SelectorTemplate.String())
+}
+
+func TestParserSupportsStatement(t *testing.T) {
+	var stmts = RunParserTest(`@supports (display: flex) and (not (display: grid)) { .a { display: flex; } }`)
+	assert.Equal(t, 1, len(stmts))
+	supports, ok := stmts[0].(*ast.SupportsStatement)
+	assert.True(t, ok)
+	condition, ok := supports.Condition.(*ast.BinaryExpression)
+	assert.True(t, ok)
+	assert.Equal(t, ast.T_LOGICAL_AND, condition.Op.Type)
+	assert.IsType(t, &ast.SupportsDeclaration{}, condition.Left)
+	assert.IsType(t, &ast.UnaryExpression{}, condition.Right)
+	assert.Equal(t, 1, len(supports.Block.Statements))
+}
+
+func TestParserKeyframesStatement(t *testing.T) {
+	var stmts = RunParserTest(`@keyframes fade { from { opacity: 0; } 50% { opacity: 0.5; } }`)
+	keyframes, ok := stmts[0].(*ast.KeyframesStatement)
+	assert.True(t, ok)
+	assert.Equal(t, "@keyframes", keyframes.Keyword)
+	assert.Equal(t, "fade", keyframes.Name.String())
+	assert.Equal(t, 2, len(keyframes.Block.Statements))
+	ruleset, ok := keyframes.Block.Statements[1].(*ast.RuleSet)
+	assert.True(t, ok)
+	assert.Equal(t, "50%", ruleset.Selectors.String())
+}
+
+func TestParserFontFaceAndPageStatement(t *testing.T) {
+	var stmts = RunParserTest(`@font-face { font-family: "Open Sans"; } @page :first { margin: 1in; }`)
+	assert.Equal(t, 2, len(stmts))
+	fontFace, ok := stmts[0].(*ast.FontFaceStatement)
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(fontFace.Block.Statements))
+	page, ok := stmts[1].(*ast.PageStatement)
+	assert.True(t, ok)
+	assert.Equal(t, ":first", page.Selector.String())
+	assert.Equal(t, 1, len(page.Block.Statements))
+}
+
+func TestParserUnknownAtRule(t *testing.T) {
+	var stmts = RunParserTest(`@namespace svg url(x); @custom-selector :--heading h1, h2; @viewport { width: device-width; }`)
+	assert.Equal(t, 3, len(stmts))
+	namespace, ok := stmts[0].(*ast.NamespaceStatement)
+	assert.True(t, ok)
+	assert.Equal(t, "svg url(x)", namespace.Prelude.String())
+	atRule, ok := stmts[1].(*ast.AtRuleStatement)
+	assert.True(t, ok)
+	assert.Equal(t, "@custom-selector", atRule.Name)
+	assert.Equal(t, ":--heading h1, h2", atRule.Prelude.String())
+	assert.Nil(t, atRule.Block)
+	atRule, ok = stmts[2].(*ast.AtRuleStatement)
+	assert.True(t, ok)
+	assert.Nil(t, atRule.Prelude)
+	assert.Equal(t, 1, len(atRule.Block.Statements))
+}
+
+func TestParserAtRootStatement(t *testing.T) {
+	var stmts = RunParserTest(`.a { @at-root .b & { color: red; } @at-root (with: Media rule) { color: blue; } }`)
+	assert.Equal(t, 1, len(stmts))
+	var ruleset = stmts[0].(*ast.RuleSet)
+	assert.Equal(t, 2, len(ruleset.Block.Statements))
+
+	atRoot, ok := ruleset.Block.Statements[0].(*ast.AtRootStatement)
+	if assert.True(t, ok) {
+		assert.Nil(t, atRoot.Query)
+		assert.Equal(t, 1, len(atRoot.Block.Statements))
+		inner, ok := atRoot.Block.Statements[0].(*ast.RuleSet)
+		if assert.True(t, ok) {
+			assert.True(t, inner.Selectors.HasParentSelector())
+		}
+	}
+
+	atRoot, ok = ruleset.Block.Statements[1].(*ast.AtRootStatement)
+	if assert.True(t, ok) {
+		assert.True(t, atRoot.Query.With)
+		assert.Equal(t, []string{"media", "rule"}, atRoot.Query.Names)
+		assert.Equal(t, "@at-root (with: media rule)", atRoot.String())
+	}
+}
@@ -0,0 +1,282 @@
+package runtime
+
+import "c6/ast"
+import "strings"
+
+/*
+atRootFrame is a ruleset or an at-rule enclosing @at-root.
+*/
+type atRootFrame struct {
+	// "rule" for the ruleset, or the name of the at-rule without "@"
+	Name string
+
+	Statement ast.Statement
+
+	// the resolved selectors of the ruleset, or of the ruleset enclosing
+	// the at-rule, nil if there is none.
+	Selectors ast.SelectorGroup
+}
+
+/*
+atRootEscape is the statements of @at-root moved out of the frames, they're
+put next to the frame at Depth.
+*/
+type atRootEscape struct {
+	Depth      int
+	Statements []ast.Statement
+}
+
+/*
+ResolveAtRoot moves the statements of @at-root out of the rulesets and the
+at-rules excluded by the query. The statements are put next to the outermost
+excluded frame, wrapped by the frames which are not excluded:
+
+	.a { @media print { .b { @at-root (without: media) { color: red } } } }
+
+becomes
+
+	.a { @media print { .b { } } .b { color: red } }
+
+The parent selectors inside @at-root are resolved before the rulesets are
+moved out, so "&" still refers to the enclosing ruleset:
+
+	.a { @at-root .b & { ... } }    // .b .a
+
+The given statements are the output of the interpreter, their blocks are
+changed in place.
+*/
+func ResolveAtRoot(stmts []ast.Statement) []ast.Statement {
+	var out, _ = resolveAtRootStatements(stmts, nil)
+	return out
+}
+
+/*
+resolveAtRootStatements resolves @at-root in the statements of the top level,
+the block of a ruleset or an at-rule. The escapes which are not put in the
+statements are returned.
+*/
+func resolveAtRootStatements(stmts []ast.Statement, frames []*atRootFrame) ([]ast.Statement, []*atRootEscape) {
+	var depth = len(frames)
+	var out = []ast.Statement{}
+	var escapes = []*atRootEscape{}
+	var place = func(subEscapes []*atRootEscape) {
+		for _, escape := range subEscapes {
+			if escape.Depth == depth {
+				out = append(out, escape.Statements...)
+			} else {
+				escapes = append(escapes, escape)
+			}
+		}
+	}
+
+	for _, anyStm := range stmts {
+		switch stm := anyStm.(type) {
+		case *ast.AtRootStatement:
+			var atRootStmts, subEscapes = resolveAtRootStatement(stm, frames)
+			out = append(out, atRootStmts...)
+			place(subEscapes)
+		case *ast.RuleSet:
+			out = append(out, stm)
+			place(resolveAtRootRuleSet(stm, frames))
+		case *ast.MediaQueryStatement, *ast.SupportsStatement:
+			var subEscapes = resolveAtRootBlock(stm, frames)
+			// the block becomes empty if all of its rules are moved out
+			if !isEmptyAtRootFrame(stm) {
+				out = append(out, stm)
+			}
+			place(subEscapes)
+		case *ast.AtRuleStatement:
+			out = append(out, stm)
+			place(resolveAtRootBlock(stm, frames))
+		default:
+			out = append(out, anyStm)
+		}
+	}
+	return out, escapes
+}
+
+func resolveAtRootRuleSet(ruleset *ast.RuleSet, frames []*atRootFrame) []*atRootEscape {
+	if ruleset.Block == nil {
+		return nil
+	}
+	var selectors = ruleset.Selectors
+	if parents := frameSelectors(frames); parents != nil {
+		selectors = selectors.ResolveParent(parents)
+	}
+	var subFrames = append(append([]*atRootFrame{}, frames...), &atRootFrame{"rule", ruleset, selectors})
+
+	var stmts = blockStatements(ruleset.Block)
+	ruleset.Block.Statements = []ast.Statement{}
+	ruleset.Block.SubRuleSets = []*ast.RuleSet{}
+	ruleset.Block.SubRuleSetOffsets = nil
+
+	var out, escapes = resolveAtRootStatements(stmts, subFrames)
+	appendDeclarations(ruleset.Block, out)
+	return escapes
+}
+
+func resolveAtRootBlock(stm ast.Statement, frames []*atRootFrame) []*atRootEscape {
+	var block = atRuleBlock(stm)
+	if block == nil {
+		return nil
+	}
+	var name = "media"
+	switch stm := stm.(type) {
+	case *ast.SupportsStatement:
+		name = "supports"
+	case *ast.AtRuleStatement:
+		name = strings.ToLower(strings.TrimPrefix(stm.Name, "@"))
+	}
+	var subFrames = append(append([]*atRootFrame{}, frames...), &atRootFrame{name, stm, frameSelectors(frames)})
+
+	var out, escapes = resolveAtRootStatements(block.Statements, subFrames)
+	block.Statements = out
+	return escapes
+}
+
+/*
+resolveAtRootStatement returns the statements of @at-root if no frame is
+excluded, otherwise the statements are escaped to the outermost excluded
+frame.
+*/
+func resolveAtRootStatement(stm *ast.AtRootStatement, frames []*atRootFrame) ([]ast.Statement, []*atRootEscape) {
+	var stmts = []ast.Statement{}
+	if stm.Block != nil {
+		stmts = append(stmts, stm.Block.Statements...)
+	}
+
+	var outermost = -1
+	for idx, frame := range frames {
+		if stm.Query.Excludes(frame.Name) {
+			outermost = idx
+			break
+		}
+	}
+	if outermost < 0 {
+		return resolveAtRootStatements(stmts, frames)
+	}
+
+	if stm.Query.Excludes("rule") {
+		resolveAtRootSelectors(stmts, frameSelectors(frames))
+	}
+	for idx := len(frames) - 1; idx > outermost; idx-- {
+		if !stm.Query.Excludes(frames[idx].Name) {
+			stmts = []ast.Statement{wrapAtRootStatements(frames[idx].Statement, stmts)}
+		}
+	}
+
+	var out, escapes = resolveAtRootStatements(stmts, frames[:outermost])
+	return nil, append(escapes, &atRootEscape{outermost, out})
+}
+
+/*
+resolveAtRootSelectors resolves the parent selectors of the rulesets moved
+out of the enclosing rulesets, the selectors without "&" are not changed.
+*/
+func resolveAtRootSelectors(stmts []ast.Statement, parents ast.SelectorGroup) {
+	if parents == nil {
+		return
+	}
+	for _, anyStm := range stmts {
+		if ruleset, ok := anyStm.(*ast.RuleSet); ok {
+			var selectors = ast.SelectorGroup{}
+			for _, selectorList := range ruleset.Selectors {
+				if selectorList.HasParentSelector() {
+					selectors = append(selectors, ast.SelectorGroup{selectorList}.ResolveParent(parents)...)
+				} else {
+					selectors = append(selectors, selectorList)
+				}
+			}
+			ruleset.Selectors = selectors
+		} else if block := atRuleBlock(anyStm); block != nil {
+			resolveAtRootSelectors(block.Statements, parents)
+		}
+	}
+}
+
+/*
+wrapAtRootStatements wraps the statements by a copy of the frame which is
+not excluded by @at-root.
+*/
+func wrapAtRootStatements(anyStm ast.Statement, stmts []ast.Statement) ast.Statement {
+	switch stm := anyStm.(type) {
+	case *ast.RuleSet:
+		var ruleset = ast.NewRuleSet()
+		ruleset.Selectors = stm.Selectors
+		ruleset.Token = stm.Token
+		ruleset.Block = ast.NewDeclarationBlock()
+		appendDeclarations(ruleset.Block, stmts)
+		return ruleset
+	case *ast.MediaQueryStatement:
+		var media = ast.NewMediaQueryStatement()
+		media.MediaQueryList = stm.MediaQueryList
+		media.Token = stm.Token
+		media.Block = ast.NewBlock()
+		media.Block.AppendStatements(stmts)
+		return media
+	case *ast.SupportsStatement:
+		var supports = ast.NewSupportsStatementWithToken(stm.Token)
+		supports.Condition = stm.Condition
+		supports.Block = ast.NewBlock()
+		supports.Block.AppendStatements(stmts)
+		return supports
+	case *ast.AtRuleStatement:
+		var atRule = ast.NewAtRuleStatementWithToken(stm.Token)
+		atRule.Name = stm.Name
+		atRule.Prelude = stm.Prelude
+		atRule.Block = ast.NewBlock()
+		atRule.Block.AppendStatements(stmts)
+		return atRule
+	}
+	return anyStm
+}
+
+/*
+appendDeclarations appends the statements to the declaration block, the
+rulesets are appended as the sub rulesets.
+*/
+func appendDeclarations(block *ast.DeclarationBlock, stmts []ast.Statement) {
+	for _, anyStm := range stmts {
+		if ruleset, ok := anyStm.(*ast.RuleSet); ok {
+			block.AppendSubRuleSet(ruleset)
+		} else {
+			block.Append(anyStm)
+		}
+	}
+}
+
+/*
+isEmptyAtRootFrame reports whether the @media or @supports contains nothing
+but the empty rulesets and the empty @media or @supports.
+*/
+func isEmptyAtRootFrame(anyStm ast.Statement) bool {
+	switch stm := anyStm.(type) {
+	case *ast.RuleSet:
+		if stm.Block == nil {
+			return true
+		}
+		for _, subStm := range blockStatements(stm.Block) {
+			if !isEmptyAtRootFrame(subStm) {
+				return false
+			}
+		}
+		return true
+	case *ast.MediaQueryStatement, *ast.SupportsStatement:
+		if block := atRuleBlock(stm); block != nil {
+			for _, subStm := range block.Statements {
+				if !isEmptyAtRootFrame(subStm) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func frameSelectors(frames []*atRootFrame) ast.SelectorGroup {
+	if len(frames) == 0 {
+		return nil
+	}
+	return frames[len(frames)-1].Selectors
+}
@@ -0,0 +1,213 @@
+package runtime
+
+import "c6/ast"
+import "c6/symtable"
+import "fmt"
+import "strings"
+
+/*
+BuiltinFunction is the SASS built-in function. The arguments are bound to the
+parameters like the arguments of @function, so the keyword arguments, the
+rest arguments and the default values work the same:
+
+	map-get($map: $colors, $key: primary)
+*/
+type BuiltinFunction struct {
+	Name       string
+	Parameters []*ast.Parameter
+
+	// Call gets the bound arguments and returns the result, it panics with
+	// an error if the arguments are invalid.
+	Call func(args *BuiltinArguments) ast.Value
+}
+
+/*
+BuiltinFunctions is the registry of the built-in functions, the keys are the
+names with the hyphens.
+*/
+var BuiltinFunctions = map[string]*BuiltinFunction{}
+
+func builtinKey(name string) string {
+	return strings.Replace(name, "_", "-", -1)
+}
+
+/*
+RegisterBuiltinFunction adds the function to the registry, the function of
+the same name is replaced. The parameter names start with "$", the rest
+parameter ends with "...":
+
+	RegisterBuiltinFunction("map-remove", BuiltinParameters("$map", "$keys..."), mapRemove)
+*/
+func RegisterBuiltinFunction(name string, params []*ast.Parameter, call func(args *BuiltinArguments) ast.Value) {
+	BuiltinFunctions[builtinKey(name)] = &BuiltinFunction{Name: name, Parameters: params, Call: call}
+}
+
+/*
+LookupBuiltinFunction returns the built-in function of the name, the hyphens
+and underscores in the names are the same.
+*/
+func LookupBuiltinFunction(name string) (*BuiltinFunction, bool) {
+	fn, ok := BuiltinFunctions[builtinKey(name)]
+	return fn, ok
+}
+
+/*
+BuiltinParameters creates the parameters from the names.
+*/
+func BuiltinParameters(names ...string) []*ast.Parameter {
+	var params = []*ast.Parameter{}
+	for _, name := range names {
+		var param = &ast.Parameter{}
+		if strings.HasSuffix(name, "...") {
+			name = strings.TrimSuffix(name, "...")
+			param.Rest = true
+		}
+		param.Variable = &ast.Variable{Name: name}
+		params = append(params, param)
+	}
+	return params
+}
+
+/*
+withDefaults sets the default values of the parameters by the names, the
+parameters with the default values are optional.
+*/
+func withDefaults(params []*ast.Parameter, defaults map[string]ast.Expression) []*ast.Parameter {
+	for _, param := range params {
+		if val, ok := defaults[param.Variable.Name]; ok {
+			param.Default = val
+		}
+	}
+	return params
+}
+
+/*
+CallBuiltinFunction binds the arguments of the call and calls the built-in
+function, the errors are raised at the position of the call.
+*/
+func CallBuiltinFunction(fn *BuiltinFunction, fcall *ast.FunctionCall, callerSymTable *symtable.SymTable) (result ast.Value) {
+	var argsSymTable = symtable.NewSymTable()
+	if err := BindArguments("function '"+fn.Name+"'", fn.Parameters, fcall.Arguments, callerSymTable, argsSymTable); err != nil {
+		panic(NewRuntimeError(fcall.Token, err))
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			if err, ok := r.(error); ok {
+				if _, isRuntimeErr := err.(*RuntimeError); !isRuntimeErr {
+					r = NewRuntimeError(fcall.Token, err)
+				}
+			}
+			panic(r)
+		}
+	}()
+	return fn.Call(&BuiltinArguments{Function: fn, Token: fcall.Token, SymTable: argsSymTable})
+}
+
+/*
+BuiltinArguments is the arguments of the built-in function call, the getters
+panic with an error if the argument is not of the expected type.
+*/
+type BuiltinArguments struct {
+	Function *BuiltinFunction
+
+	// the token of the function call
+	Token *ast.Token
+
+	SymTable *symtable.SymTable
+}
+
+/*
+Value returns the argument of the parameter.
+*/
+func (args *BuiltinArguments) Value(name string) ast.Value {
+	var item, _ = args.SymTable.Get(name)
+	if item == nil {
+		return ast.NewNullWithToken(nil)
+	}
+	return item.(ast.Value)
+}
+
+/*
+IsNull returns true if the argument is null, e.g. the optional argument which
+is not passed.
+*/
+func (args *BuiltinArguments) IsNull(name string) bool {
+	_, ok := args.Value(name).(*ast.Null)
+	return ok
+}
+
+/*
+Number returns the argument as a number.
+*/
+func (args *BuiltinArguments) Number(name string) *ast.Number {
+	if num, ok := args.Value(name).(*ast.Number); ok {
+		return num
+	}
+	panic(args.Errorf(name, "%s is not a number", args.Value(name)))
+}
+
+/*
+CSSFunctionCall returns the plain CSS function call of the arguments, for the
+built-in functions which are also CSS functions, e.g. rgb(var(--red), 0, 0)
+and the filter grayscale(50%). The null arguments are skipped.
+*/
+func (args *BuiltinArguments) CSSFunctionCall() *ast.FunctionCall {
+	var fcall = &ast.FunctionCall{Function: args.Function.Name, Arguments: []*ast.Argument{}, Token: args.Token}
+	for _, param := range args.Function.Parameters {
+		if args.IsNull(param.Variable.Name) {
+			continue
+		}
+		if list, ok := args.Value(param.Variable.Name).(*ast.List); ok && param.Rest {
+			for _, item := range list.Expressions {
+				fcall.AppendArgument(item)
+			}
+		} else {
+			fcall.AppendArgument(args.Value(param.Variable.Name))
+		}
+	}
+	return fcall
+}
+
+/*
+List returns the argument as a list, the value which is not a list is a list
+of one item.
+*/
+func (args *BuiltinArguments) List(name string) []ast.Expression {
+	switch val := args.Value(name).(type) {
+	case *ast.List:
+		return val.Expressions
+	case *ast.Map:
+		if val.Len() == 0 {
+			return []ast.Expression{}
+		}
+	}
+	return []ast.Expression{args.Value(name)}
+}
+
+/*
+Map returns the argument as a map, the empty list "()" is the empty map.
+*/
+func (args *BuiltinArguments) Map(name string) *ast.Map {
+	return args.toMap(name, args.Value(name))
+}
+
+func (args *BuiltinArguments) toMap(name string, anyVal ast.Value) *ast.Map {
+	switch val := anyVal.(type) {
+	case *ast.Map:
+		return val
+	case *ast.List:
+		if val.Len() == 0 {
+			return ast.NewMap()
+		}
+	}
+	panic(args.Errorf(name, "%s is not a map", anyVal))
+}
+
+/*
+Errorf creates the error of the argument, e.g. "$map: 10px is not a map for
+function 'map-get'".
+*/
+func (args *BuiltinArguments) Errorf(name string, format string, a ...interface{}) error {
+	return fmt.Errorf("%s: %s for function '%s'", name, fmt.Sprintf(format, a...), args.Function.Name)
+}
@@ -0,0 +1,442 @@
+package runtime
+
+import "c6/ast"
+import "fmt"
+import "math"
+import "strings"
+
+func init() {
+	var null = ast.NewNullWithToken(nil)
+	var rgbParams = func() []*ast.Parameter {
+		return withDefaults(BuiltinParameters("$red", "$green", "$blue", "$alpha"),
+			map[string]ast.Expression{"$green": null, "$blue": null, "$alpha": null})
+	}
+	var hslParams = func() []*ast.Parameter {
+		return withDefaults(BuiltinParameters("$hue", "$saturation", "$lightness", "$alpha"),
+			map[string]ast.Expression{"$alpha": null})
+	}
+	RegisterBuiltinFunction("rgb", rgbParams(), rgbFunction)
+	RegisterBuiltinFunction("rgba", rgbParams(), rgbFunction)
+	RegisterBuiltinFunction("hsl", hslParams(), hslFunction)
+	RegisterBuiltinFunction("hsla", hslParams(), hslFunction)
+
+	RegisterBuiltinFunction("red", BuiltinParameters("$color"), redFunction)
+	RegisterBuiltinFunction("green", BuiltinParameters("$color"), greenFunction)
+	RegisterBuiltinFunction("blue", BuiltinParameters("$color"), blueFunction)
+	RegisterBuiltinFunction("hue", BuiltinParameters("$color"), hueFunction)
+	RegisterBuiltinFunction("saturation", BuiltinParameters("$color"), saturationFunction)
+	RegisterBuiltinFunction("lightness", BuiltinParameters("$color"), lightnessFunction)
+	RegisterBuiltinFunction("alpha", BuiltinParameters("$color"), alphaFunction)
+	RegisterBuiltinFunction("opacity", BuiltinParameters("$color"), alphaFunction)
+
+	RegisterBuiltinFunction("mix", withDefaults(BuiltinParameters("$color1", "$color2", "$weight"),
+		map[string]ast.Expression{"$weight": ast.NewNumber(50, ast.NewUnit(ast.T_UNIT_PERCENT, nil), nil)}), mixFunction)
+	RegisterBuiltinFunction("lighten", BuiltinParameters("$color", "$amount"), lightenFunction)
+	RegisterBuiltinFunction("darken", BuiltinParameters("$color", "$amount"), darkenFunction)
+	RegisterBuiltinFunction("saturate", withDefaults(BuiltinParameters("$color", "$amount"),
+		map[string]ast.Expression{"$amount": null}), saturateFunction)
+	RegisterBuiltinFunction("desaturate", BuiltinParameters("$color", "$amount"), desaturateFunction)
+	RegisterBuiltinFunction("adjust-hue", BuiltinParameters("$color", "$degrees"), adjustHueFunction)
+	RegisterBuiltinFunction("grayscale", BuiltinParameters("$color"), grayscaleFunction)
+	RegisterBuiltinFunction("complement", BuiltinParameters("$color"), complementFunction)
+	RegisterBuiltinFunction("invert", withDefaults(BuiltinParameters("$color", "$weight"),
+		map[string]ast.Expression{"$weight": null}), invertFunction)
+
+	RegisterBuiltinFunction("opacify", BuiltinParameters("$color", "$amount"), opacifyFunction)
+	RegisterBuiltinFunction("fade-in", BuiltinParameters("$color", "$amount"), opacifyFunction)
+	RegisterBuiltinFunction("transparentize", BuiltinParameters("$color", "$amount"), transparentizeFunction)
+	RegisterBuiltinFunction("fade-out", BuiltinParameters("$color", "$amount"), transparentizeFunction)
+}
+
+/*
+colorRGBA is the color computed by the built-in functions, the channels are
+0~255 and the alpha is 0~1.
+*/
+type colorRGBA struct {
+	R, G, B, A float64
+}
+
+/*
+toColorRGBA converts the color value, including the color keywords, e.g.
+red and transparent.
+*/
+func toColorRGBA(anyVal ast.Value) (*colorRGBA, bool) {
+	switch val := anyVal.(type) {
+	case *ast.HexColor:
+		return &colorRGBA{float64(val.R), float64(val.G), float64(val.B), 1}, true
+	case *ast.RGBColor:
+		return &colorRGBA{float64(val.R), float64(val.G), float64(val.B), 1}, true
+	case *ast.RGBAColor:
+		return &colorRGBA{float64(val.R), float64(val.G), float64(val.B), float64(val.A)}, true
+	case *ast.HSLColor:
+		var r, g, b = ast.HSLToRGB(val.H, val.S, val.L)
+		return &colorRGBA{float64(r), float64(g), float64(b), 1}, true
+	case *ast.HSLAColor:
+		var r, g, b = ast.HSLToRGB(val.H, val.S, val.L)
+		return &colorRGBA{float64(r), float64(g), float64(b), val.A}, true
+	case *ast.String:
+		if val.Quote != 0 {
+			return nil, false
+		}
+		var name = strings.ToLower(val.Value)
+		if name == "transparent" {
+			return &colorRGBA{0, 0, 0, 0}, true
+		}
+		if hex, ok := ast.ColorKeywords[name]; ok {
+			var r, g, b, _ = ast.HexToRGBA(hex)
+			return &colorRGBA{float64(r), float64(g), float64(b), 1}, true
+		}
+	}
+	return nil, false
+}
+
+/*
+colorFromHSL creates the color from the hue in degrees, the saturation and
+the lightness in percentages.
+*/
+func colorFromHSL(h, s, l, a float64) *colorRGBA {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	h, s, l = h/360, clamp(s, 0, 100)/100, clamp(l, 0, 100)/100
+	if s == 0 {
+		return &colorRGBA{l * 255, l * 255, l * 255, a}
+	}
+	var q = l + s - s*l
+	if l < 0.5 {
+		q = l * (1 + s)
+	}
+	var p = 2*l - q
+	return &colorRGBA{
+		ast.ConvertHUE(p, q, h+1.0/3) * 255,
+		ast.ConvertHUE(p, q, h) * 255,
+		ast.ConvertHUE(p, q, h-1.0/3) * 255,
+		a,
+	}
+}
+
+/*
+HSL returns the hue in degrees, the saturation and the lightness in
+percentages.
+*/
+func (c *colorRGBA) HSL() (h, s, l float64) {
+	h, s, l = ast.RGBToHSL(roundChannel(c.R), roundChannel(c.G), roundChannel(c.B))
+	return h * 360, s * 100, l * 100
+}
+
+/*
+Value returns the hex color for the opaque color, otherwise the rgba() color.
+*/
+func (c *colorRGBA) Value() ast.Value {
+	var r, g, b = roundChannel(c.R), roundChannel(c.G), roundChannel(c.B)
+	if c.A >= 1 {
+		return ast.NewHexColor(fmt.Sprintf("#%02x%02x%02x", r, g, b), nil)
+	}
+	return ast.NewRGBAColor(r, g, b, float32(clamp(c.A, 0, 1)), nil)
+}
+
+/*
+roundChannel rounds the channel half up, the tiny epsilon avoids the
+floating point errors of the HSL conversion, e.g. 25.499999999999996.
+*/
+func roundChannel(val float64) uint32 {
+	return uint32(math.Floor(clamp(val, 0, 255) + 0.5 + 1e-9))
+}
+
+func clamp(val, min, max float64) float64 {
+	return math.Max(min, math.Min(max, val))
+}
+
+func unitType(num *ast.Number) ast.TokenType {
+	if num.Unit == nil {
+		return ast.T_UNIT_NONE
+	}
+	return num.Unit.Type
+}
+
+/*
+colorArgument returns the argument as a color.
+*/
+func colorArgument(args *BuiltinArguments, name string) *colorRGBA {
+	if color, ok := toColorRGBA(args.Value(name)); ok {
+		return color
+	}
+	panic(args.Errorf(name, "%s is not a color", args.Value(name)))
+}
+
+/*
+percentageArgument returns the unitless number or the percentage within
+0% and 100%, e.g. the amount of lighten().
+*/
+func percentageArgument(args *BuiltinArguments, name string) float64 {
+	var num = args.Number(name)
+	if unit := unitType(num); unit != ast.T_UNIT_NONE && unit != ast.T_UNIT_PERCENT {
+		panic(args.Errorf(name, "%s should be unitless or a percentage", num))
+	}
+	if num.Value < 0 || num.Value > 100 {
+		panic(args.Errorf(name, "%s is not within 0%% and 100%%", num))
+	}
+	return num.Value
+}
+
+/*
+alphaArgument returns the unitless number within 0 and 1, e.g. the amount of
+opacify().
+*/
+func alphaArgument(args *BuiltinArguments, name string) float64 {
+	var num = args.Number(name)
+	if unitType(num) != ast.T_UNIT_NONE {
+		panic(args.Errorf(name, "%s should be unitless", num))
+	}
+	if num.Value < 0 || num.Value > 1 {
+		panic(args.Errorf(name, "%s is not within 0 and 1", num))
+	}
+	return num.Value
+}
+
+/*
+channelArgument returns the channel of rgb() within 0 and 255, the
+percentage is the percentage of 255.
+*/
+func channelArgument(args *BuiltinArguments, name string) float64 {
+	var num = args.Number(name)
+	switch unitType(num) {
+	case ast.T_UNIT_NONE:
+		return clamp(num.Value, 0, 255)
+	case ast.T_UNIT_PERCENT:
+		return clamp(num.Value, 0, 100) * 255 / 100
+	}
+	panic(args.Errorf(name, "%s should be unitless or a percentage", num))
+}
+
+/*
+degreesArgument returns the angle in degrees, the unitless number is in
+degrees.
+*/
+func degreesArgument(args *BuiltinArguments, name string) float64 {
+	var num = args.Number(name)
+	switch unitType(num) {
+	case ast.T_UNIT_NONE, ast.T_UNIT_DEG:
+		return num.Value
+	case ast.T_UNIT_RAD:
+		return num.Value * 180 / math.Pi
+	case ast.T_UNIT_GRAD:
+		return num.Value * 0.9
+	case ast.T_UNIT_TURN:
+		return num.Value * 360
+	}
+	panic(args.Errorf(name, "%s should be unitless or an angle", num))
+}
+
+/*
+hasCSSFunctionArgument returns true if an argument is a CSS function call,
+e.g. var() and calc(), the call is passed through as a CSS function.
+*/
+func hasCSSFunctionArgument(args *BuiltinArguments) bool {
+	for _, param := range args.Function.Parameters {
+		if _, ok := args.Value(param.Variable.Name).(*ast.FunctionCall); ok {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+rgbFunction creates the color by rgb($red, $green, $blue, $alpha) or changes
+the alpha of the color by rgba($color, $alpha).
+*/
+func rgbFunction(args *BuiltinArguments) ast.Value {
+	if hasCSSFunctionArgument(args) {
+		return args.CSSFunctionCall()
+	}
+	if _, ok := toColorRGBA(args.Value("$red")); ok && args.IsNull("$blue") && args.IsNull("$alpha") {
+		return rgbaColorFunction(args)
+	}
+	for _, name := range []string{"$green", "$blue"} {
+		if args.IsNull(name) {
+			panic(fmt.Errorf("Missing argument %s of function '%s'", name, args.Function.Name))
+		}
+	}
+
+	var color = &colorRGBA{channelArgument(args, "$red"), channelArgument(args, "$green"), channelArgument(args, "$blue"), 1}
+	if !args.IsNull("$alpha") {
+		color.A = alphaArgument(args, "$alpha")
+	}
+	return color.Value()
+}
+
+/*
+rgbaColorFunction changes the alpha of the color by rgba($color, $alpha), the
+two arguments are bound to $red and $green of rgb() and renamed here so the
+errors name the arguments of this overload.
+*/
+func rgbaColorFunction(args *BuiltinArguments) ast.Value {
+	if args.IsNull("$green") {
+		panic(fmt.Errorf("Missing argument $alpha of function '%s'", args.Function.Name))
+	}
+	args.SymTable.Set("$color", args.Value("$red"))
+	args.SymTable.Set("$alpha", args.Value("$green"))
+	var color = colorArgument(args, "$color")
+	color.A = alphaArgument(args, "$alpha")
+	return color.Value()
+}
+
+/*
+hslFunction creates the color by hsl($hue, $saturation, $lightness, $alpha).
+*/
+func hslFunction(args *BuiltinArguments) ast.Value {
+	if hasCSSFunctionArgument(args) {
+		return args.CSSFunctionCall()
+	}
+	var alpha = 1.0
+	if !args.IsNull("$alpha") {
+		alpha = alphaArgument(args, "$alpha")
+	}
+	return colorFromHSL(degreesArgument(args, "$hue"), percentageArgument(args, "$saturation"), percentageArgument(args, "$lightness"), alpha).Value()
+}
+
+func redFunction(args *BuiltinArguments) ast.Value {
+	return ast.NewNumber(float64(roundChannel(colorArgument(args, "$color").R)), nil, nil)
+}
+
+func greenFunction(args *BuiltinArguments) ast.Value {
+	return ast.NewNumber(float64(roundChannel(colorArgument(args, "$color").G)), nil, nil)
+}
+
+func blueFunction(args *BuiltinArguments) ast.Value {
+	return ast.NewNumber(float64(roundChannel(colorArgument(args, "$color").B)), nil, nil)
+}
+
+func hueFunction(args *BuiltinArguments) ast.Value {
+	var h, _, _ = colorArgument(args, "$color").HSL()
+	return ast.NewNumber(h, ast.NewUnit(ast.T_UNIT_DEG, nil), nil)
+}
+
+func saturationFunction(args *BuiltinArguments) ast.Value {
+	var _, s, _ = colorArgument(args, "$color").HSL()
+	return ast.NewNumber(s, ast.NewUnit(ast.T_UNIT_PERCENT, nil), nil)
+}
+
+func lightnessFunction(args *BuiltinArguments) ast.Value {
+	var _, _, l = colorArgument(args, "$color").HSL()
+	return ast.NewNumber(l, ast.NewUnit(ast.T_UNIT_PERCENT, nil), nil)
+}
+
+/*
+alphaFunction returns the alpha of the color, opacity($amount) with a number
+is the CSS filter function.
+*/
+func alphaFunction(args *BuiltinArguments) ast.Value {
+	if _, ok := args.Value("$color").(*ast.Number); ok && args.Function.Name == "opacity" {
+		return args.CSSFunctionCall()
+	}
+	return ast.NewNumber(colorArgument(args, "$color").A, nil, nil)
+}
+
+/*
+mixFunction mixes the colors by the weight of $color1, the alpha channels are
+taken into account like Sass.
+*/
+func mixFunction(args *BuiltinArguments) ast.Value {
+	var color1 = colorArgument(args, "$color1")
+	var color2 = colorArgument(args, "$color2")
+	var p = percentageArgument(args, "$weight") / 100
+	return mixColors(color1, color2, p).Value()
+}
+
+func mixColors(color1, color2 *colorRGBA, p float64) *colorRGBA {
+	var w = 2*p - 1
+	var a = color1.A - color2.A
+	var w1 = w
+	if w*a != -1 {
+		w1 = (w + a) / (1 + w*a)
+	}
+	w1 = (w1 + 1) / 2
+	var w2 = 1 - w1
+	return &colorRGBA{
+		color1.R*w1 + color2.R*w2,
+		color1.G*w1 + color2.G*w2,
+		color1.B*w1 + color2.B*w2,
+		color1.A*p + color2.A*(1-p),
+	}
+}
+
+/*
+adjustHSL changes the hue, the saturation and the lightness of the color.
+*/
+func adjustHSL(color *colorRGBA, dh, ds, dl float64) ast.Value {
+	var h, s, l = color.HSL()
+	return colorFromHSL(h+dh, s+ds, l+dl, color.A).Value()
+}
+
+func lightenFunction(args *BuiltinArguments) ast.Value {
+	return adjustHSL(colorArgument(args, "$color"), 0, 0, percentageArgument(args, "$amount"))
+}
+
+func darkenFunction(args *BuiltinArguments) ast.Value {
+	return adjustHSL(colorArgument(args, "$color"), 0, 0, -percentageArgument(args, "$amount"))
+}
+
+/*
+saturateFunction saturates the color, saturate($amount) with a number is the
+CSS filter function.
+*/
+func saturateFunction(args *BuiltinArguments) ast.Value {
+	if args.IsNull("$amount") {
+		args.Number("$color")
+		return args.CSSFunctionCall()
+	}
+	return adjustHSL(colorArgument(args, "$color"), 0, percentageArgument(args, "$amount"), 0)
+}
+
+func desaturateFunction(args *BuiltinArguments) ast.Value {
+	return adjustHSL(colorArgument(args, "$color"), 0, -percentageArgument(args, "$amount"), 0)
+}
+
+func adjustHueFunction(args *BuiltinArguments) ast.Value {
+	return adjustHSL(colorArgument(args, "$color"), degreesArgument(args, "$degrees"), 0, 0)
+}
+
+/*
+grayscaleFunction removes the saturation of the color, grayscale($amount)
+with a number is the CSS filter function.
+*/
+func grayscaleFunction(args *BuiltinArguments) ast.Value {
+	if _, ok := args.Value("$color").(*ast.Number); ok {
+		return args.CSSFunctionCall()
+	}
+	return adjustHSL(colorArgument(args, "$color"), 0, -100, 0)
+}
+
+func complementFunction(args *BuiltinArguments) ast.Value {
+	return adjustHSL(colorArgument(args, "$color"), 180, 0, 0)
+}
+
+/*
+invertFunction inverts the channels of the color, the weight is the weight of
+the inverse color. invert($amount) with a number is the CSS filter function.
+*/
+func invertFunction(args *BuiltinArguments) ast.Value {
+	if _, ok := args.Value("$color").(*ast.Number); ok {
+		return args.CSSFunctionCall()
+	}
+	var color = colorArgument(args, "$color")
+	var inverse = &colorRGBA{255 - color.R, 255 - color.G, 255 - color.B, color.A}
+	if args.IsNull("$weight") {
+		return inverse.Value()
+	}
+	return mixColors(inverse, color, percentageArgument(args, "$weight")/100).Value()
+}
+
+func opacifyFunction(args *BuiltinArguments) ast.Value {
+	var color = colorArgument(args, "$color")
+	color.A = clamp(color.A+alphaArgument(args, "$amount"), 0, 1)
+	return color.Value()
+}
+
+func transparentizeFunction(args *BuiltinArguments) ast.Value {
+	var color = colorArgument(args, "$color")
+	color.A = clamp(color.A-alphaArgument(args, "$amount"), 0, 1)
+	return color.Value()
+}
@@ -0,0 +1,150 @@
+package runtime
+
+import "c6/ast"
+
+func init() {
+	RegisterBuiltinFunction("map-get", BuiltinParameters("$map", "$key", "$keys..."), mapGet)
+	RegisterBuiltinFunction("map-merge", BuiltinParameters("$map1", "$map2", "$args..."), mapMerge)
+	RegisterBuiltinFunction("map-deep-merge", BuiltinParameters("$map1", "$map2"), mapDeepMerge)
+	RegisterBuiltinFunction("map-remove", BuiltinParameters("$map", "$keys..."), mapRemove)
+	RegisterBuiltinFunction("map-keys", BuiltinParameters("$map"), mapKeys)
+	RegisterBuiltinFunction("map-values", BuiltinParameters("$map"), mapValues)
+	RegisterBuiltinFunction("map-has-key", BuiltinParameters("$map", "$key", "$keys..."), mapHasKey)
+}
+
+/*
+mapGet returns the value of the key, the following keys look up the nested
+maps, null is returned if a key is not found:
+
+	map-get($theme, colors, primary)
+*/
+func mapGet(args *BuiltinArguments) ast.Value {
+	var keys = append([]ast.Expression{args.Value("$key")}, args.List("$keys")...)
+	var val ast.Expression = args.Map("$map")
+	for _, key := range keys {
+		var m, ok = val.(*ast.Map)
+		if !ok {
+			return ast.NewNullWithToken(nil)
+		}
+		if val, ok = m.Get(key); !ok {
+			return ast.NewNullWithToken(nil)
+		}
+	}
+	return val
+}
+
+/*
+mapHasKey returns true if the key is in the map, the following keys look up
+the nested maps like map-get.
+*/
+func mapHasKey(args *BuiltinArguments) ast.Value {
+	var keys = append([]ast.Expression{args.Value("$key")}, args.List("$keys")...)
+	var val ast.Expression = args.Map("$map")
+	for _, key := range keys {
+		var m, ok = val.(*ast.Map)
+		if !ok {
+			return ast.NewBoolean(false)
+		}
+		if val, ok = m.Get(key); !ok {
+			return ast.NewBoolean(false)
+		}
+	}
+	return ast.NewBoolean(true)
+}
+
+/*
+mapMerge returns a new map with the pairs of both maps, the values of $map2
+take precedence. When there are the keys between the maps, $map2 is merged
+into the nested map of the keys, the nested maps which are not found are
+created:
+
+	map-merge($theme, colors, (primary: blue))
+*/
+func mapMerge(args *BuiltinArguments) ast.Value {
+	var map1 = args.Map("$map1")
+	var rest = args.List("$args")
+	if len(rest) == 0 {
+		return mergeMaps(map1, args.Map("$map2"))
+	}
+
+	var keys = append([]ast.Expression{args.Value("$map2")}, rest[:len(rest)-1]...)
+	var map2 = args.toMap("$map2", rest[len(rest)-1])
+	return mergeNestedMap(map1, keys, map2)
+}
+
+func mergeNestedMap(m *ast.Map, keys []ast.Expression, map2 *ast.Map) *ast.Map {
+	if len(keys) == 0 {
+		return mergeMaps(m, map2)
+	}
+	var nested = ast.NewMap()
+	if val, ok := m.Get(keys[0]); ok {
+		if valMap, ok := val.(*ast.Map); ok {
+			nested = valMap
+		}
+	}
+	var out = m.Copy()
+	out.Set(keys[0], mergeNestedMap(nested, keys[1:], map2))
+	return out
+}
+
+func mergeMaps(map1 *ast.Map, map2 *ast.Map) *ast.Map {
+	var out = map1.Copy()
+	for idx, key := range map2.Keys {
+		out.Set(key, map2.Values[idx])
+	}
+	return out
+}
+
+/*
+mapDeepMerge merges the maps like map-merge, but the nested maps of the same
+key are merged recursively instead of being replaced.
+*/
+func mapDeepMerge(args *BuiltinArguments) ast.Value {
+	return deepMergeMaps(args.Map("$map1"), args.Map("$map2"))
+}
+
+func deepMergeMaps(map1 *ast.Map, map2 *ast.Map) *ast.Map {
+	var out = map1.Copy()
+	for idx, key := range map2.Keys {
+		var val = map2.Values[idx]
+		if valMap, ok := val.(*ast.Map); ok {
+			if current, ok := out.Get(key); ok {
+				if currentMap, ok := current.(*ast.Map); ok {
+					val = deepMergeMaps(currentMap, valMap)
+				}
+			}
+		}
+		out.Set(key, val)
+	}
+	return out
+}
+
+/*
+mapRemove returns a new map without the keys, the keys not found are
+ignored.
+*/
+func mapRemove(args *BuiltinArguments) ast.Value {
+	var out = args.Map("$map").Copy()
+	for _, key := range args.List("$keys") {
+		out.Remove(key)
+	}
+	return out
+}
+
+/*
+mapKeys returns the keys of the map as a comma-separated list.
+*/
+func mapKeys(args *BuiltinArguments) ast.Value {
+	var list = ast.NewCommaSepList()
+	list.Expressions = append(list.Expressions, args.Map("$map").Keys...)
+	return list
+}
+
+/*
+mapValues returns the values of the map as a comma-separated list.
+*/
+func mapValues(args *BuiltinArguments) ast.Value {
+	var list = ast.NewCommaSepList()
+	list.Expressions = append(list.Expressions, args.Map("$map").Values...)
+	return list
+}
@@ -81,6 +81,15 @@ func Compute(op *ast.Op, a ast.Value, b ast.Value) ast.Value {
 					panic("Can't compare number (unit different)")
 				}
 			}
+		case *ast.String:
+			switch tb := b.(type) {
+			case *ast.String:
+				// the quotes are not compared
+				return ast.NewBoolean(ta.Value == tb.Value)
+			}
+		}
+		if IsEvaluatedValue(a) && IsEvaluatedValue(b) {
+			return ast.NewBoolean(a.String() == b.String())
 		}
 
 	case ast.T_UNEQUAL:
@@ -100,6 +109,15 @@ func Compute(op *ast.Op, a ast.Value, b ast.Value) ast.Value {
 					panic("Can't compare number (unit different)")
 				}
 			}
+		case *ast.String:
+			switch tb := b.(type) {
+			case *ast.String:
+				// the quotes are not compared
+				return ast.NewBoolean(ta.Value != tb.Value)
+			}
+		}
+		if IsEvaluatedValue(a) && IsEvaluatedValue(b) {
+			return ast.NewBoolean(a.String() != b.String())
 		}
 
 	case ast.T_GT:
@@ -197,12 +215,19 @@ func Compute(op *ast.Op, a ast.Value, b ast.Value) ast.Value {
 	*/
 	case ast.T_PLUS:
 		switch ta := a.(type) {
+		case *ast.String:
+			switch tb := b.(type) {
+			case *ast.String, *ast.Number:
+				return StringAddValue(ta, tb)
+			}
 		case *ast.Number:
 			switch tb := b.(type) {
 			case *ast.Number:
-				return NumberAddNumber(ta, tb)
+				return numberValue(NumberAddNumber(ta, tb))
 			case *ast.HexColor:
 				return HexColorAddNumber(tb, ta)
+			case *ast.String:
+				return StringAddValue(ast.NewString(tb.Quote, ta.String(), nil), tb)
 			}
 		case *ast.HexColor:
 			switch tb := b.(type) {
@@ -225,7 +250,7 @@ func Compute(op *ast.Op, a ast.Value, b ast.Value) ast.Value {
 		case *ast.Number:
 			switch tb := b.(type) {
 			case *ast.Number:
-				return NumberSubNumber(ta, tb)
+				return numberValue(NumberSubNumber(ta, tb))
 			}
 		case *ast.HexColor:
 			switch tb := b.(type) {
@@ -251,7 +276,7 @@ func Compute(op *ast.Op, a ast.Value, b ast.Value) ast.Value {
 		case *ast.Number:
 			switch tb := b.(type) {
 			case *ast.Number:
-				return NumberDivNumber(ta, tb)
+				return numberValue(NumberDivNumber(ta, tb))
 			}
 		case *ast.HexColor:
 			switch tb := b.(type) {
@@ -275,7 +300,7 @@ func Compute(op *ast.Op, a ast.Value, b ast.Value) ast.Value {
 		case *ast.Number:
 			switch tb := b.(type) {
 			case *ast.Number:
-				return NumberMulNumber(ta, tb)
+				return numberValue(NumberMulNumber(ta, tb))
 			}
 
 		case *ast.HexColor:
@@ -300,6 +325,17 @@ func Compute(op *ast.Op, a ast.Value, b ast.Value) ast.Value {
 	return nil
 }
 
+/*
+numberValue returns nil for the nil number, since the nil pointer in the
+interface is not nil.
+*/
+func numberValue(num *ast.Number) ast.Value {
+	if num == nil {
+		return nil
+	}
+	return num
+}
+
 func IsConstantExpression(expr ast.Expression) bool {
 	switch e := expr.(type) {
 	case *ast.BinaryExpression:
@@ -314,6 +350,18 @@ func IsConstantExpression(expr ast.Expression) bool {
 	return false
 }
 
+/*
+IsEvaluatedValue returns false for the expressions which are not evaluated,
+e.g. the variables when the symbol table is not given.
+*/
+func IsEvaluatedValue(val ast.Value) bool {
+	switch val.(type) {
+	case nil, *ast.Variable, *ast.BinaryExpression, *ast.UnaryExpression, *ast.FunctionCall:
+		return false
+	}
+	return true
+}
+
 func IsConstantValue(val ast.Value) bool {
 	switch val.(type) {
 	case *ast.Number, *ast.HexColor, *ast.RGBColor, *ast.RGBAColor, *ast.HSLColor, *ast.HSVColor, *ast.Boolean:
@@ -408,13 +456,26 @@ func EvaluateExpression(expr ast.Expression, symTable *symtable.SymTable) ast.Va
 		// For binary expression that is a CSS slash, we evaluate the expression as a literal string (unquoted)
 		if t.IsCssSlash() {
 			// return string object without quote
-			return ast.NewString(0, t.Left.(*ast.Number).String()+"/"+t.Right.(*ast.Number).String(), nil)
+			return ast.NewString(0, t.String(), nil)
 		}
 		return EvaluateBinaryExpression(t, symTable)
 
 	case *ast.UnaryExpression:
 		return EvaluateUnaryExpression(t, symTable)
 
+	case *ast.Variable:
+		return EvaluateVariable(t, symTable)
+
+	case *ast.FunctionCall:
+		return EvaluateFunctionCall(t, symTable)
+
+	case *ast.StringTemplate:
+		// the interpolations are evaluated at runtime
+		if symTable == nil {
+			return nil
+		}
+		return EvaluateValue(t, symTable)
+
 	default:
 		return ast.Value(expr)
 
@@ -445,7 +506,7 @@ func EvaluateBinaryExpression(expr *ast.BinaryExpression, symTable *symtable.Sym
 		lval = EvaluateUnaryExpression(expr, symTable)
 
 	default:
-		lval = ast.Value(expr)
+		lval = EvaluateExpression(expr, symTable)
 	}
 
 	switch expr := expr.Right.(type) {
@@ -457,7 +518,7 @@ func EvaluateBinaryExpression(expr *ast.BinaryExpression, symTable *symtable.Sym
 		rval = EvaluateBinaryExpression(expr, symTable)
 
 	default:
-		rval = ast.Value(expr)
+		rval = EvaluateExpression(expr, symTable)
 	}
 
 	if lval != nil && rval != nil {
@@ -475,7 +536,7 @@ func EvaluateUnaryExpression(expr *ast.UnaryExpression, symTable *symtable.SymTa
 	case *ast.UnaryExpression:
 		val = EvaluateUnaryExpression(t, symTable)
 	default:
-		val = ast.Value(t)
+		val = EvaluateExpression(t, symTable)
 	}
 
 	switch expr.Op.Type {
@@ -488,8 +549,107 @@ func EvaluateUnaryExpression(expr *ast.UnaryExpression, symTable *symtable.SymTa
 	case ast.T_MINUS:
 		switch n := val.(type) {
 		case *ast.Number:
-			n.Value = -n.Value
+			// the number may be the value of a variable, don't change it.
+			val = ast.NewNumber(-n.Value, n.Unit, n.Token)
 		}
 	}
 	return val
 }
+
+/*
+EvaluateVariable returns the value of the variable defined in the scope
+chain of the symbol table. The variable itself is returned if the symbol
+table is nil, e.g. the constant folding in the parser, otherwise an undefined
+variable raises the runtime error.
+*/
+func EvaluateVariable(variable *ast.Variable, symTable *symtable.SymTable) ast.Value {
+	if symTable == nil {
+		return variable
+	}
+	if item, ok := symTable.Get(variable.Name); ok {
+		if val, ok := item.(ast.Value); ok {
+			return val
+		}
+	}
+	panic(NewRuntimeError(variable.Token, fmt.Errorf("Undefined variable %s", variable.Name)))
+}
+
+/*
+AssignVariable evaluates the assignment in the scope of the symbol table:
+
+	$a: 1 !global;     // assigns $a in the root scope
+	$b: 2 !default;    // assigns $b only if it's undefined or null
+
+The other assignments are assigned by SymTable.Assign.
+*/
+func AssignVariable(stm *ast.VariableAssignment, symTable *symtable.SymTable) {
+	var target = symTable
+	if stm.Global {
+		for target.Parent != nil {
+			target = target.Parent
+		}
+	}
+	if stm.Default {
+		if item, ok := target.Get(stm.Variable.Name); ok {
+			if _, isNull := item.(*ast.Null); !isNull {
+				return
+			}
+		}
+	}
+
+	var val = EvaluateValue(stm.Expression, symTable)
+	if stm.Global {
+		target.Set(stm.Variable.Name, val)
+	} else {
+		target.Assign(stm.Variable.Name, val)
+	}
+}
+
+/*
+EvaluateValue replaces the variables in the expression by their values and
+reduces the expression, the lists are evaluated item by item and the
+functions are called.
+*/
+func EvaluateValue(anyExpr ast.Expression, symTable *symtable.SymTable) ast.Expression {
+	switch expr := anyExpr.(type) {
+
+	case *ast.List:
+		var list = ast.NewList(expr.Separator)
+		for _, subexpr := range expr.Expressions {
+			list.Append(EvaluateValue(subexpr, symTable))
+		}
+		return list
+
+	case *ast.Map:
+		var mapValue = ast.NewMap()
+		for i, key := range expr.Keys {
+			mapValue.Set(EvaluateValue(key, symTable), EvaluateValue(expr.Values[i], symTable))
+		}
+		return mapValue
+
+	case *ast.FunctionCall:
+		return EvaluateFunctionCall(expr, symTable)
+
+	case *ast.Interpolation:
+		return ast.NewInterpolation(EvaluateValue(expr.Expression, symTable), expr.StartToken, expr.EndToken)
+
+	case *ast.LiteralConcat:
+		return ast.NewLiteralConcat(EvaluateValue(expr.Left, symTable), EvaluateValue(expr.Right, symTable))
+
+	case *ast.StringTemplate:
+		// the interpolated strings are unquoted inside the quoted string
+		return ast.NewString(expr.Quote, EvaluateValue(expr.Template, symTable).String(), expr.Token)
+
+	case *ast.BinaryExpression:
+		if val := EvaluateExpression(expr, symTable); val != nil {
+			return val
+		}
+		// the operation is not supported, keep the operands
+		return ast.NewBinaryExpression(expr.Op, EvaluateValue(expr.Left, symTable), EvaluateValue(expr.Right, symTable), expr.Grouped)
+	}
+
+	if val := EvaluateExpression(anyExpr, symTable); val != nil {
+		return val
+	}
+	return anyExpr
+}
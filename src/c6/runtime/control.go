@@ -0,0 +1,148 @@
+package runtime
+
+import "c6/ast"
+import "c6/symtable"
+import "fmt"
+
+/*
+EvaluateCondition evaluates the condition of @if and @while. Like SASS, only
+false and null are false, the other values are true.
+*/
+func EvaluateCondition(anyExpr ast.Expression, symTable *symtable.SymTable) bool {
+	switch expr := anyExpr.(type) {
+	case *ast.BinaryExpression:
+		switch expr.Op.Type {
+		case ast.T_LOGICAL_AND:
+			return EvaluateCondition(expr.Left, symTable) && EvaluateCondition(expr.Right, symTable)
+		case ast.T_LOGICAL_OR:
+			return EvaluateCondition(expr.Left, symTable) || EvaluateCondition(expr.Right, symTable)
+		}
+	case *ast.UnaryExpression:
+		if expr.Op.Type == ast.T_LOGICAL_NOT {
+			return !EvaluateCondition(expr.Expr, symTable)
+		}
+	}
+
+	switch val := EvaluateValue(anyExpr, symTable).(type) {
+	case *ast.Boolean:
+		return val.Value
+	case *ast.Null, nil:
+		return false
+	}
+	return true
+}
+
+/*
+SelectIfBlock returns the block of the first true condition of the @if and
+@else if chain, or the @else block. It's nil if no block is selected.
+*/
+func SelectIfBlock(stm *ast.IfStatement, symTable *symtable.SymTable) *ast.Block {
+	if EvaluateCondition(stm.Condition, symTable) {
+		return stm.Block
+	}
+	for _, elseIf := range stm.ElseIfs {
+		if EvaluateCondition(elseIf.Condition, symTable) {
+			return elseIf.Block
+		}
+	}
+	return stm.ElseBlock
+}
+
+/*
+EvaluateForRange returns the values of the variable of @for, "through"
+includes the end of the range and "to" excludes it. The range is descending
+if the start is greater than the end:
+
+	@for $i from 1 through 3    // 1, 2, 3
+	@for $i from 3 to 1         // 3, 2
+*/
+func EvaluateForRange(stm *ast.ForStatement, symTable *symtable.SymTable) []*ast.Number {
+	// the errors of the range are reported at the @for statement
+	defer func() {
+		if r := recover(); r != nil {
+			if err, ok := r.(error); ok {
+				if _, isRuntimeErr := err.(*RuntimeError); !isRuntimeErr {
+					r = NewRuntimeError(stm.Token, err)
+				}
+			}
+			panic(r)
+		}
+	}()
+
+	var end = stm.To
+	if stm.Through != nil {
+		end = stm.Through
+	}
+
+	from, ok := EvaluateValue(stm.From, symTable).(*ast.Number)
+	if !ok {
+		panic(fmt.Errorf("The start of @for range must be a number, got %s", stm.From))
+	}
+	to, ok := EvaluateValue(end, symTable).(*ast.Number)
+	if !ok {
+		panic(fmt.Errorf("The end of @for range must be a number, got %s", end))
+	}
+	for _, num := range []*ast.Number{from, to} {
+		if float64(num.Integer()) != num.Value {
+			panic(fmt.Errorf("%s is not an int", num))
+		}
+	}
+
+	var unit = from.Unit
+	if unit == nil {
+		unit = to.Unit
+	}
+
+	var step = 1
+	if from.Integer() > to.Integer() {
+		step = -1
+	}
+	var last = to.Integer()
+	if stm.Through == nil {
+		last -= step
+	}
+
+	var values = []*ast.Number{}
+	for i := from.Integer(); (step > 0 && i <= last) || (step < 0 && i >= last); i += step {
+		values = append(values, ast.NewNumber(float64(i), unit, nil))
+	}
+	return values
+}
+
+/*
+EvaluateEachItems returns the values of the variables of @each for every
+iteration. The key and the value of the map are assigned to the first two
+variables, or to the only variable as a space separated list. The list items
+are destructured when there are more than one variable, the variables
+without the value are null.
+*/
+func EvaluateEachItems(stm *ast.EachStatement, symTable *symtable.SymTable) [][]ast.Expression {
+	var items = [][]ast.Expression{}
+	switch list := EvaluateValue(stm.List, symTable).(type) {
+	case *ast.Map:
+		for i, key := range list.Keys {
+			var pair = ast.NewSpaceSepList()
+			pair.Append(key)
+			pair.Append(list.Values[i])
+			items = append(items, destructureEachItem(pair, len(stm.Variables)))
+		}
+	case *ast.List:
+		for _, item := range list.Expressions {
+			items = append(items, destructureEachItem(item, len(stm.Variables)))
+		}
+	default:
+		items = append(items, destructureEachItem(list, len(stm.Variables)))
+	}
+	return items
+}
+
+func destructureEachItem(item ast.Expression, numVariables int) []ast.Expression {
+	var values = []ast.Expression{item}
+	if list, ok := item.(*ast.List); ok && numVariables > 1 {
+		values = list.Expressions
+	}
+	for len(values) < numVariables {
+		values = append(values, ast.NewNullWithToken(nil))
+	}
+	return values[:numVariables]
+}
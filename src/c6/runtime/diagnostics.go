@@ -0,0 +1,57 @@
+package runtime
+
+import "c6/ast"
+import "c6/logger"
+import "c6/symtable"
+
+/*
+UserError is raised by @error, the message is the evaluated value of the
+statement. It's wrapped by RuntimeError with the position of @error.
+*/
+type UserError struct {
+	Message string
+}
+
+func (e *UserError) Error() string {
+	return e.Message
+}
+
+/*
+EvaluateDiagnosticStatement reports the value of @debug and @warn to the
+sink, the value of @error is raised as UserError. It returns false if the
+statement is not a diagnostic statement.
+*/
+func EvaluateDiagnosticStatement(anyStm ast.Statement, symTable *symtable.SymTable, sink logger.Sink) bool {
+	switch stm := anyStm.(type) {
+	case *ast.DebugStatement:
+		reportDiagnostic(logger.LevelDebug, stm.Value, stm.Token, symTable, sink)
+	case *ast.WarnStatement:
+		reportDiagnostic(logger.LevelWarn, stm.Value, stm.Token, symTable, sink)
+	case *ast.ErrorStatement:
+		panic(NewRuntimeError(stm.Token, &UserError{Message: diagnosticMessage(stm.Value, symTable)}))
+	default:
+		return false
+	}
+	return true
+}
+
+func reportDiagnostic(level logger.Level, value ast.Expression, token *ast.Token, symTable *symtable.SymTable, sink logger.Sink) {
+	var diagnostic = &logger.Diagnostic{Level: level, Message: diagnosticMessage(value, symTable)}
+	if token != nil {
+		diagnostic.File = token.File
+		diagnostic.Line = token.Line + 1
+	}
+	sink.Report(diagnostic)
+}
+
+/*
+diagnosticMessage evaluates the value of the statement, the quoted strings
+are printed without the quotes.
+*/
+func diagnosticMessage(value ast.Expression, symTable *symtable.SymTable) string {
+	var val = EvaluateValue(value, symTable)
+	if str, ok := val.(*ast.String); ok {
+		return str.Value
+	}
+	return val.String()
+}
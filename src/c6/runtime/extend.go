@@ -0,0 +1,527 @@
+package runtime
+
+import "c6/ast"
+import "fmt"
+import "sort"
+import "strings"
+
+/*
+extension is an @extend statement with the selector of the ruleset which
+contains the statement.
+*/
+type extension struct {
+	Statement *ast.ExtendStatement
+
+	// the selector of the extending ruleset, joined with the parent rulesets
+	Extender ast.SelectorList
+
+	// the target selector is found in a ruleset
+	Matched bool
+
+	// the innermost @media containing the @extend, nil if it's not in @media.
+	// The extension inside @media only extends the selectors in the same
+	// @media.
+	Media *ast.MediaQueryStatement
+}
+
+/*
+ExtendRuleSets applies the @extend statements to the evaluated rulesets, the
+selectors created by replacing the target selector with the selector of the
+extending ruleset are added to the ExtendedSelectors of the rulesets:
+
+	.message { border: 1px solid; }
+	.error { @extend .message; color: red; }
+
+	// .message, .error { border: 1px solid; }
+	// .error { color: red; }
+
+The target compound selector is unified with the compound selector containing
+it, the selector is skipped if they can't be unified, e.g. "a" and "span". The
+@extend statements are removed from the rulesets. An error is returned if the
+target selector of a non-optional @extend is not found.
+*/
+func ExtendRuleSets(stmts []ast.Statement) error {
+	var extensions, err = collectExtensions(stmts, nil, nil)
+	if err != nil || len(extensions) == 0 {
+		return err
+	}
+	if err := extendStatements(stmts, nil, extensions, nil); err != nil {
+		return err
+	}
+
+	for _, ext := range extensions {
+		if !ext.Matched && !ext.Statement.Optional {
+			return NewRuntimeError(ext.Statement.Token, fmt.Errorf(
+				"The target selector '%s' of @extend was not found, use \"@extend %s !optional\" to avoid this error",
+				selectorKey(ext.Statement.Selectors), selectorKey(ext.Statement.Selectors)))
+		}
+	}
+	return nil
+}
+
+/*
+innerMedia returns the statement if it's @media, otherwise the enclosing
+@media is returned.
+*/
+func innerMedia(anyStm ast.Statement, media *ast.MediaQueryStatement) *ast.MediaQueryStatement {
+	if stm, ok := anyStm.(*ast.MediaQueryStatement); ok {
+		return stm
+	}
+	return media
+}
+
+/*
+sameMedia reports whether the two @media have the same media queries, the
+selectors in the different @media blocks of the same queries could extend
+each other.
+*/
+func sameMedia(a, b *ast.MediaQueryStatement) bool {
+	return a == b || (a != nil && b != nil && a.String() == b.String())
+}
+
+/*
+collectExtensions removes the @extend statements from the rulesets, the
+parents are the resolved selectors of the enclosing ruleset, nil for the top
+level statements. The media is the innermost @media of the statements.
+*/
+func collectExtensions(stmts []ast.Statement, parents ast.SelectorGroup, media *ast.MediaQueryStatement) ([]*extension, error) {
+	var extensions = []*extension{}
+	for _, anyStm := range stmts {
+		switch stm := anyStm.(type) {
+
+		case *ast.ExtendStatement:
+			if parents == nil {
+				return nil, NewRuntimeError(stm.Token, fmt.Errorf("@extend may only be used within rulesets"))
+			}
+			// the @extend in the at-rule block nested in a ruleset
+			for _, extender := range parents {
+				extensions = append(extensions, &extension{Statement: stm, Extender: extender, Media: media})
+			}
+
+		case *ast.MediaQueryStatement, *ast.SupportsStatement, *ast.AtRuleStatement:
+			var block = atRuleBlock(stm)
+			if block == nil {
+				continue
+			}
+			subExtensions, err := collectExtensions(block.Statements, parents, innerMedia(stm, media))
+			if err != nil {
+				return nil, err
+			}
+			extensions = append(extensions, subExtensions...)
+			block.Statements = withoutExtendStatements(block.Statements)
+
+		case *ast.RuleSet:
+			if stm.Block == nil {
+				continue
+			}
+			var extenders = stm.Selectors
+			if parents != nil {
+				extenders = stm.Selectors.ResolveParent(parents)
+			}
+
+			// the new offsets of the statements, the offsets of the nested
+			// rulesets are moved by the removed @extend statements
+			var declarations = []ast.Statement{}
+			var offsets = make([]int, len(stm.Block.Statements)+1)
+			for idx, subStm := range stm.Block.Statements {
+				offsets[idx] = len(declarations)
+				switch subStm := subStm.(type) {
+				case *ast.ExtendStatement:
+					for _, extender := range extenders {
+						extensions = append(extensions, &extension{Statement: subStm, Extender: extender, Media: media})
+					}
+					continue
+				case *ast.MediaQueryStatement, *ast.SupportsStatement, *ast.AtRuleStatement:
+					// the rulesets in the nested at-rule block are relative to this ruleset
+					subExtensions, err := collectExtensions([]ast.Statement{subStm}, extenders, media)
+					if err != nil {
+						return nil, err
+					}
+					extensions = append(extensions, subExtensions...)
+				}
+				declarations = append(declarations, subStm)
+			}
+			offsets[len(stm.Block.Statements)] = len(declarations)
+			for idx, offset := range stm.Block.SubRuleSetOffsets {
+				if offset < len(offsets) {
+					stm.Block.SubRuleSetOffsets[idx] = offsets[offset]
+				}
+			}
+			stm.Block.Statements = declarations
+
+			var subStmts = []ast.Statement{}
+			for _, subRuleSet := range stm.Block.SubRuleSets {
+				subStmts = append(subStmts, subRuleSet)
+			}
+			subExtensions, err := collectExtensions(subStmts, extenders, media)
+			if err != nil {
+				return nil, err
+			}
+			extensions = append(extensions, subExtensions...)
+		}
+	}
+	return extensions, nil
+}
+
+func withoutExtendStatements(stmts []ast.Statement) []ast.Statement {
+	var out = []ast.Statement{}
+	for _, stm := range stmts {
+		if _, ok := stm.(*ast.ExtendStatement); !ok {
+			out = append(out, stm)
+		}
+	}
+	return out
+}
+
+/*
+extendStatements adds the extended selectors to the rulesets, the selectors
+are resolved with the parents, the resolved selectors of the enclosing
+ruleset, so the ancestors of the target are woven with the ancestors of the
+extender. The media is the innermost @media of the statements. An error is
+returned if an @extend inside @media extends a selector outside the @media.
+*/
+func extendStatements(stmts []ast.Statement, parents ast.SelectorGroup, extensions []*extension, media *ast.MediaQueryStatement) error {
+	for _, anyStm := range stmts {
+		switch stm := anyStm.(type) {
+		case *ast.MediaQueryStatement, *ast.SupportsStatement, *ast.AtRuleStatement:
+			if block := atRuleBlock(stm); block != nil {
+				if err := extendStatements(block.Statements, parents, extensions, innerMedia(stm, media)); err != nil {
+					return err
+				}
+			}
+		case *ast.RuleSet:
+			if stm.Block == nil {
+				continue
+			}
+			var selectors = stm.Selectors
+			if parents != nil {
+				selectors = selectors.ResolveParent(parents)
+			}
+
+			var mediaExtensions = []*extension{}
+			for _, ext := range extensions {
+				if ext.Media == nil || sameMedia(ext.Media, media) {
+					mediaExtensions = append(mediaExtensions, ext)
+					continue
+				}
+				for _, selectors := range selectors {
+					if len(extendSelector(selectors, ext)) > 0 {
+						return NewRuntimeError(ext.Statement.Token, fmt.Errorf(
+							"You may not @extend the selector '%s' outside @media from within @media, only the selectors within the same @media can be extended",
+							selectorKey(ext.Statement.Selectors)))
+					}
+				}
+			}
+
+			// the selectors from the extended parents could be created again
+			var seen = map[string]bool{}
+			for _, selectors := range selectors {
+				seen[normalizedSelectorKey(selectors)] = true
+			}
+			stm.ExtendedSelectors = ast.SelectorGroup{}
+			for _, selectors := range selectors {
+				for _, extended := range extendSelectors(selectors, mediaExtensions) {
+					if key := normalizedSelectorKey(extended); !seen[key] {
+						seen[key] = true
+						stm.ExtendedSelectors = append(stm.ExtendedSelectors, extended)
+					}
+				}
+			}
+
+			var subParents = stm.ResolvedSelectors(parents)
+			for _, subRuleSet := range stm.Block.SubRuleSets {
+				if err := extendStatements([]ast.Statement{subRuleSet}, subParents, extensions, media); err != nil {
+					return err
+				}
+			}
+			for _, subStm := range stm.Block.Statements {
+				if atRuleBlock(subStm) != nil {
+					if err := extendStatements([]ast.Statement{subStm}, subParents, extensions, media); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+/*
+atRuleBlock returns the block of @media, @supports or the unknown at-rule,
+the selectors inside them could be extended. The rulesets inside @keyframes
+are not selectors, so the block of @keyframes is not returned.
+*/
+func atRuleBlock(anyStm ast.Statement) *ast.Block {
+	switch stm := anyStm.(type) {
+	case *ast.MediaQueryStatement:
+		return stm.Block
+	case *ast.SupportsStatement:
+		return stm.Block
+	case *ast.AtRuleStatement:
+		return stm.Block
+	}
+	return nil
+}
+
+/*
+extendSelectors returns the selectors created from the selector by the
+extensions. The created selectors are extended again for the chained
+@extend, but an extension is applied only once to the selectors created from
+it, so the recursive @extend stops.
+*/
+func extendSelectors(selectors ast.SelectorList, extensions []*extension) []ast.SelectorList {
+	type extendedSelector struct {
+		Selectors ast.SelectorList
+		Applied   map[*extension]bool
+	}
+
+	var out = []ast.SelectorList{}
+	var seen = map[string]bool{normalizedSelectorKey(selectors): true}
+	var queue = []extendedSelector{{selectors, map[*extension]bool{}}}
+	for i := 0; i < len(queue); i++ {
+		var current = queue[i]
+		for _, ext := range extensions {
+			if current.Applied[ext] {
+				continue
+			}
+			for _, newSelectors := range extendSelector(current.Selectors, ext) {
+				var key = normalizedSelectorKey(newSelectors)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+
+				var applied = map[*extension]bool{ext: true}
+				for appliedExt := range current.Applied {
+					applied[appliedExt] = true
+				}
+				queue = append(queue, extendedSelector{newSelectors, applied})
+				out = append(out, newSelectors)
+			}
+		}
+	}
+	return out
+}
+
+/*
+extendSelector replaces the target selector of the extension in every
+compound selector containing it, the ancestors of the extender are woven with
+the ancestors of the target, see weaveParents.
+*/
+func extendSelector(selectors ast.SelectorList, ext *extension) []ast.SelectorList {
+	var out = []ast.SelectorList{}
+	var extenderStart = lastCompoundStart(ext.Extender)
+
+	var start = 0
+	for start < len(selectors) {
+		var end = start
+		for end < len(selectors) && !ast.IsCombinator(selectors[end]) {
+			end++
+		}
+
+		var compound = selectors[start:end]
+		if rest, ok := removeSelectors(compound, ext.Statement.Selectors); ok {
+			ext.Matched = true
+			if unified := unifyCompound(rest, ext.Extender[extenderStart:]); unified != nil {
+				for _, parents := range weaveParents(selectors[:start], ext.Extender[:extenderStart]) {
+					var newSelectors = ast.SelectorList{}
+					newSelectors = append(newSelectors, parents...)
+					newSelectors = append(newSelectors, unified...)
+					newSelectors = append(newSelectors, selectors[end:]...)
+					out = append(out, newSelectors)
+				}
+			}
+		}
+		start = end + 1
+	}
+	return out
+}
+
+/*
+weaveParents returns the orders of the ancestors of the target and the
+ancestors of the extender, like SASS, the two sequences are kept as they are
+and either of them can come first:
+
+	.c .x { ... }  .a .b { @extend .x; }  =>  .c .a .b, .a .c .b
+
+A sequence can't come first if it ends with the child or sibling combinator,
+the combinator has to stay before the extended compound selector. If both of
+them end with the same combinator, the compound selectors before it are
+unified:
+
+	.c > .x { ... }  .a > .b { @extend .x; }  =>  .c.a > .b
+*/
+func weaveParents(targetParents ast.SelectorList, extenderParents ast.SelectorList) []ast.SelectorList {
+	if len(targetParents) == 0 {
+		return []ast.SelectorList{extenderParents}
+	}
+	if len(extenderParents) == 0 || normalizedSelectorKey(targetParents) == normalizedSelectorKey(extenderParents) {
+		return []ast.SelectorList{targetParents}
+	}
+
+	var out = []ast.SelectorList{}
+	var targetCombinator = targetParents[len(targetParents)-1]
+	var extenderCombinator = extenderParents[len(extenderParents)-1]
+	if !endsWithDescendant(targetParents) && targetCombinator.String() == extenderCombinator.String() {
+		var targetRest = targetParents[:len(targetParents)-1]
+		var extenderRest = extenderParents[:len(extenderParents)-1]
+		var targetStart = lastCompoundStart(targetRest)
+		var extenderStart = lastCompoundStart(extenderRest)
+		var unified = unifyCompound(targetRest[targetStart:], extenderRest[extenderStart:])
+		if unified == nil {
+			return out
+		}
+		for _, parents := range weaveParents(targetRest[:targetStart], extenderRest[:extenderStart]) {
+			var woven = append(append(ast.SelectorList{}, parents...), unified...)
+			out = append(out, append(woven, targetCombinator))
+		}
+		return out
+	}
+
+	if endsWithDescendant(targetParents) {
+		out = append(out, append(append(ast.SelectorList{}, targetParents...), extenderParents...))
+	}
+	if endsWithDescendant(extenderParents) {
+		out = append(out, append(append(ast.SelectorList{}, extenderParents...), targetParents...))
+	}
+	if len(out) == 0 {
+		out = append(out, append(append(ast.SelectorList{}, targetParents...), extenderParents...))
+	}
+	return out
+}
+
+func endsWithDescendant(selectors ast.SelectorList) bool {
+	if len(selectors) == 0 {
+		return false
+	}
+	_, ok := selectors[len(selectors)-1].(*ast.DescendantCombinator)
+	return ok
+}
+
+// lastCompoundStart returns the index of the last compound selector.
+func lastCompoundStart(selectors ast.SelectorList) int {
+	for i := len(selectors) - 1; i >= 0; i-- {
+		if ast.IsCombinator(selectors[i]) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+/*
+removeSelectors removes the target simple selectors from the compound
+selector, it returns false if the compound selector doesn't contain all of
+them.
+*/
+func removeSelectors(compound ast.SelectorList, target ast.SelectorList) (ast.SelectorList, bool) {
+	var rest = ast.SelectorList{}
+	var found = 0
+	for _, sel := range compound {
+		if containsSelector(target, sel) {
+			found++
+		} else {
+			rest = append(rest, sel)
+		}
+	}
+	return rest, found == len(target)
+}
+
+/*
+unifyCompound merges the simple selectors of the two compound selectors, nil
+is returned if nothing can match both of them, e.g. two different element
+types or two different ids. The type selector is put first and the pseudo
+selectors are put last.
+*/
+func unifyCompound(compound ast.SelectorList, extender ast.SelectorList) ast.SelectorList {
+	var out = append(ast.SelectorList{}, compound...)
+	for _, sel := range extender {
+		if containsSelector(out, sel) {
+			continue
+		}
+		switch sel.(type) {
+
+		case *ast.UniversalSelector:
+			if len(out) > 0 {
+				continue
+			}
+			out = append(out, sel)
+
+		case *ast.TypeSelector:
+			if len(out) > 0 {
+				switch out[0].(type) {
+				case *ast.TypeSelector:
+					return nil
+				case *ast.UniversalSelector:
+					out = out[1:]
+				}
+			}
+			out = append(ast.SelectorList{sel}, out...)
+
+		case *ast.IdSelector:
+			for _, other := range out {
+				if _, ok := other.(*ast.IdSelector); ok {
+					return nil
+				}
+			}
+			out = insertBeforePseudo(out, sel)
+
+		case *ast.PseudoSelector:
+			out = append(out, sel)
+
+		default:
+			out = insertBeforePseudo(out, sel)
+		}
+	}
+	return out
+}
+
+func insertBeforePseudo(compound ast.SelectorList, sel ast.Selector) ast.SelectorList {
+	var i = len(compound)
+	for i > 0 {
+		if _, ok := compound[i-1].(*ast.PseudoSelector); !ok {
+			break
+		}
+		i--
+	}
+	var out = append(ast.SelectorList{}, compound[:i]...)
+	out = append(out, sel)
+	return append(out, compound[i:]...)
+}
+
+func containsSelector(selectors ast.SelectorList, sel ast.Selector) bool {
+	var key = selectorKey(ast.SelectorList{sel})
+	for _, other := range selectors {
+		if selectorKey(ast.SelectorList{other}) == key {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+selectorKey returns the text of the selectors for the comparison, the parent
+selector is not resolved here.
+*/
+func selectorKey(selectors ast.SelectorList) string {
+	return selectors.String()
+}
+
+/*
+normalizedSelectorKey returns the same key for the selectors which are only
+different in the order of the simple selectors, e.g. ".a.b" and ".b.a".
+*/
+func normalizedSelectorKey(selectors ast.SelectorList) string {
+	var out = ""
+	var compound = []string{}
+	for _, sel := range selectors {
+		if ast.IsCombinator(sel) {
+			sort.Strings(compound)
+			out += strings.Join(compound, "") + sel.String()
+			compound = []string{}
+		} else {
+			compound = append(compound, selectorKey(ast.SelectorList{sel}))
+		}
+	}
+	sort.Strings(compound)
+	return out + strings.Join(compound, "")
+}
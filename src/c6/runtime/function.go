@@ -0,0 +1,238 @@
+package runtime
+
+import "c6/ast"
+import "c6/logger"
+import "c6/symtable"
+import "fmt"
+import "strings"
+
+/*
+The key of the call depth in the symbol table of the function call.
+*/
+const callDepthKey = "@call-depth"
+
+/*
+Function is the function defined by @function, the diagnostics of the
+function body are reported to the sink of the interpreter defining the
+function.
+*/
+type Function struct {
+	Statement   *ast.FunctionStatement
+	Diagnostics logger.Sink
+}
+
+/*
+FunctionKey returns the key of the function in the symbol table, the hyphens
+and underscores in the names are the same.
+*/
+func FunctionKey(name string) string {
+	return "@function " + strings.Replace(name, "_", "-", -1)
+}
+
+/*
+EvaluateFunctionCall calls the function defined by @function or the built-in
+function of the name. The other function calls are CSS functions, e.g. rgba()
+and calc(), their arguments are evaluated and the function calls are kept for
+the output. The function call is returned as it is if there is no symbol table, since the functions
+are not defined when parsing.
+*/
+func EvaluateFunctionCall(fcall *ast.FunctionCall, symTable *symtable.SymTable) ast.Value {
+	if symTable == nil {
+		return fcall
+	}
+
+	var item, definedSymTable = symTable.Lookup(FunctionKey(fcall.Function))
+	if fn, ok := item.(*Function); ok {
+		return CallFunction(fn, fcall, symTable, definedSymTable)
+	}
+	if fn, ok := LookupBuiltinFunction(fcall.Function); ok {
+		return CallBuiltinFunction(fn, fcall, symTable)
+	}
+
+	var result = &ast.FunctionCall{Function: fcall.Function, Arguments: []*ast.Argument{}, Token: fcall.Token}
+	for _, arg := range fcall.Arguments {
+		var val = EvaluateValue(arg.Value, symTable)
+		if list, ok := val.(*ast.List); ok && arg.Rest {
+			for _, item := range list.Expressions {
+				result.AppendArgument(item)
+			}
+		} else {
+			result.Arguments = append(result.Arguments, &ast.Argument{Name: arg.Name, Value: val})
+		}
+	}
+	return result
+}
+
+/*
+CallFunction binds the arguments and runs the function body in a new scope
+of the symbol table where the function is defined, the value of @return is
+returned.
+*/
+func CallFunction(function *Function, fcall *ast.FunctionCall, callerSymTable *symtable.SymTable, definedSymTable *symtable.SymTable) ast.Value {
+	var fn = function.Statement
+	var depth = 1
+	if item, ok := callerSymTable.Get(callDepthKey); ok {
+		depth = item.(int) + 1
+	}
+	if depth > MaxCallDepth {
+		panic(NewRuntimeError(fcall.Token, fmt.Errorf("Too many nested calls of function '%s'", fn.Name)))
+	}
+
+	var fnSymTable = symtable.NewSymTableWithParent(definedSymTable)
+	fnSymTable.Set(callDepthKey, depth)
+	if err := BindArguments("function '"+fn.Name+"'", fn.Parameters, fcall.Arguments, callerSymTable, fnSymTable); err != nil {
+		panic(NewRuntimeError(fcall.Token, err))
+	}
+
+	if val, ok := runFunctionStatements(function, fn.Block.Statements, fnSymTable); ok {
+		return val
+	}
+	panic(NewRuntimeError(fcall.Token, fmt.Errorf("Function '%s' finished without @return", fn.Name)))
+}
+
+/*
+runFunctionStatements runs the statements of the function body until
+@return. The blocks of the control statements share the scope of the
+function, so the variables assigned in the blocks are the local variables
+of the function.
+*/
+func runFunctionStatements(fn *Function, stmts []ast.Statement, symTable *symtable.SymTable) (ast.Value, bool) {
+	for _, anyStm := range stmts {
+		switch stm := anyStm.(type) {
+
+		case *ast.VariableAssignment:
+			AssignVariable(stm, symTable)
+
+		case *ast.ReturnStatement:
+			return EvaluateValue(stm.Value, symTable), true
+
+		case *ast.IfStatement:
+			if block := SelectIfBlock(stm, symTable); block != nil {
+				if val, ok := runFunctionStatements(fn, block.Statements, symTable); ok {
+					return val, true
+				}
+			}
+
+		case *ast.ForStatement:
+			for _, val := range EvaluateForRange(stm, symTable) {
+				symTable.Set(stm.Variable.Name, val)
+				if val, ok := runFunctionStatements(fn, stm.Block.Statements, symTable); ok {
+					return val, true
+				}
+			}
+
+		case *ast.EachStatement:
+			for _, values := range EvaluateEachItems(stm, symTable) {
+				for i, variable := range stm.Variables {
+					symTable.Set(variable.Name, values[i])
+				}
+				if val, ok := runFunctionStatements(fn, stm.Block.Statements, symTable); ok {
+					return val, true
+				}
+			}
+
+		case *ast.WhileStatement:
+			for EvaluateCondition(stm.Condition, symTable) {
+				if val, ok := runFunctionStatements(fn, stm.Block.Statements, symTable); ok {
+					return val, true
+				}
+			}
+
+		case *ast.DebugStatement, *ast.WarnStatement, *ast.ErrorStatement:
+			EvaluateDiagnosticStatement(stm, symTable, fn.Diagnostics)
+
+		case *ast.CommentStatement:
+
+		default:
+			panic(NewRuntimeError(fn.Statement.Token, fmt.Errorf("Function '%s' can only contain the variable assignments, the control statements, @debug, @warn, @error and @return", fn.Statement.Name)))
+		}
+	}
+	return nil, false
+}
+
+/*
+BindArguments evaluates the arguments in the scope of the caller and defines
+the parameters in the scope of the callee. The positional arguments are
+bound in order, the rest argument `$list...` is expanded to the positional
+arguments and the rest argument `$map...` is expanded to the keyword
+arguments, the rest parameter takes the remaining positional arguments as a
+comma-separated list, and the missing parameters take their default values.
+*/
+func BindArguments(callee string, params []*ast.Parameter, args []*ast.Argument, callerSymTable *symtable.SymTable, symTable *symtable.SymTable) error {
+	var names = map[string]bool{}
+	var restIdx = -1
+	for idx, param := range params {
+		if param.Rest {
+			restIdx = idx
+		} else {
+			names[param.Variable.Name] = true
+		}
+	}
+
+	var positional = []ast.Expression{}
+	var keywords = map[string]ast.Expression{}
+	var setKeyword = func(name string, val ast.Expression) error {
+		if !names[name] {
+			return fmt.Errorf("No argument named %s for %s", name, callee)
+		}
+		if _, ok := keywords[name]; ok {
+			return fmt.Errorf("Argument %s of %s is passed twice", name, callee)
+		}
+		keywords[name] = val
+		return nil
+	}
+	for _, arg := range args {
+		var val = EvaluateValue(arg.Value, callerSymTable)
+		if arg.Name != "" {
+			if err := setKeyword(arg.Name, val); err != nil {
+				return err
+			}
+		} else if mapValue, ok := val.(*ast.Map); ok && arg.Rest {
+			// the keys of the map spread are the names without "$"
+			for i, key := range mapValue.Keys {
+				var name, ok = key.(*ast.String)
+				if !ok {
+					return fmt.Errorf("The keys of the map spread %s... must be the argument names, got %s", arg.Value, key)
+				}
+				if err := setKeyword("$"+name.Value, mapValue.Values[i]); err != nil {
+					return err
+				}
+			}
+		} else if list, ok := val.(*ast.List); ok && arg.Rest {
+			positional = append(positional, list.Expressions...)
+		} else {
+			positional = append(positional, val)
+		}
+	}
+
+	if restIdx == -1 && len(positional) > len(params) {
+		return fmt.Errorf("Only %d arguments are allowed for %s, but %d were passed", len(params), callee, len(positional))
+	}
+
+	for idx, param := range params {
+		var name = param.Variable.Name
+		if param.Rest {
+			var rest = ast.NewCommaSepList()
+			if idx < len(positional) {
+				rest.Expressions = append(rest.Expressions, positional[idx:]...)
+			}
+			symTable.Set(name, rest)
+			continue
+		}
+
+		var val, isKeyword = keywords[name]
+		if isKeyword && idx < len(positional) {
+			return fmt.Errorf("Argument %s of %s is passed twice", name, callee)
+		} else if isKeyword {
+			symTable.Set(name, val)
+		} else if idx < len(positional) {
+			symTable.Set(name, positional[idx])
+		} else if param.Default != nil {
+			// the default value may refer to the previous parameters
+			symTable.Set(name, EvaluateValue(param.Default, symTable))
+		} else {
+			return fmt.Errorf("Missing argument %s of %s", name, callee)
+		}
+	}
+	return nil
+}
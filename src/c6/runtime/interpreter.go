@@ -0,0 +1,513 @@
+package runtime
+
+import "c6/ast"
+import "c6/logger"
+import "c6/symtable"
+import "fmt"
+import "strings"
+
+/*
+The limit of the nested mixin calls, it stops the infinite recursion.
+*/
+const MaxCallDepth = 100
+
+/*
+Interpreter evaluates the statements from the parser into the statements of
+the output: the mixins and the control directives are expanded and the
+variables are replaced by their values. The statements from the parser are not changed since they may be
+cached, the evaluated rulesets and properties are new nodes.
+*/
+type Interpreter struct {
+	// the global scope
+	SymTable *symtable.SymTable
+
+	// parses the selectors after the interpolations are evaluated, e.g.
+	// c6.ParseSelectorGroup. The runtime package can't depend on the parser.
+	ParseSelectors func(code string) (ast.SelectorGroup, error)
+
+	// parses the media queries after the interpolations are evaluated, e.g.
+	// c6.ParseMediaQueryList.
+	ParseMediaQueries func(code string) ([]*ast.MediaQuery, error)
+
+	// receives the messages of @debug and @warn, nil means logger.DefaultSink.
+	Diagnostics logger.Sink
+
+	// the content block of the current mixin call
+	content *contentBlock
+
+	// the number of the nested mixin calls
+	depth int
+
+	// the resolved selectors of the ruleset enclosing the current statement,
+	// nil at the top level
+	parentSelectors ast.SelectorGroup
+}
+
+/*
+contentBlock is the content block of a mixin call, it's evaluated in the
+scope of the @include statement.
+*/
+type contentBlock struct {
+	Block    *ast.DeclarationBlock
+	SymTable *symtable.SymTable
+
+	// the content block of the mixin call enclosing the @include statement
+	Outer *contentBlock
+}
+
+/*
+RuntimeError is returned by the interpreter, the position is the statement
+raising the error.
+*/
+type RuntimeError struct {
+	File string
+
+	// the line number starts from 1, 0 means the line is unknown.
+	Line int
+
+	Err error
+}
+
+func (e *RuntimeError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Err)
+	}
+	return e.Err.Error()
+}
+
+/*
+NewRuntimeError creates the error at the position of the token.
+*/
+func NewRuntimeError(token *ast.Token, err error) *RuntimeError {
+	if token == nil {
+		return &RuntimeError{Err: err}
+	}
+	return &RuntimeError{File: token.File, Line: token.Line + 1, Err: err}
+}
+
+func NewInterpreter() *Interpreter {
+	return &Interpreter{SymTable: symtable.NewSymTable()}
+}
+
+/*
+MixinKey returns the key of the mixin in the symbol table, the hyphens and
+underscores in the names are the same.
+*/
+func MixinKey(name string) string {
+	return "@mixin " + strings.Replace(name, "_", "-", -1)
+}
+
+/*
+EvaluateStatements evaluates the top level statements, resolves @at-root and
+applies the @extend statements, the error is returned if a mixin is undefined, the
+arguments don't match its parameters, the target selector of @extend is
+not found or @error is evaluated.
+*/
+func (self *Interpreter) EvaluateStatements(stmts []ast.Statement) (out []ast.Statement, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if runtimeErr, ok := r.(*RuntimeError); ok {
+				err = runtimeErr
+			} else if e, ok := r.(error); ok {
+				err = &RuntimeError{Err: e}
+			} else {
+				err = &RuntimeError{Err: fmt.Errorf("%v", r)}
+			}
+		}
+	}()
+	out = ResolveAtRoot(self.evaluateStatements(stmts, self.SymTable))
+	if err := ExtendRuleSets(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+/*
+diagnostics returns the sink of @debug and @warn, the default sink is
+returned when the sink is not set.
+*/
+func (self *Interpreter) diagnostics() logger.Sink {
+	if self.Diagnostics != nil {
+		return self.Diagnostics
+	}
+	return logger.DefaultSink
+}
+
+func (self *Interpreter) evaluateStatements(stmts []ast.Statement, symTable *symtable.SymTable) []ast.Statement {
+	var out = []ast.Statement{}
+	for _, stm := range stmts {
+		out = append(out, self.evaluateStatement(stm, symTable)...)
+	}
+	return out
+}
+
+func (self *Interpreter) evaluateStatement(anyStm ast.Statement, symTable *symtable.SymTable) []ast.Statement {
+	switch stm := anyStm.(type) {
+
+	case *ast.VariableAssignment:
+		AssignVariable(stm, symTable)
+		return nil
+
+	case *ast.MixinStatement:
+		symTable.Set(MixinKey(stm.Name), stm)
+		return nil
+
+	case *ast.FunctionStatement:
+		symTable.Set(FunctionKey(stm.Name), &Function{Statement: stm, Diagnostics: self.diagnostics()})
+		return nil
+
+	case *ast.IncludeStatement:
+		return self.evaluateIncludeStatement(stm, symTable)
+
+	case *ast.ContentStatement:
+		return self.evaluateContentStatement(stm)
+
+	case *ast.DebugStatement, *ast.WarnStatement, *ast.ErrorStatement:
+		EvaluateDiagnosticStatement(stm, symTable, self.diagnostics())
+		return nil
+
+	case *ast.RuleSet:
+		return []ast.Statement{self.evaluateRuleSet(stm, symTable)}
+
+	case *ast.Property:
+		if property := evaluateProperty(stm, symTable); property != nil {
+			return []ast.Statement{property}
+		}
+		return nil
+
+	case *ast.IfStatement:
+		if block := SelectIfBlock(stm, symTable); block != nil {
+			return self.evaluateStatements(block.Statements, symtable.NewControlSymTable(symTable))
+		}
+		return nil
+
+	case *ast.ForStatement:
+		var out = []ast.Statement{}
+		for _, val := range EvaluateForRange(stm, symTable) {
+			var forSymTable = symtable.NewControlSymTable(symTable)
+			forSymTable.Set(stm.Variable.Name, val)
+			out = append(out, self.evaluateStatements(stm.Block.Statements, forSymTable)...)
+		}
+		return out
+
+	case *ast.WhileStatement:
+		var out = []ast.Statement{}
+		for EvaluateCondition(stm.Condition, symTable) {
+			out = append(out, self.evaluateStatements(stm.Block.Statements, symtable.NewControlSymTable(symTable))...)
+		}
+		return out
+
+	case *ast.EachStatement:
+		var out = []ast.Statement{}
+		for _, values := range EvaluateEachItems(stm, symTable) {
+			var eachSymTable = symtable.NewControlSymTable(symTable)
+			for i, variable := range stm.Variables {
+				eachSymTable.Set(variable.Name, values[i])
+			}
+			out = append(out, self.evaluateStatements(stm.Block.Statements, eachSymTable)...)
+		}
+		return out
+
+	case *ast.MediaQueryStatement:
+		var media = ast.NewMediaQueryStatement()
+		if stm.QueryTemplate != nil {
+			media.MediaQueryList = self.evaluateQueryTemplate(stm, symTable)
+		} else {
+			media.MediaQueryList = evaluateMediaQueryList(stm.MediaQueryList, symTable)
+		}
+		media.Token = stm.Token
+		media.Block = self.evaluateBlock(stm.Block, symTable)
+		return []ast.Statement{media}
+
+	case *ast.SupportsStatement:
+		var supports = ast.NewSupportsStatementWithToken(stm.Token)
+		supports.Condition = evaluateSupportsCondition(stm.Condition, symTable)
+		supports.Block = self.evaluateBlock(stm.Block, symTable)
+		return []ast.Statement{supports}
+
+	case *ast.KeyframesStatement:
+		var keyframes = ast.NewKeyframesStatementWithToken(stm.Token)
+		keyframes.Keyword = stm.Keyword
+		keyframes.Name = EvaluateValue(stm.Name, symTable)
+		keyframes.Block = self.evaluateBlock(stm.Block, symTable)
+		return []ast.Statement{keyframes}
+
+	case *ast.FontFaceStatement:
+		var fontFace = ast.NewFontFaceStatementWithToken(stm.Token)
+		fontFace.Block = self.evaluateDeclarationBlock(stm.Block, symTable)
+		return []ast.Statement{fontFace}
+
+	case *ast.PageStatement:
+		var page = ast.NewPageStatementWithToken(stm.Token)
+		if stm.Selector != nil {
+			page.Selector = EvaluateValue(stm.Selector, symTable)
+		}
+		page.Block = self.evaluateDeclarationBlock(stm.Block, symTable)
+		return []ast.Statement{page}
+
+	case *ast.NamespaceStatement:
+		var namespace = ast.NewNamespaceStatementWithToken(stm.Token)
+		namespace.Prelude = EvaluateValue(stm.Prelude, symTable)
+		return []ast.Statement{namespace}
+
+	case *ast.AtRootStatement:
+		var atRoot = ast.NewAtRootStatementWithToken(stm.Token)
+		atRoot.Query = stm.Query
+		atRoot.Block = self.evaluateBlock(stm.Block, symTable)
+		if atRoot.Query.Excludes("rule") {
+			for _, subStm := range atRoot.Block.Statements {
+				if _, ok := subStm.(*ast.Property); ok {
+					panic(NewRuntimeError(stm.Token, fmt.Errorf("Properties are only allowed within rulesets, %s moves them out of the rulesets", atRoot.String())))
+				}
+			}
+		}
+		return []ast.Statement{atRoot}
+
+	case *ast.AtRuleStatement:
+		var atRule = ast.NewAtRuleStatementWithToken(stm.Token)
+		atRule.Name = stm.Name
+		if stm.Prelude != nil {
+			atRule.Prelude = EvaluateValue(stm.Prelude, symTable)
+		}
+		if stm.Block != nil {
+			atRule.Block = self.evaluateBlock(stm.Block, symTable)
+		}
+		return []ast.Statement{atRule}
+	}
+	return []ast.Statement{anyStm}
+}
+
+/*
+evaluateBlock evaluates the statements of the at-rule block in a new scope.
+*/
+func (self *Interpreter) evaluateBlock(block *ast.Block, symTable *symtable.SymTable) *ast.Block {
+	var out = ast.NewBlock()
+	if block != nil {
+		out.Statements = self.evaluateStatements(block.Statements, symtable.NewSymTableWithParent(symTable))
+	}
+	return out
+}
+
+/*
+evaluateDeclarationBlock evaluates the declarations and the nested rulesets of
+the block in a new scope.
+*/
+func (self *Interpreter) evaluateDeclarationBlock(block *ast.DeclarationBlock, symTable *symtable.SymTable) *ast.DeclarationBlock {
+	var out = ast.NewDeclarationBlock()
+	for _, stm := range self.evaluateStatements(blockStatements(block), symtable.NewSymTableWithParent(symTable)) {
+		if subRuleSet, ok := stm.(*ast.RuleSet); ok {
+			out.AppendSubRuleSet(subRuleSet)
+		} else {
+			out.Append(stm)
+		}
+	}
+	return out
+}
+
+/*
+evaluateSupportsCondition evaluates the properties and the values of the
+declarations in the condition of @supports, e.g. "(#{$prop}: $value)".
+*/
+func evaluateSupportsCondition(anyExpr ast.Expression, symTable *symtable.SymTable) ast.Expression {
+	switch expr := anyExpr.(type) {
+	case *ast.SupportsDeclaration:
+		return ast.NewSupportsDeclarationWithToken(EvaluateValue(expr.Property, symTable), EvaluateValue(expr.Value, symTable), expr.Token)
+	case *ast.UnaryExpression:
+		return ast.NewUnaryExpression(expr.Op, evaluateSupportsCondition(expr.Expr, symTable))
+	case *ast.BinaryExpression:
+		return ast.NewBinaryExpression(expr.Op, evaluateSupportsCondition(expr.Left, symTable), evaluateSupportsCondition(expr.Right, symTable), expr.Grouped)
+	}
+	return EvaluateValue(anyExpr, symTable)
+}
+
+/*
+evaluateMediaQueryList evaluates the values of the media features, e.g.
+"(min-width: $breakpoint)", the media types are kept.
+*/
+func evaluateMediaQueryList(queries []*ast.MediaQuery, symTable *symtable.SymTable) []*ast.MediaQuery {
+	var out = []*ast.MediaQuery{}
+	for _, query := range queries {
+		var mediaExpression ast.Expression
+		if query.MediaExpression != nil {
+			mediaExpression = evaluateMediaQueryExpression(query.MediaExpression, symTable)
+		}
+		out = append(out, ast.NewMediaQuery(query.MediaType, mediaExpression))
+	}
+	return out
+}
+
+func evaluateMediaQueryExpression(anyExpr ast.Expression, symTable *symtable.SymTable) ast.Expression {
+	switch expr := anyExpr.(type) {
+	case *ast.MediaFeature:
+		var feature = ast.NewMediaFeatureWithToken(EvaluateValue(expr.Feature, symTable), nil, expr.Token)
+		if expr.Value != nil {
+			feature.Value = EvaluateValue(expr.Value, symTable)
+		}
+		return feature
+	case *ast.BinaryExpression:
+		return ast.NewBinaryExpression(expr.Op, evaluateMediaQueryExpression(expr.Left, symTable), evaluateMediaQueryExpression(expr.Right, symTable), expr.Grouped)
+	}
+	return anyExpr
+}
+
+/*
+evaluateProperty returns nil if the property has nothing to output, the null
+values and the empty lists are omitted like "b: map-get((x: 1), y)" and the
+empty rest argument "b: $args".
+*/
+func evaluateProperty(stm *ast.Property, symTable *symtable.SymTable) *ast.Property {
+	var property = ast.NewPropertyWithName(stm.Name)
+	property.Important = stm.Important
+	for _, value := range stm.Values {
+		var val = EvaluateValue(value, symTable)
+		if _, ok := val.(*ast.Null); ok {
+			continue
+		}
+		if list, ok := val.(*ast.List); ok && len(list.Expressions) == 0 {
+			continue
+		}
+		assertCssValue(val, stm.Name.Token)
+		property.AppendValue(val)
+	}
+	for _, subProperty := range stm.Properties {
+		if subProperty = evaluateProperty(subProperty, symTable); subProperty != nil {
+			property.AppendProperty(subProperty)
+		}
+	}
+	if len(property.Values) == 0 && len(property.Properties) == 0 {
+		return nil
+	}
+	return property
+}
+
+/*
+assertCssValue panics if the value can't be output as CSS, e.g. the map
+"(c: 2)" returned by map-values of the nested map.
+*/
+func assertCssValue(anyValue ast.Value, token *ast.Token) {
+	switch val := anyValue.(type) {
+	case *ast.Map:
+		panic(NewRuntimeError(token, fmt.Errorf("%s isn't a valid CSS value.", val.String())))
+	case *ast.List:
+		for _, item := range val.Expressions {
+			if itemValue, ok := item.(ast.Value); ok {
+				assertCssValue(itemValue, token)
+			}
+		}
+	}
+}
+
+/*
+blockStatements returns the statements and the nested rulesets of the
+declaration block in the source order.
+*/
+func blockStatements(block *ast.DeclarationBlock) []ast.Statement {
+	if block == nil {
+		return []ast.Statement{}
+	}
+	return block.OrderedStatements()
+}
+
+func (self *Interpreter) evaluateRuleSet(ruleset *ast.RuleSet, symTable *symtable.SymTable) *ast.RuleSet {
+	var selectors = ruleset.Selectors
+	if ruleset.SelectorTemplate != nil {
+		selectors = self.evaluateSelectorTemplate(ruleset, symTable)
+	}
+	var parents = self.parentSelectors
+	if parents == nil {
+		if selectors.HasParentSelector() {
+			panic(NewRuntimeError(ruleset.Token, fmt.Errorf("Top-level selectors may not contain the parent selector \"&\"")))
+		}
+		self.parentSelectors = append(ast.SelectorGroup{}, selectors...)
+	} else {
+		if err := selectors.CheckParentSuffix(parents); err != nil {
+			panic(NewRuntimeError(ruleset.Token, err))
+		}
+		self.parentSelectors = selectors.ResolveParent(parents)
+	}
+	defer func() {
+		self.parentSelectors = parents
+	}()
+
+	var result = ast.NewRuleSet()
+	result.Selectors = selectors
+	result.Token = ruleset.Token
+	result.Block = self.evaluateDeclarationBlock(ruleset.Block, symTable)
+	return result
+}
+
+/*
+evaluateSelectorTemplate evaluates the interpolations in the selectors of the
+ruleset and parses the text, so the parent selector and @extend work on the
+selectors:
+
+	$name: home;
+	.icon-#{$name} { &:hover { ... } }    // .icon-home:hover
+*/
+func (self *Interpreter) evaluateSelectorTemplate(ruleset *ast.RuleSet, symTable *symtable.SymTable) ast.SelectorGroup {
+	var text = EvaluateValue(ruleset.SelectorTemplate, symTable).String()
+	if self.ParseSelectors == nil {
+		panic(NewRuntimeError(ruleset.Token, fmt.Errorf("Can't parse the interpolated selector '%s'", text)))
+	}
+	selectors, err := self.ParseSelectors(text)
+	if err != nil {
+		panic(NewRuntimeError(ruleset.Token, err))
+	}
+	return selectors
+}
+
+func (self *Interpreter) evaluateQueryTemplate(stm *ast.MediaQueryStatement, symTable *symtable.SymTable) []*ast.MediaQuery {
+	var text = EvaluateValue(stm.QueryTemplate, symTable).String()
+	if self.ParseMediaQueries == nil {
+		panic(NewRuntimeError(stm.Token, fmt.Errorf("Can't parse the interpolated media query '%s'", text)))
+	}
+	queries, err := self.ParseMediaQueries(text)
+	if err != nil {
+		panic(NewRuntimeError(stm.Token, err))
+	}
+	return queries
+}
+
+func (self *Interpreter) evaluateIncludeStatement(stm *ast.IncludeStatement, symTable *symtable.SymTable) []ast.Statement {
+	var item, definedSymTable = symTable.Lookup(MixinKey(stm.Name))
+	var mixin, ok = item.(*ast.MixinStatement)
+	if !ok {
+		panic(NewRuntimeError(stm.Token, fmt.Errorf("Undefined mixin '%s'", stm.Name)))
+	}
+	if self.depth >= MaxCallDepth {
+		panic(NewRuntimeError(stm.Token, fmt.Errorf("Too many nested calls of mixin '%s'", stm.Name)))
+	}
+
+	// the mixin body is evaluated in the scope where the mixin is defined.
+	var mixinSymTable = symtable.NewSymTableWithParent(definedSymTable)
+	if err := BindArguments("mixin '"+stm.Name+"'", mixin.Parameters, stm.Arguments, symTable, mixinSymTable); err != nil {
+		panic(NewRuntimeError(stm.Token, err))
+	}
+
+	var outerContent = self.content
+	self.content = nil
+	if stm.ContentBlock != nil {
+		self.content = &contentBlock{stm.ContentBlock, symTable, outerContent}
+	}
+	self.depth++
+	defer func() {
+		self.content = outerContent
+		self.depth--
+	}()
+	return self.evaluateStatements(blockStatements(mixin.Block), mixinSymTable)
+}
+
+func (self *Interpreter) evaluateContentStatement(stm *ast.ContentStatement) []ast.Statement {
+	var content = self.content
+	if content == nil {
+		return nil
+	}
+
+	// the @content inside the content block refers to the content block of
+	// the enclosing mixin call.
+	self.content = content.Outer
+	defer func() {
+		self.content = content
+	}()
+	return self.evaluateStatements(blockStatements(content.Block), symtable.NewSymTableWithParent(content.SymTable))
+}
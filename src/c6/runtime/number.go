@@ -1,7 +1,6 @@
 package runtime
 
 import "c6/ast"
-import "fmt"
 
 func NumberComparable(a *ast.Number, b *ast.Number) bool {
 	if a.Unit == nil && b.Unit == nil {
@@ -14,19 +13,34 @@ func NumberComparable(a *ast.Number, b *ast.Number) bool {
 }
 
 func NumberSubNumber(a *ast.Number, b *ast.Number) *ast.Number {
-	if a.Unit.Type != b.Unit.Type {
-		fmt.Printf("Incompatible unit %s != %s.  %v - %v \n", a.Unit, b.Unit, a, b)
-		return nil
+	if (a.Unit == nil && b.Unit == nil) || (a.Unit != nil && b.Unit != nil && a.Unit.Type == b.Unit.Type) {
+		return ast.NewNumber(a.Value-b.Value, a.Unit, nil)
+	}
+	// the unitless number takes the unit of the other one, e.g. 0 - 1px
+	if a.Unit == nil || b.Unit == nil {
+		return ast.NewNumber(a.Value-b.Value, unitOf(a, b), nil)
 	}
-	var result = a.Value - b.Value
-	return ast.NewNumber(result, a.Unit, nil)
+	// incompatible units, e.g. "100% - 10px" inside calc(), the caller keeps
+	// the expression.
+	return nil
+}
+
+func unitOf(a *ast.Number, b *ast.Number) *ast.Unit {
+	if a.Unit != nil {
+		return a.Unit
+	}
+	return b.Unit
 }
 
 func NumberAddNumber(a *ast.Number, b *ast.Number) *ast.Number {
 	if (a.Unit == nil && b.Unit == nil) || (a.Unit != nil && b.Unit != nil && a.Unit.Type == b.Unit.Type) {
 		return ast.NewNumber(a.Value+b.Value, a.Unit, nil)
 	}
-	fmt.Printf("Incompatible unit %s != %s.  %v + %v \n", a.Unit, b.Unit, a, b)
+	// the unitless number takes the unit of the other one, e.g. 0 + 1px
+	if a.Unit == nil || b.Unit == nil {
+		return ast.NewNumber(a.Value+b.Value, unitOf(a, b), nil)
+	}
+	// incompatible units, the caller keeps the expression.
 	return nil
 }
 
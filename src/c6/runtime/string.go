@@ -0,0 +1,18 @@
+package runtime
+
+import "c6/ast"
+
+/*
+StringAddValue concatenates the string and the value by "+", the result is
+quoted if the string is quoted:
+
+	"foo" + bar    // "foobar"
+	foo + "bar"    // foobar
+	"col-" + 2     // "col-2"
+*/
+func StringAddValue(a *ast.String, b ast.Value) *ast.String {
+	if str, ok := b.(*ast.String); ok {
+		return ast.NewString(a.Quote, a.Value+str.Value, nil)
+	}
+	return ast.NewString(a.Quote, a.Value+b.String(), nil)
+}
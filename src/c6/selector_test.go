@@ -0,0 +1,156 @@
+package c6
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestParentSelectorResolution(t *testing.T) {
+	css, err := evaluateScss(`
+	.nav {
+		.item {
+			&:hover { x: 1; }
+			.dark & { x: 2; }
+			&-active { x: 3; }
+			+ .item { x: 4; }
+		}
+	}`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".nav .item:hover { x: 1; }\n.dark .nav .item { x: 2; }\n"+
+		".nav .item-active { x: 3; }\n.nav .item + .item { x: 4; }\n", css)
+}
+
+func TestParentSelectorCrossProduct(t *testing.T) {
+	css, err := evaluateScss(`
+	.a { x: 1; }
+	.b { @extend .a; }
+	.a { &:hover { y: 2; } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a, .b { x: 1; }\n\n.a:hover, .b:hover { y: 2; }\n", css)
+}
+
+func TestParentSelectorExtender(t *testing.T) {
+	css, err := evaluateScss(`
+	.message { x: 1; }
+	.a { &:hover { @extend .message; } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".message, .a:hover { x: 1; }\n", css)
+}
+
+func TestParentSelectorAtTopLevel(t *testing.T) {
+	_, err := evaluateScss(`&:hover { x: 1; }`)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Top-level selectors may not contain the parent selector")
+}
+
+func TestSelectorGroupCrossProduct(t *testing.T) {
+	css, err := evaluateScss(`
+	.a, .b {
+		.c, &:hover { x: 1; }
+	}`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a .c, .a:hover, .b .c, .b:hover { x: 1; }\n", css)
+}
+
+func TestParentSelectorRepeatedInGroup(t *testing.T) {
+	css, err := evaluateScss(`
+	.a, .b {
+		& + & { x: 1; }
+		&-c, & > .d { y: 2; }
+	}`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a + .a, .a + .b, .b + .a, .b + .b { x: 1; }\n"+
+		".a-c, .a > .d, .b-c, .b > .d { y: 2; }\n", css)
+}
+
+func TestParentSelectorSuffixAfterPseudoClass(t *testing.T) {
+	_, err := evaluateScss(`
+	.a:hover {
+		&-x { y: 2; }
+	}`)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), ":3: Invalid parent selector for \"&-x\": \".a:hover\" can't have a suffix")
+	}
+}
+
+func TestSelectorGroupAcrossLines(t *testing.T) {
+	css, err := evaluateScss("h1,\nh2 { x: 1; }\n.a\n.b { y: 2; }\n" +
+		".c {\n\t&:hover,\n\t&:focus { z: 3; }\n}\n.d\t>\r\n.e { w: 4; }")
+	assert.Nil(t, err)
+	assert.Equal(t, "h1, h2 { x: 1; }\n\n.a .b { y: 2; }\n\n.c:hover, .c:focus { z: 3; }\n\n.d > .e { w: 4; }\n", css)
+}
+
+func TestSelectorGroupExtend(t *testing.T) {
+	css, err := evaluateScss(`
+	.message, .note { x: 1; }
+	.a, .b { @extend .message; }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".message, .note, .a, .b { x: 1; }\n", css)
+}
+
+func TestSelectorInterpolation(t *testing.T) {
+	css, err := evaluateScss(`
+	$sel: ".menu";
+	$attr: role;
+	@each $name in home, search {
+		.icon-#{$name} { x: 1; }
+	}
+	#{$sel} > li { x: 2; }
+	[data-#{$attr}] { x: 3; }
+	a[href="x"] .b { x: 4; }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".icon-home { x: 1; }\n\n.icon-search { x: 1; }\n\n"+
+		".menu > li { x: 2; }\n\n[data-role] { x: 3; }\n\na[href=\"x\"] .b { x: 4; }\n", css)
+}
+
+func TestSelectorInterpolationWithParentAndExtend(t *testing.T) {
+	css, err := evaluateScss(`
+	$name: "home";
+	.button { x: 1; }
+	.nav {
+		#{$name}-#{1 + 1}, &-#{$name} {
+			&:hover { @extend .button; }
+		}
+	}`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".button, .nav home-2:hover, .nav-home:hover { x: 1; }\n", css)
+}
+
+func TestSelectorInterpolationInvalid(t *testing.T) {
+	_, err := evaluateScss(`
+	$sel: "{";
+	#{$sel} { x: 1; }`)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), ":3: Invalid selector '{'")
+}
+
+func TestSelectorInterpolationInClassNames(t *testing.T) {
+	css, err := evaluateScss(`
+	$name: theme;
+	@for $i from 1 through 2 {
+		.m-#{$i} { margin: $i * 1px; }
+	}
+	.#{$name} { x: 1; }
+	.col-2 .#{$name}-dark { x: 2; }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".m-1 { margin: 1px; }\n\n.m-2 { margin: 2px; }\n\n"+
+		".theme { x: 1; }\n\n.col-2 .theme-dark { x: 2; }\n", css)
+}
+
+func TestSelectorInterpolationInvalidMessage(t *testing.T) {
+	_, err := evaluateScss(`
+	$name: "9lives";
+	.#{$name} { x: 1; }`)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), ":3: Invalid selector '.9lives': Expecting letter for class selector. got '9'")
+	}
+}
+
+func TestSelectorStartingWithDigits(t *testing.T) {
+	_, err := evaluateScss(".a { x: 1; }\n.b, 12px { y: 2; }")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), ":2: Unexpected token '1' for lexing selector.")
+	}
+
+	css, err := evaluateScss("@keyframes fade { FROM { x: 0; } 37.5% { x: 1; } to { x: 2; } }")
+	assert.Nil(t, err)
+	assert.Equal(t, "@keyframes fade { FROM { x: 0; } 37.5% { x: 1; } to { x: 2; } }\n", css)
+}
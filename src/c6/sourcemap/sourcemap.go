@@ -0,0 +1,185 @@
+package sourcemap
+
+import "encoding/base64"
+import "encoding/json"
+import "strings"
+
+/*
+Mapping maps a position of the generated file to a position of the source
+file, all the lines and the columns start from 0.
+*/
+type Mapping struct {
+	GeneratedLine   int
+	GeneratedColumn int
+	Source          string
+	SourceLine      int
+	SourceColumn    int
+}
+
+/*
+SourceMap builds the Source Map Revision 3 document.
+
+@see https://sourcemaps.info/spec.html
+*/
+type SourceMap struct {
+	// the generated file
+	File       string
+	SourceRoot string
+
+	// the source files, in the order of the first mapping
+	Sources []string
+
+	// the contents of the sources, only used when IncludeSourcesContent is true
+	SourcesContent map[string]string
+
+	IncludeSourcesContent bool
+
+	Mappings []Mapping
+}
+
+func NewSourceMap(file string) *SourceMap {
+	return &SourceMap{
+		File:           file,
+		Sources:        []string{},
+		SourcesContent: map[string]string{},
+		Mappings:       []Mapping{},
+	}
+}
+
+func (self *SourceMap) sourceIndex(source string) int {
+	for idx, s := range self.Sources {
+		if s == source {
+			return idx
+		}
+	}
+	self.Sources = append(self.Sources, source)
+	return len(self.Sources) - 1
+}
+
+/*
+AddMapping appends the mapping, the mappings must be added in the order of
+the generated positions.
+*/
+func (self *SourceMap) AddMapping(mapping Mapping) {
+	self.sourceIndex(mapping.Source)
+	self.Mappings = append(self.Mappings, mapping)
+}
+
+func (self *SourceMap) SetSourceContent(source string, content string) {
+	self.SourcesContent[source] = content
+}
+
+/*
+RenameSources rewrites the source paths, e.g. to make them relative to the
+map file.
+*/
+func (self *SourceMap) RenameSources(rename func(source string) string) {
+	var renamed = map[string]string{}
+	for idx, source := range self.Sources {
+		renamed[source] = rename(source)
+		self.Sources[idx] = renamed[source]
+	}
+	for idx := range self.Mappings {
+		self.Mappings[idx].Source = renamed[self.Mappings[idx].Source]
+	}
+	var contents = map[string]string{}
+	for source, content := range self.SourcesContent {
+		if newSource, ok := renamed[source]; ok {
+			contents[newSource] = content
+		} else {
+			contents[source] = content
+		}
+	}
+	self.SourcesContent = contents
+}
+
+/*
+EncodeMappings encodes the mappings to the "mappings" field, the lines are
+separated by ';' and the segments of a line are separated by ','.
+*/
+func (self *SourceMap) EncodeMappings() string {
+	var out strings.Builder
+	var line = 0
+	var previousColumn = 0
+	var previousSource = 0
+	var previousSourceLine = 0
+	var previousSourceColumn = 0
+	var firstSegment = true
+
+	for _, mapping := range self.Mappings {
+		for line < mapping.GeneratedLine {
+			out.WriteByte(';')
+			line++
+			previousColumn = 0
+			firstSegment = true
+		}
+		if !firstSegment {
+			out.WriteByte(',')
+		}
+		firstSegment = false
+
+		var source = self.sourceIndex(mapping.Source)
+		out.WriteString(EncodeVLQ(mapping.GeneratedColumn - previousColumn))
+		out.WriteString(EncodeVLQ(source - previousSource))
+		out.WriteString(EncodeVLQ(mapping.SourceLine - previousSourceLine))
+		out.WriteString(EncodeVLQ(mapping.SourceColumn - previousSourceColumn))
+
+		previousColumn = mapping.GeneratedColumn
+		previousSource = source
+		previousSourceLine = mapping.SourceLine
+		previousSourceColumn = mapping.SourceColumn
+	}
+	return out.String()
+}
+
+type sourceMapJSON struct {
+	Version        int       `json:"version"`
+	File           string    `json:"file,omitempty"`
+	SourceRoot     string    `json:"sourceRoot,omitempty"`
+	Sources        []string  `json:"sources"`
+	SourcesContent []*string `json:"sourcesContent,omitempty"`
+	Names          []string  `json:"names"`
+	Mappings       string    `json:"mappings"`
+}
+
+func (self *SourceMap) MarshalJSON() ([]byte, error) {
+	var mappings = self.EncodeMappings()
+	var doc = sourceMapJSON{
+		Version:    3,
+		File:       self.File,
+		SourceRoot: self.SourceRoot,
+		Sources:    self.Sources,
+		Names:      []string{},
+		Mappings:   mappings,
+	}
+	if self.IncludeSourcesContent {
+		// the sources without the content are null
+		doc.SourcesContent = make([]*string, len(self.Sources))
+		for idx, source := range self.Sources {
+			if content, ok := self.SourcesContent[source]; ok {
+				doc.SourcesContent[idx] = &content
+			}
+		}
+	}
+	return json.Marshal(doc)
+}
+
+/*
+DataURL returns the base64 data URL of the source map for the inline source
+map.
+*/
+func (self *SourceMap) DataURL() (string, error) {
+	data, err := json.Marshal(self)
+	if err != nil {
+		return "", err
+	}
+	return "data:application/json;charset=utf-8;base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+/*
+MappingURLComment returns the comment appended to the CSS for linking the
+source map.
+*/
+func MappingURLComment(url string) string {
+	return "/*# sourceMappingURL=" + url + " */"
+}
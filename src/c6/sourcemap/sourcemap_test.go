@@ -0,0 +1,71 @@
+package sourcemap
+
+import "encoding/base64"
+import "encoding/json"
+import "strings"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestEncodeVLQ(t *testing.T) {
+	assert.Equal(t, "A", EncodeVLQ(0))
+	assert.Equal(t, "C", EncodeVLQ(1))
+	assert.Equal(t, "D", EncodeVLQ(-1))
+	assert.Equal(t, "gB", EncodeVLQ(16))
+	assert.Equal(t, "hB", EncodeVLQ(-16))
+	assert.Equal(t, "w+B", EncodeVLQ(1000))
+}
+
+func TestEncodeMappings(t *testing.T) {
+	var sourceMap = NewSourceMap("main.css")
+	sourceMap.AddMapping(Mapping{0, 0, "main.scss", 0, 0})
+	sourceMap.AddMapping(Mapping{1, 2, "main.scss", 1, 2})
+	sourceMap.AddMapping(Mapping{1, 10, "_partial.scss", 0, 4})
+	sourceMap.AddMapping(Mapping{3, 0, "main.scss", 3, 0})
+	assert.Equal(t, "AAAA;EACE,QCDE;;ADGJ", sourceMap.EncodeMappings())
+	assert.Equal(t, []string{"main.scss", "_partial.scss"}, sourceMap.Sources)
+}
+
+func TestMarshalJSON(t *testing.T) {
+	var sourceMap = NewSourceMap("main.css")
+	sourceMap.AddMapping(Mapping{0, 0, "main.scss", 0, 0})
+	sourceMap.AddMapping(Mapping{1, 0, "_partial.scss", 0, 0})
+	sourceMap.SetSourceContent("main.scss", "a { }")
+
+	data, err := json.Marshal(sourceMap)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"version":3,"file":"main.css","sources":["main.scss","_partial.scss"],"names":[],"mappings":"AAAA;ACAA"}`, string(data))
+
+	sourceMap.IncludeSourcesContent = true
+	data, err = json.Marshal(sourceMap)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"sourcesContent":["a { }",null]`)
+}
+
+func TestRenameSources(t *testing.T) {
+	var sourceMap = NewSourceMap("main.css")
+	sourceMap.AddMapping(Mapping{0, 0, "/src/main.scss", 0, 0})
+	sourceMap.SetSourceContent("/src/main.scss", "a { }")
+	sourceMap.RenameSources(func(source string) string {
+		return strings.TrimPrefix(source, "/src/")
+	})
+	assert.Equal(t, []string{"main.scss"}, sourceMap.Sources)
+	assert.Equal(t, "main.scss", sourceMap.Mappings[0].Source)
+	assert.Equal(t, "a { }", sourceMap.SourcesContent["main.scss"])
+}
+
+func TestDataURL(t *testing.T) {
+	var sourceMap = NewSourceMap("main.css")
+	sourceMap.AddMapping(Mapping{0, 0, "main.scss", 0, 0})
+	url, err := sourceMap.DataURL()
+	assert.Nil(t, err)
+
+	var prefix = "data:application/json;charset=utf-8;base64,"
+	assert.True(t, strings.HasPrefix(url, prefix))
+	data, err := base64.StdEncoding.DecodeString(url[len(prefix):])
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"mappings":"AAAA"`)
+}
+
+func TestMappingURLComment(t *testing.T) {
+	assert.Equal(t, "/*# sourceMappingURL=main.css.map */", MappingURLComment("main.css.map"))
+}
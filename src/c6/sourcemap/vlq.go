@@ -0,0 +1,37 @@
+package sourcemap
+
+const base64Chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+const (
+	vlqBaseShift       = 5
+	vlqBase            = 1 << vlqBaseShift
+	vlqBaseMask        = vlqBase - 1
+	vlqContinuationBit = vlqBase
+)
+
+/*
+EncodeVLQ encodes the integer in the base64 VLQ format of the source map, the
+sign is stored in the least significant bit.
+*/
+func EncodeVLQ(value int) string {
+	var vlq int
+	if value < 0 {
+		vlq = ((-value) << 1) | 1
+	} else {
+		vlq = value << 1
+	}
+
+	var out = []byte{}
+	for {
+		var digit = vlq & vlqBaseMask
+		vlq >>= vlqBaseShift
+		if vlq > 0 {
+			digit |= vlqContinuationBit
+		}
+		out = append(out, base64Chars[digit])
+		if vlq == 0 {
+			break
+		}
+	}
+	return string(out)
+}
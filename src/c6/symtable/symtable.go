@@ -8,27 +8,102 @@ reference.
 */
 type SymTableItem interface{}
 
-type SymTable map[string]SymTableItem
+/*
+SymTable is the symbol table of a scope, the names which are not defined in
+the table are looked up in the parent scope.
+*/
+type SymTable struct {
+	Parent *SymTable
+
+	// the scope of the control directives, e.g. @if and @for, the global
+	// names are assigned from the control directives at the top level.
+	Control bool
+
+	items map[string]SymTableItem
+}
 
 func NewSymTable() *SymTable {
 	return &SymTable{}
 }
 
-func (self SymTable) Set(name string, v SymTableItem) {
-	self[name] = v
+func NewSymTableWithParent(parent *SymTable) *SymTable {
+	return &SymTable{Parent: parent}
+}
+
+/*
+NewControlSymTable creates the scope of the block of a control directive.
+*/
+func NewControlSymTable(parent *SymTable) *SymTable {
+	return &SymTable{Parent: parent, Control: true}
+}
+
+/*
+Set defines the name in the table, the parent scopes are not changed.
+*/
+func (self *SymTable) Set(name string, v SymTableItem) {
+	if self.items == nil {
+		self.items = map[string]SymTableItem{}
+	}
+	self.items[name] = v
 }
 
-func (self SymTable) Get(name string) (SymTableItem, bool) {
-	if val, ok := self[name]; ok {
+/*
+Get looks up the name from the table to the outermost scope.
+*/
+func (self *SymTable) Get(name string) (SymTableItem, bool) {
+	if val, table := self.Lookup(name); table != nil {
 		return val, true
 	}
 	return nil, false
 }
 
-func (self SymTable) Has(name string) bool {
-	if _, ok := self[name]; ok {
-		return true
-	} else {
-		return false
+/*
+Lookup returns the item and the table defining the name, the table is nil if
+the name is not defined.
+*/
+func (self *SymTable) Lookup(name string) (SymTableItem, *SymTable) {
+	for table := self; table != nil; table = table.Parent {
+		if val, ok := table.items[name]; ok {
+			return val, table
+		}
+	}
+	return nil, nil
+}
+
+/*
+Assign sets the name in the nearest scope defining it, the name is defined in
+the scope of the assignment if no scope defines it. Like SASS, a global name
+is assigned only from the scopes of the control directives at the top level,
+the other local scopes define a local name:
+
+	$i: 3;
+	@while $i > 0 { $i: $i - 1; }    // assigns the global $i
+	a { $w: 1; b { $w: 2; } }        // assigns the $w of "a"
+	c { $i: 0; }                     // defines a local $i
+*/
+func (self *SymTable) Assign(name string, v SymTableItem) {
+	var _, table = self.Lookup(name)
+	if table == nil || table.Parent == nil && !self.isSemiGlobal() {
+		self.Set(name, v)
+		return
 	}
+	table.Set(name, v)
+}
+
+/*
+isSemiGlobal returns true if the scope is the global scope or the scope of
+the control directives at the top level.
+*/
+func (self *SymTable) isSemiGlobal() bool {
+	for table := self; table.Parent != nil; table = table.Parent {
+		if !table.Control {
+			return false
+		}
+	}
+	return true
+}
+
+func (self *SymTable) Has(name string) bool {
+	_, table := self.Lookup(name)
+	return table != nil
 }
@@ -0,0 +1,92 @@
+package c6
+
+import "testing"
+import "c6/ast"
+import "c6/symtable"
+import "github.com/stretchr/testify/assert"
+
+func TestVariableLexicalScope(t *testing.T) {
+	css, err := evaluateScss(`
+	$color: red;
+	.a {
+		$color: blue;
+		color: $color;
+		.b { $size: 1px; color: $color; }
+	}
+	.c { color: $color; }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { color: blue; }\n.a .b { color: blue; }\n\n.c { color: red; }\n", css)
+}
+
+func TestVariableAssignsNearestScope(t *testing.T) {
+	css, err := evaluateScss(`
+	$g: 1;
+	a {
+		$w: 1;
+		b { $w: 2; $g: 2; x: $g; }
+		c { w: $w; g: $g; }
+	}
+	@mixin m { $w: 3; }
+	d { $w: 1; @include m; e { @if true { $w: 4; } } w: $w; }
+	@if true { $g: 5; }
+	f { g: $g; }`)
+	assert.Nil(t, err)
+	assert.Equal(t, "a b { x: 2; }\na c { w: 2; g: 1; }\n\nd { w: 4; }\n\nf { g: 5; }\n", css)
+}
+
+func TestVariableGlobalFlag(t *testing.T) {
+	css, err := evaluateScss(`
+	$color: red;
+	@mixin set-theme { $color: green !global; $width: 3px !global; }
+	.a { @include set-theme; }
+	@function twice() { $count: 2 !global; @return 2; }
+	.b { color: $color; width: $width; x: twice(); y: $count; }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".b { color: green; width: 3px; x: 2; y: 2; }\n", css)
+}
+
+func TestVariableDefaultFlag(t *testing.T) {
+	css, err := evaluateScss(`
+	$a: 1px;
+	$a: 2px !default;
+	$b: null;
+	$b: 3px !default;
+	$c: 4px !default;
+	.a { x: $a; y: $b; z: $c; }`)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { x: 1px; y: 3px; z: 4px; }\n", css)
+}
+
+func TestVariableUndefined(t *testing.T) {
+	_, err := evaluateScss(`
+	.a { $local: 1px; }
+	.b {
+		width: $local;
+	}`)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), ":4: Undefined variable $local")
+}
+
+func TestContextGetVariable(t *testing.T) {
+	var context = NewContext()
+	context.GlobalSymTable.Set("$global", ast.NewNumber(1, nil, nil))
+
+	var outer = ast.NewRuleSet()
+	outer.Block = ast.NewDeclarationBlock()
+	outer.Block.SymTable.Set("$outer", ast.NewNumber(2, nil, nil))
+	context.PushRuleSet(outer)
+
+	var inner = ast.NewRuleSet()
+	inner.Block = &ast.DeclarationBlock{SymTable: symtable.NewSymTable()}
+	context.PushRuleSet(inner)
+
+	val, ok := context.GetVariable("$outer")
+	assert.True(t, ok)
+	assert.Equal(t, "2", val.(*ast.Number).String())
+
+	_, ok = context.GetVariable("$global")
+	assert.True(t, ok)
+
+	_, ok = context.GetVariable("$undefined")
+	assert.False(t, ok)
+}